@@ -0,0 +1,272 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+	"github.com/roots/trellis-cli/command"
+)
+
+/*
+Registers user-defined aliases (from the `aliases` CLI config) as commands so
+they're offered in shell completion and listed in help. Aliases which would
+shadow a core command are ignored with a warning.
+*/
+func registerAliases(c *cli.CLI, ui cli.Ui, runner command.Runner, aliases map[string]string) map[string]string {
+	valid := make(map[string]string)
+
+	for name, value := range aliases {
+		if err := validateAlias(name, value, c.Commands); err != nil {
+			ui.Warn(fmt.Sprintf("Warning: ignoring alias %q: %v", name, err))
+			continue
+		}
+
+		valid[name] = value
+		aliasCommand := &aliasCommand{name: name, value: value, commands: c.Commands, runner: runner}
+
+		c.Commands[name] = func() (cli.Command, error) {
+			return aliasCommand, nil
+		}
+	}
+
+	return valid
+}
+
+func validateAlias(name string, value string, commands map[string]cli.CommandFactory) error {
+	if name == "" || strings.ContainsAny(name, " \t") || strings.HasPrefix(name, "-") {
+		return fmt.Errorf("alias names must be a single word")
+	}
+
+	for command := range commands {
+		if command == name || strings.HasPrefix(command, name+" ") {
+			return fmt.Errorf("it would shadow the '%s' command", name)
+		}
+	}
+
+	if isShellAlias(value) {
+		if strings.TrimSpace(value[1:]) == "" {
+			return fmt.Errorf("shell alias is empty")
+		}
+
+		return nil
+	}
+
+	args, err := splitArgs(value)
+	if err != nil {
+		return err
+	}
+
+	if len(args) == 0 {
+		return fmt.Errorf("alias is empty")
+	}
+
+	return nil
+}
+
+func isShellAlias(value string) bool {
+	return strings.HasPrefix(value, "!")
+}
+
+/*
+Expands a leading command alias in args (ie: "dp example.com" with
+dp: "deploy production" => "deploy production example.com").
+Shell aliases aren't expanded since they're run by their alias command.
+*/
+func expandAlias(args []string, aliases map[string]string) ([]string, error) {
+	if len(args) == 0 {
+		return args, nil
+	}
+
+	value, ok := aliases[args[0]]
+	if !ok || isShellAlias(value) {
+		return args, nil
+	}
+
+	expanded, err := splitArgs(value)
+	if err != nil {
+		return nil, fmt.Errorf("Error: invalid alias %q: %v", args[0], err)
+	}
+
+	return append(expanded, args[1:]...), nil
+}
+
+/*
+Splits a string into args like a shell (without any expansion). Single and
+double quotes group args and backslashes escape the next character (outside
+single quotes).
+*/
+func splitArgs(value string) ([]string, error) {
+	args := []string{}
+	var current strings.Builder
+	inArg := false
+	var quote rune
+	escaped := false
+
+	for _, r := range value {
+		switch {
+		case escaped:
+			current.WriteRune(r)
+			escaped = false
+		case r == '\\' && quote != '\'':
+			escaped = true
+			inArg = true
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '\'' || r == '"':
+			quote = r
+			inArg = true
+		case r == ' ' || r == '\t' || r == '\n':
+			if inArg {
+				args = append(args, current.String())
+				current.Reset()
+				inArg = false
+			}
+		default:
+			current.WriteRune(r)
+			inArg = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote")
+	}
+
+	if escaped {
+		return nil, fmt.Errorf("trailing backslash")
+	}
+
+	if inArg {
+		args = append(args, current.String())
+	}
+
+	return args, nil
+}
+
+type aliasCommand struct {
+	name     string
+	value    string
+	commands map[string]cli.CommandFactory
+	runner   command.Runner
+}
+
+/*
+Runs a shell alias (ie: "!git pull && trellis deploy production") with sh.
+Like git, args are passed as positional parameters so they're appended to the
+command. Command aliases are expanded before dispatch so they never get here.
+*/
+func (c *aliasCommand) Run(args []string) int {
+	if !isShellAlias(c.value) {
+		return 1
+	}
+
+	shellArgs := append([]string{"-c", c.value[1:] + ` "$@"`, c.name}, args...)
+
+	shell := command.New(c.runner, command.WithTermOutput()).Cmd("sh", shellArgs)
+	shell.Env = os.Environ()
+
+	return command.ExitCode(shell.Run())
+}
+
+func (c *aliasCommand) Synopsis() string {
+	return c.value
+}
+
+func (c *aliasCommand) Help() string {
+	return fmt.Sprintf("Usage: trellis %s [<args>]\n\nAlias for '%s'", c.name, c.value)
+}
+
+// Completes the aliased command's args (ie: sites for a "deploy production" alias).
+func (c *aliasCommand) target() cli.CommandAutocomplete {
+	if isShellAlias(c.value) {
+		return nil
+	}
+
+	args, err := splitArgs(c.value)
+	if err != nil {
+		return nil
+	}
+
+	// Find the longest matching command (ie: "db pull" over "db")
+	for i := len(args); i > 0; i-- {
+		factory, ok := c.commands[strings.Join(args[:i], " ")]
+		if !ok {
+			continue
+		}
+
+		command, err := factory()
+		if err != nil {
+			return nil
+		}
+
+		if autocomplete, ok := command.(cli.CommandAutocomplete); ok {
+			return autocomplete
+		}
+
+		return nil
+	}
+
+	return nil
+}
+
+func (c *aliasCommand) AutocompleteArgs() complete.Predictor {
+	if target := c.target(); target != nil {
+		return target.AutocompleteArgs()
+	}
+
+	return complete.PredictNothing
+}
+
+func (c *aliasCommand) AutocompleteFlags() complete.Flags {
+	if target := c.target(); target != nil {
+		return target.AutocompleteFlags()
+	}
+
+	return complete.Flags{}
+}
+
+// Lists aliases under their own heading instead of with the other commands.
+func aliasHelpFunc(aliases map[string]string, f cli.HelpFunc) cli.HelpFunc {
+	return func(commands map[string]cli.CommandFactory) string {
+		filteredCommands := make(map[string]cli.CommandFactory)
+
+		for key, command := range commands {
+			if _, ok := aliases[key]; !ok {
+				filteredCommands[key] = command
+			}
+		}
+
+		if len(aliases) == 0 {
+			return f(filteredCommands)
+		}
+
+		names := make([]string, 0, len(aliases))
+		maxKeyLen := 0
+
+		for name := range aliases {
+			names = append(names, name)
+
+			if len(name) > maxKeyLen {
+				maxKeyLen = len(name)
+			}
+		}
+
+		sort.Strings(names)
+
+		var buf strings.Builder
+		buf.WriteString("\n\nAliases:\n")
+
+		for _, name := range names {
+			buf.WriteString(fmt.Sprintf("    %-*s    %s\n", maxKeyLen, name, aliases[name]))
+		}
+
+		return strings.TrimRight(f(filteredCommands), "\n") + buf.String()
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"reflect"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/cmd"
+	"github.com/roots/trellis-cli/command"
+)
+
+func TestSplitArgs(t *testing.T) {
+	cases := []struct {
+		value    string
+		expected []string
+		err      bool
+	}{
+		{"deploy production", []string{"deploy", "production"}, false},
+		{"  deploy   --branch=main production ", []string{"deploy", "--branch=main", "production"}, false},
+		{`provision --extra-vars "a=b c=d" production`, []string{"provision", "--extra-vars", "a=b c=d", "production"}, false},
+		{`ssh 'it'"'"'s' foo\ bar`, []string{"ssh", "it's", "foo bar"}, false},
+		{`deploy ""`, []string{"deploy", ""}, false},
+		{`deploy "production`, nil, true},
+		{`deploy production\`, nil, true},
+	}
+
+	for _, tc := range cases {
+		args, err := splitArgs(tc.value)
+
+		if (err != nil) != tc.err {
+			t.Errorf("%s: expected error %v, got %v", tc.value, tc.err, err)
+		}
+
+		if !tc.err && !reflect.DeepEqual(args, tc.expected) {
+			t.Errorf("%s: expected %q, got %q", tc.value, tc.expected, args)
+		}
+	}
+}
+
+func TestExpandAlias(t *testing.T) {
+	aliases := map[string]string{
+		"dp":   "deploy --branch=main production",
+		"sync": "!git pull && trellis deploy production",
+	}
+
+	cases := []struct {
+		args     []string
+		expected []string
+	}{
+		{[]string{"dp"}, []string{"deploy", "--branch=main", "production"}},
+		{[]string{"dp", "example.com"}, []string{"deploy", "--branch=main", "production", "example.com"}},
+		{[]string{"sync", "foo"}, []string{"sync", "foo"}},
+		{[]string{"deploy", "dp"}, []string{"deploy", "dp"}},
+		{[]string{}, []string{}},
+	}
+
+	for _, tc := range cases {
+		args, err := expandAlias(tc.args, aliases)
+
+		if err != nil {
+			t.Errorf("%v: unexpected error: %v", tc.args, err)
+		}
+
+		if !reflect.DeepEqual(args, tc.expected) {
+			t.Errorf("%v: expected %q, got %q", tc.args, tc.expected, args)
+		}
+	}
+}
+
+func TestRegisterAliases(t *testing.T) {
+	c := cli.NewCLI("trellis", "test")
+	c.Commands = map[string]cli.CommandFactory{
+		"deploy": func() (cli.Command, error) {
+			return &cmd.NamespaceCommand{SynopsisText: "Deploys a site"}, nil
+		},
+		"venv hook": func() (cli.Command, error) {
+			return &cmd.NamespaceCommand{}, nil
+		},
+		"spy": func() (cli.Command, error) {
+			return &cmd.PassthroughCommand{Name: "spy", Bin: "/usr/local/bin/trellis-spy"}, nil
+		},
+	}
+
+	ui := cli.NewMockUi()
+	aliases := registerAliases(c, ui, command.NewFakeRunner(), map[string]string{
+		"dp":     "deploy production",
+		"deploy": "deploy production",
+		"venv":   "venv hook",
+		"spy":    "deploy production",
+		"bad":    `deploy "production`,
+		"sync":   "!git pull",
+	})
+
+	expected := map[string]string{
+		"dp":   "deploy production",
+		"sync": "!git pull",
+	}
+
+	if !reflect.DeepEqual(aliases, expected) {
+		t.Errorf("expected aliases %v to be %v", aliases, expected)
+	}
+
+	for _, name := range []string{"dp", "sync"} {
+		if _, ok := c.Commands[name]; !ok {
+			t.Errorf("expected alias %s to be registered as a command", name)
+		}
+	}
+
+	warnings := ui.ErrorWriter.String()
+
+	for _, expected := range []string{
+		`ignoring alias "deploy": it would shadow the 'deploy' command`,
+		`ignoring alias "venv": it would shadow the 'venv' command`,
+		`ignoring alias "spy": it would shadow the 'spy' command`,
+		`ignoring alias "bad": unterminated quote`,
+	} {
+		if !strings.Contains(warnings, expected) {
+			t.Errorf("expected warnings %q to contain %q", warnings, expected)
+		}
+	}
+}
+
+func TestAliasHelpFunc(t *testing.T) {
+	aliases := map[string]string{"dp": "deploy production", "sync": "!git pull"}
+	commands := map[string]cli.CommandFactory{
+		"deploy": func() (cli.Command, error) { return &cmd.NamespaceCommand{SynopsisText: "Deploys a site"}, nil },
+		"dp":     func() (cli.Command, error) { return &aliasCommand{}, nil },
+		"sync":   func() (cli.Command, error) { return &aliasCommand{}, nil },
+	}
+
+	helpFunc := aliasHelpFunc(aliases, cli.BasicHelpFunc("trellis"))
+	output := helpFunc(commands)
+
+	expected := "\n\nAliases:\n    dp      deploy production\n    sync    !git pull\n"
+
+	if !strings.HasSuffix(output, expected) {
+		t.Errorf("expected output %q to end with %q", output, expected)
+	}
+
+	if strings.Contains(strings.TrimSuffix(output, expected), "dp") {
+		t.Errorf("expected aliases not to be listed with the other commands: %q", output)
+	}
+}
+
+func TestAliasCommandRunShellAlias(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	aliasCommand := &aliasCommand{name: "fail", value: `!test "$1" = ok || exit 3`, runner: command.NewExecRunner()}
+
+	if code := aliasCommand.Run([]string{"ok"}); code != 0 {
+		t.Errorf("expected code %d to be 0", code)
+	}
+
+	if code := aliasCommand.Run([]string{"nope"}); code != 3 {
+		t.Errorf("expected code %d to be 3", code)
+	}
+}
@@ -1,6 +1,7 @@
 package cli_config
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
 	"os"
@@ -12,31 +13,49 @@ import (
 )
 
 type VmImage struct {
-	Location string `yaml:"location"`
-	Arch     string `yaml:"arch"`
+	Location string `yaml:"location" json:"location"`
+	Arch     string `yaml:"arch" json:"arch"`
 }
 
 type VmConfig struct {
-	Manager       string    `yaml:"manager"`
-	HostsResolver string    `yaml:"hosts_resolver"`
-	Images        []VmImage `yaml:"images"`
-	Ubuntu        string    `yaml:"ubuntu"`
+	Manager       string    `yaml:"manager" json:"manager"`
+	HostsResolver string    `yaml:"hosts_resolver" json:"hosts_resolver"`
+	Images        []VmImage `yaml:"images" json:"images"`
+	Ubuntu        string    `yaml:"ubuntu" json:"ubuntu"`
+}
+
+type DropletConfig struct {
+	Region     string `yaml:"region" json:"region"`
+	Size       string `yaml:"size" json:"size"`
+	Image      string `yaml:"image" json:"image"`
+	Backups    bool   `yaml:"backups" json:"backups"`
+	Monitoring bool   `yaml:"monitoring" json:"monitoring"`
+	VpcUUID    string `yaml:"vpc_uuid" json:"vpc_uuid"`
+	Firewall   bool   `yaml:"firewall" json:"firewall"`
+	ReservedIP bool   `yaml:"reserved_ip" json:"reserved_ip"`
+	UserData   string `yaml:"user_data" json:"user_data"`
 }
 
 type Config struct {
-	AllowDevelopmentDeploys bool              `yaml:"allow_development_deploys"`
-	AskVaultPass            bool              `yaml:"ask_vault_pass"`
-	DatabaseApp             string            `yaml:"database_app"`
-	CheckForUpdates         bool              `yaml:"check_for_updates"`
-	LoadPlugins             bool              `yaml:"load_plugins"`
-	Open                    map[string]string `yaml:"open"`
-	VirtualenvIntegration   bool              `yaml:"virtualenv_integration"`
-	Vm                      VmConfig          `yaml:"vm"`
+	Aliases                 map[string]string `yaml:"aliases" json:"aliases"`
+	AllowDevelopmentDeploys bool              `yaml:"allow_development_deploys" json:"allow_development_deploys"`
+	AskVaultPass            bool              `yaml:"ask_vault_pass" json:"ask_vault_pass"`
+	DatabaseApp             string            `yaml:"database_app" json:"database_app"`
+	Droplet                 DropletConfig     `yaml:"droplet" json:"droplet"`
+	CheckForUpdates         bool              `yaml:"check_for_updates" json:"check_for_updates"`
+	LoadPlugins             bool              `yaml:"load_plugins" json:"load_plugins"`
+	Open                    map[string]string `yaml:"open" json:"open"`
+	VirtualenvIntegration   bool              `yaml:"virtualenv_integration" json:"virtualenv_integration"`
+	Vm                      VmConfig          `yaml:"vm" json:"vm"`
+
+	// Settings overlaid per environment (see ApplyEnvironment)
+	environments map[string][][]byte
 }
 
 var (
 	UnsupportedTypeErr = errors.New("Invalid env var config setting: value is an unsupported type.")
 	CouldNotParseErr   = errors.New("Invalid env var config setting: failed to parse value")
+	InvalidValueErr    = errors.New("Invalid env var config setting: unsupported value.")
 	InvalidConfigErr   = errors.New("Invalid config file")
 )
 
@@ -51,37 +70,59 @@ func (c *Config) LoadFile(path string) error {
 		return err
 	}
 
-	if err := yaml.Unmarshal(configYaml, &c); err != nil {
-		return fmt.Errorf("%w: %s", InvalidConfigErr, err)
-	}
+	return c.Load(configYaml)
+}
 
-	if c.Vm.Manager != "lima" && c.Vm.Manager != "auto" && c.Vm.Manager != "mock" {
-		return fmt.Errorf("%w: unsupported value for `vm.manager`. Must be one of: auto, lima", InvalidConfigErr)
+// Loads and validates config file contents. Unknown keys are errors.
+func (c *Config) Load(configYaml []byte) error {
+	if err := checkUnknownKeys(configYaml); err != nil {
+		return err
 	}
 
-	if c.Vm.Ubuntu != "18.04" && c.Vm.Ubuntu != "20.04" && c.Vm.Ubuntu != "22.04" && c.Vm.Ubuntu != "24.04" {
-		return fmt.Errorf("%w: unsupported value for `vm.ubuntu`. Must be one of: 18.04, 20.04, 22.04, 24.04", InvalidConfigErr)
+	if err := yaml.Unmarshal(configYaml, &c); err != nil {
+		return fmt.Errorf("%w: %s", InvalidConfigErr, err)
 	}
 
-	if c.Vm.HostsResolver != "hosts_file" {
-		return fmt.Errorf("%w: unsupported value for `vm.hosts_resolver`. Must be one of: hosts_file", InvalidConfigErr)
+	for _, setting := range c.enumSettings() {
+		if err := checkEnum(setting.key, setting.value, setting.hidden...); err != nil {
+			return err
+		}
 	}
 
-	if c.DatabaseApp != "" && c.DatabaseApp != "tableplus" && c.DatabaseApp != "sequel-ace" {
-		return fmt.Errorf("%w: unsupported value for `database_app`. Must be one of: tableplus, sequel-ace", InvalidConfigErr)
+	if err := c.loadEnvironments(configYaml); err != nil {
+		return err
 	}
 
 	return nil
 }
 
-func (c *Config) LoadEnv(prefix string) error {
-	structType := reflect.ValueOf(c).Elem()
-	fields := reflect.VisibleFields(structType.Type())
+type enumSetting struct {
+	key   string
+	value string
+	// Allowed values which aren't documented (see Enums)
+	hidden []string
+}
+
+// Settings limited to a set of values (see Enums).
+func (c *Config) enumSettings() []enumSetting {
+	return []enumSetting{
+		// "mock" is only used in tests
+		{"vm.manager", c.Vm.Manager, []string{"mock"}},
+		{"vm.ubuntu", c.Vm.Ubuntu, nil},
+		{"vm.hosts_resolver", c.Vm.HostsResolver, nil},
+		{"database_app", c.DatabaseApp, []string{""}},
+	}
+}
 
+/*
+Overrides settings with env vars matching their keys (ie: TRELLIS_ASK_VAULT_PASS).
+Nested settings are joined by a single underscore (TRELLIS_VM_MANAGER) and map
+entries by two (TRELLIS_OPEN__SENTRY). Lists are set with JSON values.
+Overridden values are validated like config files (see Load).
+*/
+func (c *Config) LoadEnv(prefix string) error {
 	for _, env := range os.Environ() {
-		parts := strings.Split(env, "=")
-		originalKey := parts[0]
-		value := parts[1]
+		originalKey, value, _ := strings.Cut(env, "=")
 
 		key := strings.TrimPrefix(originalKey, prefix)
 
@@ -90,43 +131,108 @@ func (c *Config) LoadEnv(prefix string) error {
 			continue
 		}
 
-		for _, field := range fields {
-			if strings.ToLower(key) == field.Tag.Get("yaml") {
-				structValue := structType.FieldByName(field.Name)
-
-				if !structValue.CanSet() {
-					continue
-				}
-
-				switch field.Type.Kind() {
-				case reflect.Bool:
-					val, err := strconv.ParseBool(value)
-
-					if err != nil {
-						return fmt.Errorf("%w '%s'\n'%s' can't be parsed as a boolean", CouldNotParseErr, env, value)
-					}
-
-					structValue.SetBool(val)
-				case reflect.Int:
-					val, err := strconv.ParseInt(value, 10, 32)
-
-					if err != nil {
-						return fmt.Errorf("%w '%s'\n'%s' can't be parsed as an integer", CouldNotParseErr, env, value)
-					}
-
-					structValue.SetInt(val)
-				case reflect.Float32:
-					val, err := strconv.ParseFloat(value, 32)
-					if err != nil {
-						return fmt.Errorf("%w '%s'\n'%s' can't be parsed as a float", CouldNotParseErr, env, value)
-					}
-
-					structValue.SetFloat(val)
-				default:
-					return fmt.Errorf("%w\n%s setting of type %s is unsupported.", UnsupportedTypeErr, env, field.Type.String())
-				}
+		if _, err := setEnvValue(reflect.ValueOf(c).Elem(), strings.ToLower(key), value, env); err != nil {
+			return err
+		}
+	}
+
+	for _, setting := range c.enumSettings() {
+		env := EnvVarName(prefix, setting.key)
+
+		if _, ok := os.LookupEnv(env); !ok {
+			continue
+		}
+
+		if err := checkEnum(setting.key, setting.value, setting.hidden...); err != nil {
+			return fmt.Errorf("%w\n%s=%s must be one of: %s", InvalidValueErr, env, setting.value, strings.Join(Enums[setting.key], ", "))
+		}
+	}
+
+	return nil
+}
+
+// Sets the field of structValue matching key and reports whether one matched.
+func setEnvValue(structValue reflect.Value, key string, value string, env string) (bool, error) {
+	for _, field := range reflect.VisibleFields(structValue.Type()) {
+		tag := yamlTag(field)
+		fieldValue := structValue.FieldByIndex(field.Index)
+
+		if tag == "" || !fieldValue.CanSet() {
+			continue
+		}
+
+		switch {
+		case key == tag:
+			return true, setValue(fieldValue, value, env)
+		case field.Type.Kind() == reflect.Struct && strings.HasPrefix(key, tag+"_"):
+			if ok, err := setEnvValue(fieldValue, strings.TrimPrefix(key, tag+"_"), value, env); ok || err != nil {
+				return ok, err
+			}
+		case field.Type.Kind() == reflect.Map && strings.HasPrefix(key, tag+"__"):
+			mapKey := strings.TrimPrefix(key, tag+"__")
+			if mapKey == "" {
+				continue
+			}
+
+			elem := reflect.New(field.Type.Elem()).Elem()
+			if err := setValue(elem, value, env); err != nil {
+				return true, err
+			}
+
+			// Copy the map so defaults shared by other configs aren't modified
+			m := reflect.MakeMap(field.Type)
+			iter := fieldValue.MapRange()
+			for iter.Next() {
+				m.SetMapIndex(iter.Key(), iter.Value())
 			}
+
+			m.SetMapIndex(reflect.ValueOf(mapKey), elem)
+			fieldValue.Set(m)
+
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+func setValue(fieldValue reflect.Value, value string, env string) error {
+	switch fieldValue.Kind() {
+	case reflect.Bool:
+		val, err := strconv.ParseBool(value)
+
+		if err != nil {
+			return fmt.Errorf("%w '%s'\n'%s' can't be parsed as a boolean", CouldNotParseErr, env, value)
+		}
+
+		fieldValue.SetBool(val)
+	case reflect.Int:
+		val, err := strconv.ParseInt(value, 10, 32)
+
+		if err != nil {
+			return fmt.Errorf("%w '%s'\n'%s' can't be parsed as an integer", CouldNotParseErr, env, value)
+		}
+
+		fieldValue.SetInt(val)
+	case reflect.Float32:
+		val, err := strconv.ParseFloat(value, 32)
+		if err != nil {
+			return fmt.Errorf("%w '%s'\n'%s' can't be parsed as a float", CouldNotParseErr, env, value)
 		}
+
+		fieldValue.SetFloat(val)
+	case reflect.String:
+		fieldValue.SetString(value)
+	case reflect.Slice:
+		val := reflect.New(fieldValue.Type())
+
+		if err := json.Unmarshal([]byte(value), val.Interface()); err != nil {
+			return fmt.Errorf("%w '%s'\n'%s' can't be parsed as a JSON list: %v", CouldNotParseErr, env, value, err)
+		}
+
+		fieldValue.Set(val.Elem())
+	default:
+		return fmt.Errorf("%w\n%s setting of type %s is unsupported.", UnsupportedTypeErr, env, fieldValue.Type().String())
 	}
 
 	return nil
@@ -4,6 +4,7 @@ import (
 	_ "fmt"
 	"os"
 	"path/filepath"
+	"reflect"
 	"strings"
 	"testing"
 )
@@ -60,6 +61,73 @@ func TestLoadEnv(t *testing.T) {
 	}
 }
 
+func TestLoadEnvNested(t *testing.T) {
+	t.Setenv("TRELLIS_DATABASE_APP", "tableplus")
+	t.Setenv("TRELLIS_VM_MANAGER", "lima")
+	t.Setenv("TRELLIS_VM_HOSTS_RESOLVER", "hosts_file")
+	t.Setenv("TRELLIS_VM_IMAGES", `[{"location": "https://example.com/ubuntu.img", "arch": "aarch64"}]`)
+	t.Setenv("TRELLIS_VM_NOPE", "foo")
+	t.Setenv("TRELLIS_OPEN__SENTRY", "https://sentry.io/?project=1")
+
+	open := map[string]string{"site": "https://example.com"}
+
+	conf := Config{
+		Open: open,
+		Vm:   VmConfig{Manager: "auto"},
+	}
+
+	if err := conf.LoadEnv("TRELLIS_"); err != nil {
+		t.Fatal(err)
+	}
+
+	if conf.DatabaseApp != "tableplus" {
+		t.Errorf("expected DatabaseApp to be tableplus, got %s", conf.DatabaseApp)
+	}
+
+	if conf.Vm.Manager != "lima" {
+		t.Errorf("expected Vm.Manager to be lima, got %s", conf.Vm.Manager)
+	}
+
+	if conf.Vm.HostsResolver != "hosts_file" {
+		t.Errorf("expected Vm.HostsResolver to be hosts_file, got %s", conf.Vm.HostsResolver)
+	}
+
+	expectedImages := []VmImage{{Location: "https://example.com/ubuntu.img", Arch: "aarch64"}}
+
+	if !reflect.DeepEqual(conf.Vm.Images, expectedImages) {
+		t.Errorf("expected Vm.Images to be %v, got %v", expectedImages, conf.Vm.Images)
+	}
+
+	expectedOpen := map[string]string{"site": "https://example.com", "sentry": "https://sentry.io/?project=1"}
+
+	if !reflect.DeepEqual(conf.Open, expectedOpen) {
+		t.Errorf("expected Open to be %v, got %v", expectedOpen, conf.Open)
+	}
+
+	if _, ok := open["sentry"]; ok {
+		t.Errorf("expected the original Open map not to be modified")
+	}
+}
+
+func TestLoadEnvListParseError(t *testing.T) {
+	t.Setenv("TRELLIS_VM_IMAGES", "foo")
+
+	conf := Config{}
+
+	err := conf.LoadEnv("TRELLIS_")
+
+	if err == nil {
+		t.Fatalf("expected LoadEnv to return an error")
+	}
+
+	expected := `Invalid env var config setting: failed to parse value 'TRELLIS_VM_IMAGES=foo'
+'foo' can't be parsed as a JSON list`
+
+	if !strings.HasPrefix(err.Error(), expected) {
+		t.Errorf("expected error %s to start with %s", err.Error(), expected)
+	}
+}
+
 func TestLoadBoolParseError(t *testing.T) {
 	t.Setenv("TRELLIS_ASK_VAULT_PASS", "foo")
 
@@ -83,6 +151,27 @@ Invalid env var config setting: failed to parse value 'TRELLIS_ASK_VAULT_PASS=fo
 	}
 }
 
+func TestLoadEnvInvalidEnum(t *testing.T) {
+	t.Setenv("TRELLIS_VM_MANAGER", "bogus")
+
+	conf := Config{}
+
+	err := conf.LoadEnv("TRELLIS_")
+
+	if err == nil {
+		t.Fatal("expected LoadEnv to return an error")
+	}
+
+	expected := `
+Invalid env var config setting: unsupported value.
+TRELLIS_VM_MANAGER=bogus must be one of: auto, lima
+`
+
+	if err.Error() != strings.TrimSpace(expected) {
+		t.Errorf("expected error %s got %s", expected, err)
+	}
+}
+
 func TestLoadEnvUnsupportedType(t *testing.T) {
 	t.Setenv("TRELLIS_OPEN", "foo")
 
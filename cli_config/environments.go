@@ -0,0 +1,84 @@
+package cli_config
+
+import (
+	"fmt"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+/*
+Parses the `environments` block of config file contents. Each environment's
+settings are kept as YAML so they can be overlaid (with the same validation as
+config files) once the environment is known.
+*/
+func (c *Config) loadEnvironments(configYaml []byte) error {
+	var file struct {
+		Environments map[string]yaml.MapSlice `yaml:"environments"`
+	}
+
+	if err := yaml.Unmarshal(configYaml, &file); err != nil {
+		return fmt.Errorf("%w: %s", InvalidConfigErr, err)
+	}
+
+	if len(file.Environments) == 0 {
+		return nil
+	}
+
+	// Copy the map so configs sharing it (ie: clones) aren't modified
+	environments := make(map[string][][]byte, len(c.environments)+len(file.Environments))
+	for name, overlays := range c.environments {
+		environments[name] = overlays
+	}
+
+	for _, name := range sortedKeys(file.Environments) {
+		settings := file.Environments[name]
+
+		for _, item := range settings {
+			if item.Key == "environments" {
+				return fmt.Errorf("%w: `environments.%s.environments` is not supported", InvalidConfigErr, name)
+			}
+		}
+
+		overlay, err := yaml.Marshal(settings)
+		if err != nil {
+			return fmt.Errorf("%w: %s", InvalidConfigErr, err)
+		}
+
+		candidate := c.Clone()
+		if err := candidate.Load(overlay); err != nil {
+			return fmt.Errorf("%w (in `environments.%s`)", err, name)
+		}
+
+		environments[name] = append(append([][]byte{}, environments[name]...), overlay)
+	}
+
+	c.environments = environments
+
+	return nil
+}
+
+/*
+Overlays the settings from the `environments` block for an environment
+(ie: `environments: {production: {ask_vault_pass: true}}`).
+*/
+func (c *Config) ApplyEnvironment(name string) error {
+	for _, overlay := range c.environments[name] {
+		if err := c.Load(overlay); err != nil {
+			return fmt.Errorf("%w (in `environments.%s`)", err, name)
+		}
+	}
+
+	return nil
+}
+
+func sortedKeys(m map[string]yaml.MapSlice) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
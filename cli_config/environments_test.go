@@ -0,0 +1,107 @@
+package cli_config
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestApplyEnvironment(t *testing.T) {
+	conf := Config{
+		Vm:   VmConfig{Manager: "auto", Ubuntu: "24.04", HostsResolver: "hosts_file"},
+		Open: map[string]string{"site": "https://example.com"},
+	}
+
+	global := `
+environments:
+  production:
+    ask_vault_pass: true
+    open:
+      site: https://global.example.com
+`
+
+	project := `
+database_app: tableplus
+environments:
+  production:
+    open:
+      site: https://project.example.com
+  staging:
+    database_app: mysql
+`
+
+	if err := conf.Load([]byte(global)); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := conf.Load([]byte(project)); err != nil {
+		t.Fatal(err)
+	}
+
+	if conf.AskVaultPass || conf.Open["site"] != "https://example.com" {
+		t.Errorf("expected environment settings not to be applied on load")
+	}
+
+	staging := conf.Clone()
+	if err := staging.ApplyEnvironment("staging"); err != nil {
+		t.Fatal(err)
+	}
+
+	if staging.DatabaseApp != "mysql" {
+		t.Errorf("expected staging DatabaseApp to be mysql, got %s", staging.DatabaseApp)
+	}
+
+	if err := conf.ApplyEnvironment("production"); err != nil {
+		t.Fatal(err)
+	}
+
+	if !conf.AskVaultPass {
+		t.Errorf("expected production AskVaultPass to be true")
+	}
+
+	if conf.DatabaseApp != "tableplus" {
+		t.Errorf("expected production DatabaseApp to be tableplus, got %s", conf.DatabaseApp)
+	}
+
+	if conf.Open["site"] != "https://project.example.com" {
+		t.Errorf("expected later config files to take precedence, got %s", conf.Open["site"])
+	}
+
+	if err := conf.ApplyEnvironment("development"); err != nil {
+		t.Errorf("expected environments without settings to be ignored, got %v", err)
+	}
+}
+
+func TestLoadEnvironmentsInvalid(t *testing.T) {
+	cases := []struct {
+		name     string
+		yaml     string
+		expected string
+	}{
+		{
+			"invalid_value",
+			"environments:\n  production:\n    database_app: nope\n",
+			"Invalid config file: unsupported value for `database_app`. Must be one of: tableplus, sequel-ace, dbeaver, beekeeper-studio, mysql (in `environments.production`)",
+		},
+		{
+			"nested",
+			"environments:\n  production:\n    environments: {}\n",
+			"Invalid config file: `environments.production.environments` is not supported",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := Config{Vm: VmConfig{Manager: "auto", Ubuntu: "24.04", HostsResolver: "hosts_file"}}
+
+			err := conf.Load([]byte(tc.yaml))
+
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+
+			if !strings.Contains(err.Error(), tc.expected) {
+				t.Errorf("expected error %q to contain %q", err.Error(), tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,243 @@
+package cli_config
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+const SchemaURL = "https://json-schema.org/draft-07/schema#"
+
+// Supported values of string settings. Used for validation and the JSON Schema.
+var Enums = map[string][]string{
+	"database_app":      {"tableplus", "sequel-ace", "dbeaver", "beekeeper-studio", "mysql"},
+	"vm.hosts_resolver": {"hosts_file"},
+	"vm.manager":        {"auto", "lima"},
+	"vm.ubuntu":         {"18.04", "20.04", "22.04", "24.04"},
+}
+
+/*
+Returns a JSON Schema (draft-07) for CLI config files generated from Config.
+Editors can use it to validate and complete trellis.cli.yml files.
+*/
+func Schema() ([]byte, error) {
+	settings := typeSchema("", reflect.TypeOf(Config{}))
+	properties := settings["properties"].(map[string]interface{})
+
+	schema := map[string]interface{}{
+		"$schema":              SchemaURL,
+		"title":                "trellis-cli config",
+		"type":                 "object",
+		"additionalProperties": false,
+		"properties": map[string]interface{}{
+			"environments": map[string]interface{}{
+				"description":          "Settings overridden for commands run for an environment",
+				"type":                 "object",
+				"additionalProperties": settings,
+			},
+		},
+	}
+
+	for key, property := range properties {
+		schema["properties"].(map[string]interface{})[key] = property
+	}
+
+	return json.MarshalIndent(schema, "", "  ")
+}
+
+func typeSchema(key string, t reflect.Type) map[string]interface{} {
+	switch t.Kind() {
+	case reflect.Struct:
+		properties := make(map[string]interface{})
+
+		for i := 0; i < t.NumField(); i++ {
+			if tag := yamlTag(t.Field(i)); tag != "" {
+				properties[tag] = typeSchema(joinKey(key, tag), t.Field(i).Type)
+			}
+		}
+
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": false,
+			"properties":           properties,
+		}
+	case reflect.Map:
+		return map[string]interface{}{
+			"type":                 "object",
+			"additionalProperties": typeSchema(key, t.Elem()),
+		}
+	case reflect.Slice:
+		return map[string]interface{}{
+			"type":  "array",
+			"items": typeSchema(key, t.Elem()),
+		}
+	case reflect.Bool:
+		return map[string]interface{}{"type": "boolean"}
+	case reflect.Int:
+		return map[string]interface{}{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]interface{}{"type": "number"}
+	default:
+		schema := map[string]interface{}{"type": "string"}
+
+		if enum, ok := Enums[key]; ok {
+			schema["enum"] = enum
+		}
+
+		return schema
+	}
+}
+
+func checkEnum(key string, value string, hidden ...string) error {
+	for _, allowed := range append(Enums[key], hidden...) {
+		if value == allowed {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("%w: unsupported value for `%s`. Must be one of: %s", InvalidConfigErr, key, strings.Join(Enums[key], ", "))
+}
+
+/*
+Returns an error for the first key in config file contents which isn't a
+setting, suggesting the closest known setting when there's a likely typo.
+Keys under `environments` are checked when each environment's settings are loaded.
+*/
+func checkUnknownKeys(configYaml []byte) error {
+	keys, err := FileKeys(configYaml)
+	if err != nil {
+		return err
+	}
+
+	for _, key := range keys {
+		if key == "environments" || strings.HasPrefix(key, "environments.") {
+			continue
+		}
+
+		if _, err := fileKeyType(key); err == nil {
+			continue
+		}
+
+		unknownKey := unknownKeyPrefix(key)
+
+		if suggestion := closestKey(unknownKey); suggestion != "" {
+			return fmt.Errorf("%w: unknown key `%s`, did you mean `%s`?", InvalidConfigErr, unknownKey, suggestion)
+		}
+
+		return fmt.Errorf("%w: unknown key `%s`", InvalidConfigErr, unknownKey)
+	}
+
+	return nil
+}
+
+// Returns the shortest unknown part of a key (ie: vm.cpu for vm.cpu.count).
+func unknownKeyPrefix(key string) string {
+	parts := strings.Split(key, ".")
+
+	for i := range parts {
+		prefix := strings.Join(parts[:i+1], ".")
+
+		if _, err := fileKeyType(prefix); err != nil {
+			return prefix
+		}
+	}
+
+	return key
+}
+
+/*
+Returns the type of a key from a config file. Unlike KeyType, it also resolves
+keys of list entries with the entries' schema (ie: vm.images[].location).
+*/
+func fileKeyType(key string) (reflect.Type, error) {
+	t := reflect.TypeOf(Config{})
+
+	for _, part := range strings.Split(key, ".") {
+		entry := strings.HasSuffix(part, "[]")
+
+		var ok bool
+		if t, ok = childType(t, strings.TrimSuffix(part, "[]")); !ok {
+			return nil, fmt.Errorf("%w `%s`", UnknownKeyErr, key)
+		}
+
+		if entry {
+			if t.Kind() != reflect.Slice {
+				return nil, fmt.Errorf("%w `%s`", UnknownKeyErr, key)
+			}
+
+			t = t.Elem()
+		}
+	}
+
+	return t, nil
+}
+
+func closestKey(key string) string {
+	closest := ""
+	minDistance := len(key)/3 + 1
+
+	for _, known := range knownKeys() {
+		if distance := levenshtein(key, known); distance <= minDistance {
+			closest = known
+			minDistance = distance
+		}
+	}
+
+	return closest
+}
+
+// Returns the keys of all settings and sections (excluding map entries).
+func knownKeys() []string {
+	keys := []string{"environments"}
+	collectKeys("", reflect.TypeOf(Config{}), &keys)
+	sort.Strings(keys)
+
+	return keys
+}
+
+func collectKeys(prefix string, t reflect.Type, keys *[]string) {
+	for i := 0; i < t.NumField(); i++ {
+		tag := yamlTag(t.Field(i))
+		if tag == "" {
+			continue
+		}
+
+		key := joinKey(prefix, tag)
+		*keys = append(*keys, key)
+
+		switch field := t.Field(i).Type; {
+		case field.Kind() == reflect.Struct:
+			collectKeys(key, field, keys)
+		case field.Kind() == reflect.Slice && field.Elem().Kind() == reflect.Struct:
+			collectKeys(key+"[]", field.Elem(), keys)
+		}
+	}
+}
+
+func levenshtein(a string, b string) int {
+	previous := make([]int, len(b)+1)
+	current := make([]int, len(b)+1)
+
+	for j := range previous {
+		previous[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		current[0] = i
+
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+
+		previous, current = current, previous
+	}
+
+	return previous[len(b)]
+}
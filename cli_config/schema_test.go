@@ -0,0 +1,111 @@
+package cli_config
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestSchema(t *testing.T) {
+	data, err := Schema()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal(data, &schema); err != nil {
+		t.Fatalf("expected schema to be valid JSON: %v", err)
+	}
+
+	properties := schema["properties"].(map[string]interface{})
+
+	if schema["additionalProperties"] != false {
+		t.Errorf("expected unknown top-level keys to be disallowed")
+	}
+
+	vm := properties["vm"].(map[string]interface{})
+	vmProperties := vm["properties"].(map[string]interface{})
+	manager := vmProperties["manager"].(map[string]interface{})
+
+	if !reflect.DeepEqual(manager["enum"], []interface{}{"auto", "lima"}) {
+		t.Errorf("expected vm.manager enum to be [auto lima], got %v", manager["enum"])
+	}
+
+	images := vmProperties["images"].(map[string]interface{})
+	if images["type"] != "array" {
+		t.Errorf("expected vm.images to be an array, got %v", images["type"])
+	}
+
+	open := properties["open"].(map[string]interface{})
+	if !reflect.DeepEqual(open["additionalProperties"], map[string]interface{}{"type": "string"}) {
+		t.Errorf("expected open to be a map of strings, got %v", open["additionalProperties"])
+	}
+
+	environments := properties["environments"].(map[string]interface{})
+	environmentSettings := environments["additionalProperties"].(map[string]interface{})["properties"].(map[string]interface{})
+
+	if _, ok := environmentSettings["ask_vault_pass"]; !ok {
+		t.Errorf("expected environment settings to include ask_vault_pass")
+	}
+
+	if _, ok := environmentSettings["environments"]; ok {
+		t.Errorf("expected environment settings not to include environments")
+	}
+}
+
+func TestLoadUnknownKeys(t *testing.T) {
+	cases := []struct {
+		name     string
+		yaml     string
+		expected string
+	}{
+		{
+			"typo",
+			"vm:\n  manger: lima\n",
+			"Invalid config file: unknown key `vm.manger`, did you mean `vm.manager`?",
+		},
+		{
+			"top_level_typo",
+			"ask_vault_password: true\n",
+			"Invalid config file: unknown key `ask_vault_password`, did you mean `ask_vault_pass`?",
+		},
+		{
+			"no_suggestion",
+			"vm:\n  cpu: 2\n",
+			"Invalid config file: unknown key `vm.cpu`",
+		},
+		{
+			"list_entry_typo",
+			"vm:\n  images:\n    - location: foo\n    - locaton: bar\n      arch: aarch64\n",
+			"Invalid config file: unknown key `vm.images[].locaton`, did you mean `vm.images[].location`?",
+		},
+		{
+			"environment",
+			"environments:\n  production:\n    databse_app: mysql\n",
+			"Invalid config file: unknown key `databse_app`, did you mean `database_app`? (in `environments.production`)",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			conf := Config{Vm: VmConfig{Manager: "auto", Ubuntu: "24.04", HostsResolver: "hosts_file"}}
+
+			err := conf.Load([]byte(tc.yaml))
+
+			if err == nil {
+				t.Fatalf("expected an error")
+			}
+
+			if err.Error() != tc.expected {
+				t.Errorf("expected error %q, got %q", tc.expected, err.Error())
+			}
+		})
+	}
+
+	conf := Config{Vm: VmConfig{Manager: "auto", Ubuntu: "24.04", HostsResolver: "hosts_file"}}
+	valid := "open:\n  anything: https://example.com\nvm:\n  images:\n    - location: foo\n"
+
+	if err := conf.Load([]byte(valid)); err != nil {
+		t.Errorf("expected map entries and lists to be valid, got %v", err)
+	}
+}
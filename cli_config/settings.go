@@ -0,0 +1,321 @@
+package cli_config
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+	yamlv3 "gopkg.in/yaml.v3"
+)
+
+var UnknownKeyErr = errors.New("unknown config key")
+
+// A config setting identified by its dotted key (ie: vm.manager or open.sentry).
+type Setting struct {
+	Key   string
+	Value string
+}
+
+/*
+Returns every setting sorted by key. Map entries are separate settings
+(ie: open.sentry) and lists are JSON encoded.
+*/
+func (c *Config) Settings() []Setting {
+	settings := []Setting{}
+	flattenValue("", reflect.ValueOf(*c), &settings)
+
+	sort.Slice(settings, func(i, j int) bool {
+		return settings[i].Key < settings[j].Key
+	})
+
+	return settings
+}
+
+func flattenValue(key string, v reflect.Value, settings *[]Setting) {
+	switch v.Kind() {
+	case reflect.Struct:
+		for i := 0; i < v.NumField(); i++ {
+			if tag := yamlTag(v.Type().Field(i)); tag != "" {
+				flattenValue(joinKey(key, tag), v.Field(i), settings)
+			}
+		}
+	case reflect.Map:
+		for _, mapKey := range v.MapKeys() {
+			flattenValue(joinKey(key, mapKey.String()), v.MapIndex(mapKey), settings)
+		}
+	case reflect.Slice:
+		value, _ := json.Marshal(v.Interface())
+		*settings = append(*settings, Setting{Key: key, Value: string(value)})
+	default:
+		*settings = append(*settings, Setting{Key: key, Value: fmt.Sprint(v.Interface())})
+	}
+}
+
+func yamlTag(field reflect.StructField) string {
+	return strings.Split(field.Tag.Get("yaml"), ",")[0]
+}
+
+func joinKey(prefix string, key string) string {
+	if prefix == "" {
+		return key
+	}
+
+	return prefix + "." + key
+}
+
+/*
+Returns the type of a setting. Keys of map settings can be anything
+(ie: open.anything).
+*/
+func KeyType(key string) (reflect.Type, error) {
+	t := reflect.TypeOf(Config{})
+
+	for _, part := range strings.Split(key, ".") {
+		var ok bool
+		if t, ok = childType(t, part); !ok {
+			return nil, fmt.Errorf("%w `%s`", UnknownKeyErr, key)
+		}
+	}
+
+	return t, nil
+}
+
+// Returns the type of a struct field or map value by its key.
+func childType(t reflect.Type, part string) (reflect.Type, bool) {
+	switch t.Kind() {
+	case reflect.Struct:
+		field, ok := structField(t, part)
+		return field.Type, ok
+	case reflect.Map:
+		return t.Elem(), part != ""
+	default:
+		return nil, false
+	}
+}
+
+func structField(t reflect.Type, tag string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		if yamlTag(t.Field(i)) == tag {
+			return t.Field(i), true
+		}
+	}
+
+	return reflect.StructField{}, false
+}
+
+// Parses a setting's value from a string based on the setting's type.
+func ParseValue(key string, value string) (interface{}, error) {
+	t, err := KeyType(key)
+	if err != nil {
+		return nil, err
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		val, err := strconv.ParseBool(value)
+		if err != nil {
+			return nil, fmt.Errorf("`%s` must be a boolean (true or false)", key)
+		}
+
+		return val, nil
+	case reflect.Int:
+		val, err := strconv.Atoi(value)
+		if err != nil {
+			return nil, fmt.Errorf("`%s` must be an integer", key)
+		}
+
+		return val, nil
+	case reflect.String:
+		return value, nil
+	case reflect.Struct, reflect.Map:
+		return nil, fmt.Errorf("`%s` is a section. Set one of its keys instead (ie: %s.<key>)", key, key)
+	default:
+		return nil, fmt.Errorf("`%s` is a %s setting which can't be set from the command line. Edit the config file instead.", key, t.Kind())
+	}
+}
+
+/*
+Returns the config file contents with a setting updated (or added). The file
+is edited as a YAML document so existing comments are preserved.
+*/
+func SetValue(configYaml []byte, key string, value interface{}) ([]byte, error) {
+	var doc yamlv3.Node
+
+	if err := yamlv3.Unmarshal(configYaml, &doc); err != nil {
+		return nil, fmt.Errorf("%w: %s", InvalidConfigErr, err)
+	}
+
+	if doc.Kind == 0 {
+		doc = yamlv3.Node{Kind: yamlv3.DocumentNode, Content: []*yamlv3.Node{{Kind: yamlv3.MappingNode, Tag: "!!map"}}}
+	}
+
+	node := doc.Content[0]
+	if isNull(node) {
+		*node = yamlv3.Node{Kind: yamlv3.MappingNode, Tag: "!!map", HeadComment: node.HeadComment}
+	}
+
+	parts := strings.Split(key, ".")
+
+	for i, part := range parts {
+		if node.Kind != yamlv3.MappingNode {
+			return nil, fmt.Errorf("%w: `%s` is not a mapping", InvalidConfigErr, strings.Join(parts[:i], "."))
+		}
+
+		var child *yamlv3.Node
+
+		for j := 0; j+1 < len(node.Content); j += 2 {
+			if node.Content[j].Value == part {
+				child = node.Content[j+1]
+				break
+			}
+		}
+
+		if child == nil {
+			child = &yamlv3.Node{Kind: yamlv3.MappingNode, Tag: "!!map"}
+			node.Content = append(node.Content, &yamlv3.Node{Kind: yamlv3.ScalarNode, Tag: "!!str", Value: part}, child)
+		} else if isNull(child) && i < len(parts)-1 {
+			// ie: an empty `open:` section
+			*child = yamlv3.Node{Kind: yamlv3.MappingNode, Tag: "!!map", LineComment: child.LineComment}
+		}
+
+		node = child
+	}
+
+	var valueNode yamlv3.Node
+	if err := valueNode.Encode(value); err != nil {
+		return nil, err
+	}
+
+	node.Kind = valueNode.Kind
+	node.Tag = valueNode.Tag
+	node.Value = valueNode.Value
+	node.Style = valueNode.Style
+	node.Content = nil
+
+	var buf bytes.Buffer
+	encoder := yamlv3.NewEncoder(&buf)
+	encoder.SetIndent(2)
+
+	if err := encoder.Encode(&doc); err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
+
+func isNull(node *yamlv3.Node) bool {
+	return node.Kind == yamlv3.ScalarNode && node.Tag == "!!null"
+}
+
+/*
+Returns the dotted keys of the settings defined in config file contents.
+Keys of list entries are included after their list's key (ie: vm.images and
+vm.images[].location).
+*/
+func FileKeys(configYaml []byte) ([]string, error) {
+	values := make(map[string]interface{})
+
+	if err := yaml.Unmarshal(configYaml, &values); err != nil {
+		return nil, fmt.Errorf("%w: %s", InvalidConfigErr, err)
+	}
+
+	keys := []string{}
+	flattenKeys("", values, &keys)
+	sort.Strings(keys)
+
+	return slices.Compact(keys), nil
+}
+
+func flattenKeys(prefix string, value interface{}, keys *[]string) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, val := range v {
+			flattenKeys(joinKey(prefix, key), val, keys)
+		}
+	case map[interface{}]interface{}:
+		for key, val := range v {
+			flattenKeys(joinKey(prefix, fmt.Sprint(key)), val, keys)
+		}
+	case []interface{}:
+		*keys = append(*keys, prefix)
+
+		for _, entry := range v {
+			switch entry.(type) {
+			case map[string]interface{}, map[interface{}]interface{}:
+				flattenKeys(prefix+"[]", entry, keys)
+			}
+		}
+	default:
+		*keys = append(*keys, prefix)
+	}
+}
+
+/*
+Returns the env var which overrides a setting (ie: TRELLIS_VM_MANAGER for
+vm.manager or TRELLIS_OPEN__SENTRY for open.sentry) or an empty string when it
+can't be set by an env var.
+*/
+func EnvVarName(prefix string, key string) string {
+	if _, err := KeyType(key); err != nil {
+		return ""
+	}
+
+	t := reflect.TypeOf(Config{})
+	name := prefix
+
+	for i, part := range strings.Split(key, ".") {
+		switch {
+		case i == 0:
+		case t.Kind() == reflect.Map:
+			name += "__"
+		default:
+			name += "_"
+		}
+
+		if t.Kind() == reflect.Map {
+			// Env var names are lowercased so map keys must be too
+			if strings.Trim(part, "abcdefghijklmnopqrstuvwxyz0123456789_") != "" {
+				return ""
+			}
+
+			t = t.Elem()
+		} else {
+			field, _ := structField(t, part)
+			t = field.Type
+		}
+
+		name += strings.ToUpper(part)
+	}
+
+	return name
+}
+
+// Returns a copy of the config which doesn't share its maps or lists.
+func (c Config) Clone() Config {
+	clone := c
+	clone.Aliases = cloneMap(c.Aliases)
+	clone.Open = cloneMap(c.Open)
+	clone.Vm.Images = append([]VmImage(nil), c.Vm.Images...)
+
+	return clone
+}
+
+func cloneMap(m map[string]string) map[string]string {
+	if m == nil {
+		return nil
+	}
+
+	clone := make(map[string]string, len(m))
+	for key, value := range m {
+		clone[key] = value
+	}
+
+	return clone
+}
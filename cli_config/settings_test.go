@@ -0,0 +1,175 @@
+package cli_config
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSettings(t *testing.T) {
+	conf := Config{
+		AskVaultPass: true,
+		Open:         map[string]string{"sentry": "https://sentry.io"},
+		Vm: VmConfig{
+			Manager: "lima",
+			Images:  []VmImage{{Location: "https://example.com/ubuntu.img", Arch: "aarch64"}},
+		},
+	}
+
+	settings := make(map[string]string)
+	for _, setting := range conf.Settings() {
+		settings[setting.Key] = setting.Value
+	}
+
+	expected := map[string]string{
+		"ask_vault_pass": "true",
+		"droplet.region": "",
+		"open.sentry":    "https://sentry.io",
+		"vm.manager":     "lima",
+		"vm.images":      `[{"location":"https://example.com/ubuntu.img","arch":"aarch64"}]`,
+	}
+
+	for key, value := range expected {
+		if actual, ok := settings[key]; !ok || actual != value {
+			t.Errorf("expected setting %s to be %q, got %q", key, value, actual)
+		}
+	}
+
+	if _, ok := settings["open"]; ok {
+		t.Error("expected maps to be flattened")
+	}
+}
+
+func TestParseValue(t *testing.T) {
+	cases := []struct {
+		key      string
+		value    string
+		expected interface{}
+		err      string
+	}{
+		{"ask_vault_pass", "true", true, ""},
+		{"ask_vault_pass", "yes", nil, "`ask_vault_pass` must be a boolean"},
+		{"vm.manager", "lima", "lima", ""},
+		{"open.sentry", "https://sentry.io", "https://sentry.io", ""},
+		{"vm", "lima", nil, "`vm` is a section"},
+		{"vm.images", "[]", nil, "can't be set from the command line"},
+		{"vm.cpu", "2", nil, "unknown config key `vm.cpu`"},
+		{"vm.manager.foo", "2", nil, "unknown config key `vm.manager.foo`"},
+	}
+
+	for _, tc := range cases {
+		value, err := ParseValue(tc.key, tc.value)
+
+		if tc.err != "" {
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("%s: expected error %q, got %v", tc.key, tc.err, err)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s: unexpected error: %v", tc.key, err)
+		}
+
+		if value != tc.expected {
+			t.Errorf("%s: expected %v, got %v", tc.key, tc.expected, value)
+		}
+	}
+}
+
+func TestSetValue(t *testing.T) {
+	cases := []struct {
+		name     string
+		content  string
+		key      string
+		value    interface{}
+		expected string
+	}{
+		{
+			"empty_file",
+			"",
+			"vm.manager",
+			"lima",
+			"vm:\n  manager: lima\n",
+		},
+		{
+			"preserves_comments",
+			"# Global config\nask_vault_pass: false # never\n\nvm:\n  # Use Lima\n  manager: auto\n",
+			"ask_vault_pass",
+			true,
+			"# Global config\nask_vault_pass: true # never\nvm:\n  # Use Lima\n  manager: auto\n",
+		},
+		{
+			"adds_to_existing_section",
+			"vm:\n  manager: lima # comment\n",
+			"vm.ubuntu",
+			"24.04",
+			"vm:\n  manager: lima # comment\n  ubuntu: \"24.04\"\n",
+		},
+		{
+			"empty_map",
+			"open:\n",
+			"open.sentry",
+			"https://sentry.io",
+			"open:\n  sentry: https://sentry.io\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			output, err := SetValue([]byte(tc.content), tc.key, tc.value)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			if string(output) != tc.expected {
+				t.Errorf("expected output\n%q\nto be\n%q", string(output), tc.expected)
+			}
+		})
+	}
+}
+
+func TestFileKeys(t *testing.T) {
+	keys, err := FileKeys([]byte("ask_vault_pass: true\nopen:\n  sentry: https://sentry.io\nvm:\n  manager: lima\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"ask_vault_pass", "open.sentry", "vm.manager"}
+
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected keys %v to be %v", keys, expected)
+	}
+}
+
+func TestFileKeysListEntries(t *testing.T) {
+	keys, err := FileKeys([]byte("vm:\n  images:\n    - location: foo\n      arch: aarch64\n    - location: bar\n"))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"vm.images", "vm.images[].arch", "vm.images[].location"}
+
+	if !reflect.DeepEqual(keys, expected) {
+		t.Errorf("expected keys %v to be %v", keys, expected)
+	}
+}
+
+func TestEnvVarName(t *testing.T) {
+	cases := map[string]string{
+		"ask_vault_pass":    "TRELLIS_ASK_VAULT_PASS",
+		"vm.manager":        "TRELLIS_VM_MANAGER",
+		"vm.hosts_resolver": "TRELLIS_VM_HOSTS_RESOLVER",
+		"vm.images":         "TRELLIS_VM_IMAGES",
+		"open.sentry":       "TRELLIS_OPEN__SENTRY",
+		"open.my-site":      "",
+		"open.Sentry":       "",
+		"vm.nope":           "",
+	}
+
+	for key, expected := range cases {
+		if name := EnvVarName("TRELLIS_", key); name != expected {
+			t.Errorf("expected env var name for %s to be %q, got %q", key, expected, name)
+		}
+	}
+}
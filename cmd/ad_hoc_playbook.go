@@ -3,6 +3,9 @@ package cmd
 import (
 	"os"
 	"path/filepath"
+	"sort"
+
+	"github.com/roots/trellis-cli/command"
 )
 
 type AdHocPlaybook struct {
@@ -10,21 +13,37 @@ type AdHocPlaybook struct {
 	path  string
 }
 
-func (p *AdHocPlaybook) DumpFiles() func() {
-	for fileName, content := range p.files {
+// Writes the playbook's files through the runner (only printed during a dry run).
+func (p *AdHocPlaybook) DumpFiles(runner command.Runner) func() {
+	for _, fileName := range p.fileNames() {
 		destination := filepath.Join(p.path, fileName)
-		contentByte := []byte(content)
+		contentByte := []byte(p.files[fileName])
 
-		if err := os.WriteFile(destination, contentByte, 0644); err != nil {
+		if err := command.WriteFile(runner, destination, contentByte, 0644); err != nil {
 			panic("Could not write temporary file. This is probably a bug in trellis-cli; please open an issue to let us know.")
 		}
 	}
 
 	return func() {
+		if command.IsDryRun(runner) {
+			return
+		}
+
 		p.removeFiles()
 	}
 }
 
+func (p *AdHocPlaybook) fileNames() []string {
+	fileNames := make([]string, 0, len(p.files))
+
+	for fileName := range p.files {
+		fileNames = append(fileNames, fileName)
+	}
+
+	sort.Strings(fileNames)
+	return fileNames
+}
+
 func (p *AdHocPlaybook) removeFiles() {
 	for fileName := range p.files {
 		destination := filepath.Join(p.path, fileName)
@@ -108,7 +108,7 @@ func (c *AliasCommand) Run(args []string) int {
 	}
 	defer os.RemoveAll(tempDir)
 
-	defer c.aliasPlaybook.DumpFiles()()
+	defer c.aliasPlaybook.DumpFiles(c.Trellis.Runner)()
 
 	devInventory := findDevInventory(c.Trellis, c.UI)
 
@@ -138,7 +138,8 @@ func (c *AliasCommand) Run(args []string) int {
 		}
 
 		mockUi := cli.NewMockUi()
-		aliasPlaybook := command.WithOptions(
+		aliasPlaybook := command.New(
+			c.Trellis.Runner,
 			command.WithUiOutput(mockUi),
 		).Cmd("ansible-playbook", playbook.CmdArgs())
 
@@ -162,14 +163,14 @@ func (c *AliasCommand) Run(args []string) int {
 	}
 
 	combinedYmlPath := filepath.Join(tempDir, "/combined.yml")
-	writeFileErr := os.WriteFile(combinedYmlPath, []byte(combined), 0644)
+	writeFileErr := command.WriteFile(c.Trellis.Runner, combinedYmlPath, []byte(combined), 0644)
 	if writeFileErr != nil {
 		spinner.StopFail()
 		c.UI.Error(writeFileErr.Error())
 		return 1
 	}
 
-	defer c.aliasCopyPlaybook.DumpFiles()()
+	defer c.aliasCopyPlaybook.DumpFiles(c.Trellis.Runner)()
 
 	playbook := ansible.Playbook{
 		Name: "alias-copy.yml",
@@ -180,7 +181,8 @@ func (c *AliasCommand) Run(args []string) int {
 	}
 
 	mockUi := cli.NewMockUi()
-	aliasCopyPlaybook := command.WithOptions(
+	aliasCopyPlaybook := command.New(
+		c.Trellis.Runner,
 		command.WithUiOutput(mockUi),
 	).Cmd("ansible-playbook", playbook.CmdArgs())
 
@@ -5,6 +5,7 @@ import (
 	"strings"
 
 	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/command"
 	"github.com/roots/trellis-cli/trellis"
 )
 
@@ -63,7 +64,7 @@ func (c *CheckCommand) Run(args []string) int {
 			continue
 		}
 
-		result, err := checkRequirement(req)
+		result, err := checkRequirement(c.Trellis.Runner, req)
 		if err != nil {
 			c.UI.Error(err.Error())
 		}
@@ -82,7 +83,7 @@ func (c *CheckCommand) Run(args []string) int {
 			continue
 		}
 
-		result, err := checkRequirement(req)
+		result, err := checkRequirement(c.Trellis.Runner, req)
 		if err != nil {
 			c.UI.Error(err.Error())
 		}
@@ -117,8 +118,8 @@ Options:
 	return strings.TrimSpace(helpText)
 }
 
-func checkRequirement(req trellis.Requirement) (result trellis.RequirementResult, err error) {
-	result, err = req.Check()
+func checkRequirement(runner command.Runner, req trellis.Requirement) (result trellis.RequirementResult, err error) {
+	result, err = req.Check(runner)
 	if err != nil {
 		return result, fmt.Errorf("Error checking %s requirement: %v", req.Name, err)
 	}
@@ -1,30 +1,68 @@
 package cmd
 
 import (
-	"fmt"
 	"os"
-	"strings"
 	"testing"
 
 	"github.com/mitchellh/cli"
 	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/trellis"
 )
 
-func MockUiExec(t *testing.T, ui *cli.MockUi) func() {
+/*
+Replaces the project's Runner with a FakeRunner so commands are recorded
+instead of run. Each command line is written to the UI's output.
+*/
+func MockUiExec(ui *cli.MockUi, project *trellis.Trellis) *command.FakeRunner {
+	runner := &command.FakeRunner{Log: ui.OutputWriter}
+	project.Runner = runner
+
+	return runner
+}
+
+/*
+Adds a second development site (example2.com) to the fixture project and sets
+it up to use a running Lima VM. The VM is named after the main site
+(example.com). Must be called after trellis.LoadFixtureProject.
+*/
+func mockLimaProject(t *testing.T, ui *cli.MockUi, project *trellis.Trellis) *command.FakeRunner {
 	t.Helper()
+	t.Setenv("TRELLIS_BYPASS_LIMA_REQUIREMENTS", "1")
 
-	command.Mock(command.MockExecCommand(ui.OutputWriter, ui.ErrorWriter))
+	site := `
+  example2.com:
+    site_hosts:
+      - canonical: example2.test
+    local_path: ../site2
+`
 
-	return func() {
-		command.Restore()
+	sitesFile, err := os.OpenFile("group_vars/development/wordpress_sites.yml", os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sitesFile.WriteString(site)
+	sitesFile.Close()
+
+	if err := os.WriteFile("trellis.cli.yml", []byte("vm:\n  manager: lima\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.MkdirAll(".trellis/lima", 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile(".trellis/lima/inventory", nil, 0644); err != nil {
+		t.Fatal(err)
 	}
-}
 
-func TestHelperProcess(t *testing.T) {
-	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
-		return
+	runner := MockUiExec(ui, project)
+	runner.Stubs = []command.MockCommand{
+		{
+			Command: "limactl",
+			Args:    []string{"ls", "--format=json"},
+			Output:  `{"name":"example.com","status":"Running","dir":"/foo/example.com","vmType":"vz","arch":"aarch64","cpus":4,"memory":4294967296,"disk":107374182400,"sshLocalPort":60720}`,
+		},
 	}
 
-	fmt.Fprintf(os.Stdout, strings.Join(os.Args[3:], " "))
-	os.Exit(0)
+	return runner
 }
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func NewComposerCommand(ui cli.Ui, trellis *trellis.Trellis) *ComposerCommand {
+	c := &ComposerCommand{UI: ui, Trellis: trellis}
+	c.init()
+	return c
+}
+
+type ComposerCommand struct {
+	UI       cli.Ui
+	Trellis  *trellis.Trellis
+	flags    *flag.FlagSet
+	allSites bool
+	host     string
+	site     string
+}
+
+func (c *ComposerCommand) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Usage = func() { c.UI.Info(c.Help()) }
+	c.flags.BoolVar(&c.allSites, "all-sites", false, "Run the command for every site in the environment")
+	c.flags.StringVar(&c.host, "host", "", "Inventory host to connect to when an environment has multiple servers")
+	c.flags.StringVar(&c.site, "site", "", "Name of the site (ie: example.com); Optional when only single site exist in the environment")
+}
+
+func (c *ComposerCommand) Run(args []string) int {
+	if err := c.Trellis.LoadProject(); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	args, composerArgs, err := parseSiteCommandArgs(c.flags, args)
+	if err != nil {
+		return 1
+	}
+
+	commandArgumentValidator := &CommandArgumentValidator{required: 0, optional: 1}
+	if err := commandArgumentValidator.validate(args); err != nil {
+		c.UI.Error(err.Error())
+		c.UI.Output(c.Help())
+		return 1
+	}
+
+	environment := "development"
+	if len(args) == 1 {
+		environment = args[0]
+	}
+
+	if err := c.Trellis.ValidateEnvironment(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := c.Trellis.ApplyEnvironmentConfig(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if c.allSites && c.site != "" {
+		c.UI.Error("Error: --all-sites and --site can't be used together")
+		return 1
+	}
+
+	siteNames := c.Trellis.SiteNamesFromEnvironment(environment)
+
+	if !c.allSites {
+		siteName, err := c.Trellis.FindSiteNameFromEnvironment(environment, c.site)
+		if err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+
+		siteNames = []string{siteName}
+	}
+
+	code := 0
+
+	for _, siteName := range siteNames {
+		if c.allSites {
+			c.UI.Info(color.New(color.Bold).Sprintf("==> %s", siteName))
+		}
+
+		dir := fmt.Sprintf("/srv/www/%s/current", siteName)
+		composer := append([]string{"composer"}, composerArgs...)
+
+		err := runSiteCommand(c.Trellis, c.UI, environment, siteName, c.host, dir, composer, !c.allSites && isInteractiveTerminal())
+
+		if siteCode := siteCommandExitCode(c.UI, "composer", err); siteCode != 0 {
+			code = siteCode
+		}
+	}
+
+	return code
+}
+
+func (c *ComposerCommand) Synopsis() string {
+	return "Runs Composer commands for a site"
+}
+
+func (c *ComposerCommand) Help() string {
+	helpText := `
+Usage: trellis composer [options] [ENVIRONMENT=development] [-- COMPOSER_ARGS]
+
+Runs a Composer command in a site's directory (defaults to development environment).
+
+The command runs in the site's current release directory (/srv/www/<site>/current).
+Development runs Composer inside the VM (where the site's PHP version and extensions
+are installed). Other environments run it over SSH (as the web user).
+
+Composer's exit code is passed through.
+
+Any arguments after the -- separator are passed to Composer:
+
+  $ trellis composer -- require roots/acorn
+
+Run a command for a specific site:
+
+  $ trellis composer --site example.com -- update
+
+Install dependencies for every site in development:
+
+  $ trellis composer --all-sites -- install
+
+Arguments:
+  ENVIRONMENT   Name of environment (default: development)
+  COMPOSER_ARGS Arguments passed to Composer
+
+Options:
+      --all-sites  Run the command for every site in the environment
+      --host       Inventory host to connect to when an environment has multiple servers
+      --site       Name of the site (ie: example.com); Optional when only single site exist in the environment
+  -h, --help       show this help
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ComposerCommand) AutocompleteArgs() complete.Predictor {
+	return c.Trellis.PredictEnvironment(c.flags)
+}
+
+func (c *ComposerCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"--all-sites": complete.PredictNothing,
+		"--host":      complete.PredictNothing,
+		"--site":      complete.PredictNothing,
+	}
+}
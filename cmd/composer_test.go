@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func TestComposerRunValidations(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+
+	cases := []struct {
+		name            string
+		projectDetected bool
+		args            []string
+		out             string
+		code            int
+	}{
+		{
+			"no_project",
+			false,
+			nil,
+			"No Trellis project detected",
+			1,
+		},
+		{
+			"too_many_args",
+			true,
+			[]string{"production", "foo", "--", "install"},
+			"Error: too many arguments",
+			1,
+		},
+		{
+			"invalid_env",
+			true,
+			[]string{"foo"},
+			"Error: foo is not a valid environment",
+			1,
+		},
+		{
+			"invalid_site",
+			true,
+			[]string{"production", "--site", "nosite"},
+			"Error: nosite is not a valid site",
+			1,
+		},
+		{
+			"all_sites_and_site",
+			true,
+			[]string{"production", "--all-sites", "--site", "example.com"},
+			"Error: --all-sites and --site can't be used together",
+			1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			trellis := trellis.NewMockTrellis(tc.projectDetected)
+			composerCommand := NewComposerCommand(ui, trellis)
+
+			code := composerCommand.Run(tc.args)
+
+			if code != tc.code {
+				t.Errorf("expected code %d to be %d", code, tc.code)
+			}
+
+			combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+
+			if !strings.Contains(combined, tc.out) {
+				t.Errorf("expected output %q to contain %q", combined, tc.out)
+			}
+		})
+	}
+}
+
+func TestComposerRun(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+
+	cases := []struct {
+		name string
+		args []string
+		out  string
+	}{
+		{
+			"default",
+			[]string{"production", "--", "install", "--no-dev"},
+			"ssh web@1.2.3.4 cd '/srv/www/example.com/current' && 'composer' 'install' '--no-dev'",
+		},
+		{
+			"site",
+			[]string{"production", "--site=example.com", "--", "show"},
+			"'composer' 'show'",
+		},
+		{
+			"all_sites",
+			[]string{"--all-sites", "production", "--", "install"},
+			"==> example.com",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			project := trellis.NewTrellis()
+			MockUiExec(ui, project)
+
+			composerCommand := NewComposerCommand(ui, project)
+			code := composerCommand.Run(tc.args)
+
+			if code != 0 {
+				t.Errorf("expected code %d to be 0", code)
+			}
+
+			combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+
+			if !strings.Contains(combined, tc.out) {
+				t.Errorf("expected output %q to contain %q", combined, tc.out)
+			}
+		})
+	}
+}
+
+func TestComposerRunAllSitesDevelopmentVm(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+
+	ui := cli.NewMockUi()
+	project := trellis.NewTrellis()
+	runner := mockLimaProject(t, ui, project)
+
+	composerCommand := NewComposerCommand(ui, project)
+	code := composerCommand.Run([]string{"--all-sites", "development", "--", "install"})
+
+	if code != 0 {
+		t.Fatalf("expected code %d to be 0: %s", code, ui.ErrorWriter.String())
+	}
+
+	expected := []string{
+		"limactl shell --workdir /srv/www/example.com/current example.com composer install",
+		"limactl shell --workdir /srv/www/example2.com/current example.com composer install",
+	}
+
+	for _, command := range expected {
+		if !slices.Contains(runner.Commands(), command) {
+			t.Errorf("expected commands %q to contain %q", runner.Commands(), command)
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/roots/trellis-cli/cli_config"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+const cliConfigEnvPrefix = "TRELLIS_"
+
+/*
+Loads the project when run inside one since its CLI config files override the
+global config. Config commands also work outside of projects.
+*/
+func loadProjectIfDetected(trellis *trellis.Trellis) error {
+	wd, err := os.Getwd()
+	if err != nil {
+		return err
+	}
+
+	if _, ok := trellis.Detect(wd); !ok {
+		return nil
+	}
+
+	return trellis.LoadProject()
+}
+
+/*
+Returns where each setting is set: "file:<path>" for config files,
+"env:<name>" for env vars, or "default" (when it's not in the returned map).
+Settings from an environment's block in a config file take precedence over
+other config files when environment is set.
+*/
+func cliConfigOrigins(trellis *trellis.Trellis, environment string) (map[string]string, error) {
+	origins := make(map[string]string)
+	environmentPrefix := fmt.Sprintf("environments.%s.", environment)
+	environmentOrigins := make(map[string]string)
+
+	for _, path := range trellis.CliConfigPaths() {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		keys, err := cli_config.FileKeys(data)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, key := range keys {
+			origins[key] = "file:" + path
+
+			if environment != "" && strings.HasPrefix(key, environmentPrefix) {
+				environmentOrigins[strings.TrimPrefix(key, environmentPrefix)] = fmt.Sprintf("file:%s (environments.%s)", path, environment)
+			}
+		}
+	}
+
+	for key, origin := range environmentOrigins {
+		origins[key] = origin
+	}
+
+	for _, setting := range trellis.CliConfig.Settings() {
+		env := cli_config.EnvVarName(cliConfigEnvPrefix, setting.Key)
+
+		if _, ok := os.LookupEnv(env); env != "" && ok {
+			origins[setting.Key] = "env:" + env
+		}
+	}
+
+	return origins, nil
+}
@@ -0,0 +1,128 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+	"github.com/roots/trellis-cli/cli_config"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func NewConfigGetCommand(ui cli.Ui, trellis *trellis.Trellis) *ConfigGetCommand {
+	c := &ConfigGetCommand{UI: ui, Trellis: trellis}
+	c.init()
+	return c
+}
+
+type ConfigGetCommand struct {
+	UI      cli.Ui
+	Trellis *trellis.Trellis
+	flags   *flag.FlagSet
+}
+
+func (c *ConfigGetCommand) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Usage = func() { c.UI.Info(c.Help()) }
+}
+
+func (c *ConfigGetCommand) Run(args []string) int {
+	if err := loadProjectIfDetected(c.Trellis); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = c.flags.Args()
+
+	commandArgumentValidator := &CommandArgumentValidator{required: 1, optional: 0}
+	commandArgumentErr := commandArgumentValidator.validate(args)
+	if commandArgumentErr != nil {
+		c.UI.Error(commandArgumentErr.Error())
+		c.UI.Output(c.Help())
+		return 1
+	}
+
+	key := args[0]
+
+	if _, err := cli_config.KeyType(key); err != nil {
+		c.UI.Error(fmt.Sprintf("Error: %v", err))
+		return 1
+	}
+
+	section := []string{}
+
+	for _, setting := range c.Trellis.CliConfig.Settings() {
+		if setting.Key == key {
+			c.UI.Output(setting.Value)
+			return 0
+		}
+
+		if strings.HasPrefix(setting.Key, key+".") {
+			section = append(section, fmt.Sprintf("%s=%s", setting.Key, setting.Value))
+		}
+	}
+
+	if len(section) == 0 {
+		c.UI.Error(fmt.Sprintf("Error: `%s` is not set", key))
+		return 1
+	}
+
+	c.UI.Output(strings.Join(section, "\n"))
+
+	return 0
+}
+
+func (c *ConfigGetCommand) Synopsis() string {
+	return "Gets the resolved value of a CLI config setting"
+}
+
+func (c *ConfigGetCommand) Help() string {
+	helpText := `
+Usage: trellis config get [options] KEY
+
+Gets the resolved value of a CLI config setting.
+Nested settings are separated by dots. Getting a section lists all of its settings.
+
+Get the VM manager:
+
+  $ trellis config get vm.manager
+
+Get all open shortcuts:
+
+  $ trellis config get open
+
+Arguments:
+  KEY  Setting name (ie: vm.manager)
+
+Options:
+  -h, --help  show this help
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ConfigGetCommand) AutocompleteArgs() complete.Predictor {
+	return predictConfigKeys(c.Trellis)
+}
+
+func (c *ConfigGetCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{}
+}
+
+func predictConfigKeys(trellis *trellis.Trellis) complete.Predictor {
+	return complete.PredictFunc(func(args complete.Args) []string {
+		keys := []string{}
+
+		for _, setting := range trellis.CliConfig.Settings() {
+			keys = append(keys, setting.Key)
+		}
+
+		return keys
+	})
+}
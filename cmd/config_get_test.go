@@ -0,0 +1,70 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func TestConfigGetRun(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+
+	cases := []struct {
+		name string
+		args []string
+		out  string
+		code int
+	}{
+		{
+			"no_args",
+			nil,
+			"Error: missing arguments",
+			1,
+		},
+		{
+			"unknown_key",
+			[]string{"vm.cpu"},
+			"Error: unknown config key `vm.cpu`",
+			1,
+		},
+		{
+			"unset_map_key",
+			[]string{"open.nope"},
+			"Error: `open.nope` is not set",
+			1,
+		},
+		{
+			"value",
+			[]string{"vm.manager"},
+			"auto",
+			0,
+		},
+		{
+			"section",
+			[]string{"vm"},
+			"vm.hosts_resolver=hosts_file\n",
+			0,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			configGetCommand := NewConfigGetCommand(ui, trellis.NewTrellis())
+
+			code := configGetCommand.Run(tc.args)
+
+			if code != tc.code {
+				t.Errorf("expected code %d to be %d", code, tc.code)
+			}
+
+			combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+
+			if !strings.Contains(combined, tc.out) {
+				t.Errorf("expected output %q to contain %q", combined, tc.out)
+			}
+		})
+	}
+}
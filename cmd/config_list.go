@@ -0,0 +1,143 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func NewConfigListCommand(ui cli.Ui, trellis *trellis.Trellis) *ConfigListCommand {
+	c := &ConfigListCommand{UI: ui, Trellis: trellis}
+	c.init()
+	return c
+}
+
+type ConfigListCommand struct {
+	UI         cli.Ui
+	Trellis    *trellis.Trellis
+	flags      *flag.FlagSet
+	showOrigin bool
+	env        string
+}
+
+func (c *ConfigListCommand) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Usage = func() { c.UI.Info(c.Help()) }
+	c.flags.BoolVar(&c.showOrigin, "show-origin", false, "Show where each setting is set")
+	c.flags.StringVar(&c.env, "env", "", "Show the settings used for an environment")
+}
+
+func (c *ConfigListCommand) Run(args []string) int {
+	if err := loadProjectIfDetected(c.Trellis); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = c.flags.Args()
+
+	commandArgumentValidator := &CommandArgumentValidator{required: 0, optional: 0}
+	commandArgumentErr := commandArgumentValidator.validate(args)
+	if commandArgumentErr != nil {
+		c.UI.Error(commandArgumentErr.Error())
+		c.UI.Output(c.Help())
+		return 1
+	}
+
+	if c.env != "" {
+		if err := c.Trellis.LoadProject(); err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+
+		if err := c.Trellis.ValidateEnvironment(c.env); err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+
+		if err := c.Trellis.ApplyEnvironmentConfig(c.env); err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+	}
+
+	origins, err := cliConfigOrigins(c.Trellis, c.env)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	for _, setting := range c.Trellis.CliConfig.Settings() {
+		line := fmt.Sprintf("%s=%s", setting.Key, setting.Value)
+
+		if c.showOrigin {
+			origin, ok := origins[setting.Key]
+			if !ok {
+				origin = "default"
+			}
+
+			line = fmt.Sprintf("%s\t%s", origin, line)
+		}
+
+		c.UI.Output(line)
+	}
+
+	return 0
+}
+
+func (c *ConfigListCommand) Synopsis() string {
+	return "Lists the resolved CLI config settings"
+}
+
+func (c *ConfigListCommand) Help() string {
+	helpText := `
+Usage: trellis config list [options]
+
+Lists the resolved CLI config settings.
+
+Settings are loaded from (in order of precedence, lowest to highest):
+
+  1. defaults
+  2. global config file (eg: ~/.config/trellis/cli.yml)
+  3. project config files: .trellis/cli.yml, trellis.cli.yml, trellis.cli.local.yml
+  4. the environment's settings in the config files' environments block (with --env)
+  5. env vars (eg: TRELLIS_ASK_VAULT_PASS=true)
+
+List all settings:
+
+  $ trellis config list
+
+List all settings and where they're set:
+
+  $ trellis config list --show-origin
+
+List the settings used for commands run for the production environment:
+
+  $ trellis config list --env production
+
+Options:
+      --env          Show the settings used for an environment
+      --show-origin  Show where each setting is set
+  -h, --help         show this help
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ConfigListCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *ConfigListCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"--env":         predictEnvironment(c.Trellis),
+		"--show-origin": complete.PredictNothing,
+	}
+}
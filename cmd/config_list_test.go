@@ -0,0 +1,136 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func TestConfigListRunValidations(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+
+	ui := cli.NewMockUi()
+	configListCommand := NewConfigListCommand(ui, trellis.NewTrellis())
+
+	code := configListCommand.Run([]string{"foo"})
+
+	if code != 1 {
+		t.Errorf("expected code %d to be %d", code, 1)
+	}
+
+	combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+
+	if !strings.Contains(combined, "Error: too many arguments") {
+		t.Errorf("expected output %q to contain %q", combined, "Error: too many arguments")
+	}
+}
+
+func TestConfigListRunShowOrigin(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+
+	configDir := t.TempDir()
+	t.Setenv("TRELLIS_CONFIG_DIR", configDir)
+	t.Setenv("TRELLIS_ASK_VAULT_PASS", "true")
+
+	if err := os.WriteFile(filepath.Join(configDir, "cli.yml"), []byte("check_for_updates: false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := os.WriteFile("trellis.cli.yml", []byte("vm:\n  manager: lima\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	project := trellis.NewTrellis()
+	if err := project.LoadGlobalCliConfig(); err != nil {
+		t.Fatal(err)
+	}
+
+	ui := cli.NewMockUi()
+	configListCommand := NewConfigListCommand(ui, project)
+
+	code := configListCommand.Run([]string{"--show-origin"})
+
+	if code != 0 {
+		t.Fatalf("expected code %d to be %d: %s", code, 0, ui.ErrorWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+
+	expected := []string{
+		"env:TRELLIS_ASK_VAULT_PASS\task_vault_pass=true\n",
+		"file:" + filepath.Join(configDir, "cli.yml") + "\tcheck_for_updates=false\n",
+		"file:" + filepath.Join(project.Path, "trellis.cli.yml") + "\tvm.manager=lima\n",
+		"default\tvm.hosts_resolver=hosts_file\n",
+	}
+
+	for _, e := range expected {
+		if !strings.Contains(output, e) {
+			t.Errorf("expected output %q to contain %q", output, e)
+		}
+	}
+}
+
+func TestConfigListRunEnv(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+	t.Setenv("TRELLIS_CONFIG_DIR", t.TempDir())
+
+	projectConfigContents := `
+database_app: tableplus
+environments:
+  production:
+    database_app: sequel-ace
+`
+
+	if err := os.WriteFile("trellis.cli.yml", []byte(projectConfigContents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name string
+		args []string
+		out  string
+		code int
+	}{
+		{
+			"invalid_env",
+			[]string{"--env", "nope"},
+			"Error: nope is not a valid environment",
+			1,
+		},
+		{
+			"without_env",
+			[]string{"--show-origin"},
+			"trellis.cli.yml\tdatabase_app=tableplus\n",
+			0,
+		},
+		{
+			"env",
+			[]string{"--show-origin", "--env", "production"},
+			"trellis.cli.yml (environments.production)\tdatabase_app=sequel-ace\n",
+			0,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			configListCommand := NewConfigListCommand(ui, trellis.NewTrellis())
+
+			code := configListCommand.Run(tc.args)
+
+			if code != tc.code {
+				t.Errorf("expected code %d to be %d", code, tc.code)
+			}
+
+			combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+
+			if !strings.Contains(combined, tc.out) {
+				t.Errorf("expected output %q to contain %q", combined, tc.out)
+			}
+		})
+	}
+}
@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+	"github.com/roots/trellis-cli/cli_config"
+)
+
+func NewConfigSchemaCommand(ui cli.Ui) *ConfigSchemaCommand {
+	c := &ConfigSchemaCommand{UI: ui}
+	c.init()
+	return c
+}
+
+type ConfigSchemaCommand struct {
+	UI    cli.Ui
+	flags *flag.FlagSet
+}
+
+func (c *ConfigSchemaCommand) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Usage = func() { c.UI.Info(c.Help()) }
+}
+
+func (c *ConfigSchemaCommand) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = c.flags.Args()
+
+	commandArgumentValidator := &CommandArgumentValidator{required: 0, optional: 0}
+	commandArgumentErr := commandArgumentValidator.validate(args)
+	if commandArgumentErr != nil {
+		c.UI.Error(commandArgumentErr.Error())
+		c.UI.Output(c.Help())
+		return 1
+	}
+
+	schema, err := cli_config.Schema()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error generating schema: %v", err))
+		return 1
+	}
+
+	c.UI.Output(string(schema))
+
+	return 0
+}
+
+func (c *ConfigSchemaCommand) Synopsis() string {
+	return "Outputs a JSON Schema for CLI config files"
+}
+
+func (c *ConfigSchemaCommand) Help() string {
+	helpText := `
+Usage: trellis config schema [options]
+
+Outputs a JSON Schema for CLI config files (cli.yml, trellis.cli.yml, trellis.cli.local.yml).
+
+Editors with YAML language server support (eg: VS Code with the YAML extension) can use
+the schema to validate and autocomplete settings.
+
+Save the schema to a file:
+
+  $ trellis config schema > .trellis/cli.schema.json
+
+Then reference it at the top of trellis.cli.yml:
+
+  # yaml-language-server: $schema=.trellis/cli.schema.json
+
+Options:
+  -h, --help  show this help
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ConfigSchemaCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *ConfigSchemaCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{}
+}
@@ -0,0 +1,44 @@
+package cmd
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestConfigSchemaRun(t *testing.T) {
+	ui := cli.NewMockUi()
+	configSchemaCommand := NewConfigSchemaCommand(ui)
+
+	code := configSchemaCommand.Run([]string{})
+
+	if code != 0 {
+		t.Fatalf("expected code %d to be %d: %s", code, 0, ui.ErrorWriter.String())
+	}
+
+	var schema map[string]interface{}
+	if err := json.Unmarshal([]byte(ui.OutputWriter.String()), &schema); err != nil {
+		t.Fatalf("expected output to be a JSON schema: %v", err)
+	}
+
+	if schema["$schema"] != "https://json-schema.org/draft-07/schema#" {
+		t.Errorf("expected $schema to be draft-07, got %v", schema["$schema"])
+	}
+}
+
+func TestConfigSchemaRunValidations(t *testing.T) {
+	ui := cli.NewMockUi()
+	configSchemaCommand := NewConfigSchemaCommand(ui)
+
+	code := configSchemaCommand.Run([]string{"foo"})
+
+	if code != 1 {
+		t.Errorf("expected code %d to be %d", code, 1)
+	}
+
+	if !strings.Contains(ui.ErrorWriter.String(), "Error: too many arguments") {
+		t.Errorf("expected output %q to contain %q", ui.ErrorWriter.String(), "Error: too many arguments")
+	}
+}
@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+	"github.com/roots/trellis-cli/app_paths"
+	"github.com/roots/trellis-cli/cli_config"
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func NewConfigSetCommand(ui cli.Ui, trellis *trellis.Trellis) *ConfigSetCommand {
+	c := &ConfigSetCommand{UI: ui, Trellis: trellis}
+	c.init()
+	return c
+}
+
+type ConfigSetCommand struct {
+	UI      cli.Ui
+	Trellis *trellis.Trellis
+	flags   *flag.FlagSet
+	global  bool
+	project bool
+	local   bool
+}
+
+func (c *ConfigSetCommand) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Usage = func() { c.UI.Info(c.Help()) }
+	c.flags.BoolVar(&c.global, "global", false, "Write to the global config file")
+	c.flags.BoolVar(&c.project, "project", false, "Write to the project's trellis.cli.yml file")
+	c.flags.BoolVar(&c.local, "local", false, "Write to the project's trellis.cli.local.yml file")
+}
+
+func (c *ConfigSetCommand) Run(args []string) int {
+	if err := loadProjectIfDetected(c.Trellis); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = c.flags.Args()
+
+	commandArgumentValidator := &CommandArgumentValidator{required: 2, optional: 0}
+	commandArgumentErr := commandArgumentValidator.validate(args)
+	if commandArgumentErr != nil {
+		c.UI.Error(commandArgumentErr.Error())
+		c.UI.Output(c.Help())
+		return 1
+	}
+
+	path, err := c.configPath()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error: %v", err))
+		return 1
+	}
+
+	key := args[0]
+	value, err := cli_config.ParseValue(key, args[1])
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error: %v", err))
+		return 1
+	}
+
+	configYaml, err := os.ReadFile(path)
+	if err != nil && !os.IsNotExist(err) {
+		c.UI.Error(fmt.Sprintf("Error reading config file %s: %v", path, err))
+		return 1
+	}
+
+	newConfigYaml, err := cli_config.SetValue(configYaml, key, value)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error updating config file %s: %v", path, err))
+		return 1
+	}
+
+	// Validate the result with the same checks used when loading config files
+	candidate := c.Trellis.CliConfig.Clone()
+	if err := candidate.Load(newConfigYaml); err != nil {
+		c.UI.Error(fmt.Sprintf("Error: %v", err))
+		return 1
+	}
+
+	if !command.IsDryRun(c.Trellis.Runner) {
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			c.UI.Error(fmt.Sprintf("Error creating config directory: %v", err))
+			return 1
+		}
+	}
+
+	if err := command.WriteFile(c.Trellis.Runner, path, newConfigYaml, 0644); err != nil {
+		c.UI.Error(fmt.Sprintf("Error writing config file %s: %v", path, err))
+		return 1
+	}
+
+	c.UI.Info(fmt.Sprintf("Set %s = %s in %s", key, args[1], path))
+
+	origins, err := cliConfigOrigins(c.Trellis, "")
+	if err == nil {
+		if origin, ok := origins[key]; ok && origin != "file:"+path && c.overrides(origin, path) {
+			c.UI.Warn(fmt.Sprintf("Warning: `%s` is overridden by %s", key, origin))
+		}
+	}
+
+	return 0
+}
+
+// Whether an origin takes precedence over the config file at path.
+func (c *ConfigSetCommand) overrides(origin string, path string) bool {
+	if strings.HasPrefix(origin, "env:") {
+		return true
+	}
+
+	paths := c.Trellis.CliConfigPaths()
+	originIndex := -1
+	pathIndex := -1
+
+	for i, p := range paths {
+		if "file:"+p == origin {
+			originIndex = i
+		}
+
+		if p == path {
+			pathIndex = i
+		}
+	}
+
+	return originIndex > pathIndex
+}
+
+func (c *ConfigSetCommand) configPath() (string, error) {
+	selected := 0
+	for _, flag := range []bool{c.global, c.project, c.local} {
+		if flag {
+			selected++
+		}
+	}
+
+	if selected > 1 {
+		return "", fmt.Errorf("--global, --project and --local are mutually exclusive")
+	}
+
+	if c.global || (selected == 0 && c.Trellis.Path == "") {
+		return app_paths.ConfigPath("cli.yml"), nil
+	}
+
+	if c.Trellis.Path == "" {
+		return "", fmt.Errorf("--project and --local can only be used inside a Trellis project")
+	}
+
+	if c.local {
+		return filepath.Join(c.Trellis.Path, "trellis.cli.local.yml"), nil
+	}
+
+	return filepath.Join(c.Trellis.Path, "trellis.cli.yml"), nil
+}
+
+func (c *ConfigSetCommand) Synopsis() string {
+	return "Sets a CLI config setting"
+}
+
+func (c *ConfigSetCommand) Help() string {
+	helpText := `
+Usage: trellis config set [options] KEY VALUE
+
+Sets a CLI config setting in a config file. Existing comments in the file are preserved.
+Nested settings are separated by dots.
+
+By default, settings are written to the project's trellis.cli.yml file when run inside
+a Trellis project, or the global config file otherwise.
+
+Set the VM manager for the project:
+
+  $ trellis config set vm.manager lima
+
+Disable update checks globally:
+
+  $ trellis config set --global check_for_updates false
+
+Add an open shortcut only for yourself (trellis.cli.local.yml should be gitignored):
+
+  $ trellis config set --local open.sentry https://sentry.io
+
+Arguments:
+  KEY    Setting name (ie: vm.manager)
+  VALUE  Setting value
+
+Options:
+      --global   Write to the global config file (eg: ~/.config/trellis/cli.yml)
+      --project  Write to the project's trellis.cli.yml file
+      --local    Write to the project's trellis.cli.local.yml file
+  -h, --help     show this help
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ConfigSetCommand) AutocompleteArgs() complete.Predictor {
+	return predictConfigKeys(c.Trellis)
+}
+
+func (c *ConfigSetCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"--global":  complete.PredictNothing,
+		"--project": complete.PredictNothing,
+		"--local":   complete.PredictNothing,
+	}
+}
@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func TestConfigSetRunValidations(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+	t.Setenv("TRELLIS_CONFIG_DIR", t.TempDir())
+
+	cases := []struct {
+		name string
+		args []string
+		out  string
+	}{
+		{
+			"missing_args",
+			[]string{"vm.manager"},
+			"Error: missing arguments",
+		},
+		{
+			"exclusive_flags",
+			[]string{"--global", "--local", "vm.manager", "lima"},
+			"Error: --global, --project and --local are mutually exclusive",
+		},
+		{
+			"unknown_key",
+			[]string{"vm.cpu", "2"},
+			"Error: unknown config key `vm.cpu`",
+		},
+		{
+			"invalid_type",
+			[]string{"ask_vault_pass", "maybe"},
+			"Error: `ask_vault_pass` must be a boolean (true or false)",
+		},
+		{
+			"invalid_value",
+			[]string{"vm.manager", "docker"},
+			"Error: Invalid config file: unsupported value for `vm.manager`",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			configSetCommand := NewConfigSetCommand(ui, trellis.NewTrellis())
+
+			code := configSetCommand.Run(tc.args)
+
+			if code != 1 {
+				t.Errorf("expected code %d to be %d", code, 1)
+			}
+
+			combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+
+			if !strings.Contains(combined, tc.out) {
+				t.Errorf("expected output %q to contain %q", combined, tc.out)
+			}
+		})
+	}
+
+	if _, err := os.Stat("trellis.cli.yml"); !os.IsNotExist(err) {
+		t.Errorf("expected invalid settings not to be written")
+	}
+}
+
+func TestConfigSetRun(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+
+	configDir := t.TempDir()
+	t.Setenv("TRELLIS_CONFIG_DIR", configDir)
+
+	if err := os.WriteFile("trellis.cli.yml", []byte("# VM settings\nvm:\n  manager: auto # for now\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name    string
+		args    []string
+		path    func(projectPath string) string
+		content string
+	}{
+		{
+			"project_default",
+			[]string{"vm.manager", "lima"},
+			func(projectPath string) string { return filepath.Join(projectPath, "trellis.cli.yml") },
+			"# VM settings\nvm:\n  manager: lima # for now\n",
+		},
+		{
+			"local",
+			[]string{"--local", "open.sentry", "https://sentry.io"},
+			func(projectPath string) string { return filepath.Join(projectPath, "trellis.cli.local.yml") },
+			"open:\n  sentry: https://sentry.io\n",
+		},
+		{
+			"global",
+			[]string{"--global", "check_for_updates", "false"},
+			func(string) string { return filepath.Join(configDir, "cli.yml") },
+			"check_for_updates: false\n",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			project := trellis.NewTrellis()
+			configSetCommand := NewConfigSetCommand(ui, project)
+
+			code := configSetCommand.Run(tc.args)
+
+			if code != 0 {
+				t.Fatalf("expected code %d to be %d: %s", code, 0, ui.ErrorWriter.String())
+			}
+
+			path := tc.path(project.Path)
+			content, err := os.ReadFile(path)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(content) != tc.content {
+				t.Errorf("expected %s to be %q, got %q", path, tc.content, string(content))
+			}
+		})
+	}
+}
+
+func TestConfigSetDryRun(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+
+	ui := cli.NewMockUi()
+	project := trellis.NewTrellis()
+	project.Runner = command.NewDryRunner(ui.OutputWriter)
+	configSetCommand := NewConfigSetCommand(ui, project)
+
+	code := configSetCommand.Run([]string{"--local", "open.sentry", "https://sentry.io"})
+
+	if code != 0 {
+		t.Fatalf("expected code %d to be %d: %s", code, 0, ui.ErrorWriter.String())
+	}
+
+	path := filepath.Join(project.Path, "trellis.cli.local.yml")
+
+	if !strings.Contains(ui.OutputWriter.String(), "[dry-run] write "+path) {
+		t.Errorf("expected output %q to contain the planned write", ui.OutputWriter.String())
+	}
+
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Errorf("expected %s not to be written", path)
+	}
+}
+
+func TestConfigSetRunWarnsWhenOverridden(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+	t.Setenv("TRELLIS_CONFIG_DIR", t.TempDir())
+
+	if err := os.WriteFile("trellis.cli.local.yml", []byte("ask_vault_pass: false\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	ui := cli.NewMockUi()
+	configSetCommand := NewConfigSetCommand(ui, trellis.NewTrellis())
+
+	code := configSetCommand.Run([]string{"ask_vault_pass", "true"})
+
+	if code != 0 {
+		t.Fatalf("expected code %d to be %d: %s", code, 0, ui.ErrorWriter.String())
+	}
+
+	expected := "Warning: `ask_vault_pass` is overridden by file:"
+	if !strings.Contains(ui.ErrorWriter.String(), expected) {
+		t.Errorf("expected output %q to contain %q", ui.ErrorWriter.String(), expected)
+	}
+}
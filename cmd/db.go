@@ -0,0 +1,274 @@
+package cmd
+
+import (
+	"bufio"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/pkg/ansible"
+	"github.com/roots/trellis-cli/pkg/db_opener"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+/*
+Remote shell snippet which reads a MySQL option file from the first 4 lines of
+stdin into a private temporary file ($f). This keeps database credentials off
+of both the local and remote command lines. Anything after those lines is left
+on stdin for the command that follows (eg: an SQL dump to import).
+*/
+const mysqlOptionFileScript = `set -e; umask 077; f=$(mktemp); trap 'rm -f "$f"' EXIT; ` +
+	`for i in 1 2 3 4; do IFS= read -r line; printf '%s\n' "$line" >> "$f"; done; `
+
+func newDBCredentialsPlaybook(trellis *trellis.Trellis) *AdHocPlaybook {
+	return &AdHocPlaybook{
+		path: trellis.Path,
+		files: map[string]string{
+			"dump_db_credentials.yml": dumpDbCredentialsYml,
+			"db_credentials.json.j2":  dbCredentialsJsonJ2,
+		},
+	}
+}
+
+/*
+Fetches a site's database credentials (and the SSH connection used to reach it)
+by running the dump_db_credentials.yml ad-hoc playbook.
+
+host limits the playbook to a single inventory host (for multi-server environments).
+*/
+func fetchDBCredentials(trellis *trellis.Trellis, ui cli.Ui, adHocPlaybook *AdHocPlaybook, environment string, siteName string, host string) (credentials db_opener.DBCredentials, err error) {
+	dbCredentialsJson, err := os.CreateTemp("", "*.json")
+	if err != nil {
+		return credentials, fmt.Errorf("Error creating temporary db credentials JSON file: %s", err)
+	}
+	dbCredentialsJson.Close()
+	defer os.Remove(dbCredentialsJson.Name())
+
+	defer adHocPlaybook.DumpFiles(trellis.Runner)()
+
+	playbook := ansible.Playbook{
+		Name: "dump_db_credentials.yml",
+		Env:  environment,
+		ExtraVars: map[string]string{
+			"site": siteName,
+			"dest": dbCredentialsJson.Name(),
+		},
+	}
+
+	if environment == "development" {
+		playbook.SetInventory(findDevInventory(trellis, ui))
+	} else if host != "" {
+		playbook.AddArg("--limit", host)
+	}
+
+	mockUi := cli.NewMockUi()
+	dumpDbCredentials := command.New(
+		trellis.Runner,
+		command.WithUiOutput(mockUi),
+	).Cmd("ansible-playbook", playbook.CmdArgs())
+
+	if err := dumpDbCredentials.Run(); err != nil {
+		return credentials, fmt.Errorf("Error: could not get database credentials. Temporary playbook failed to execute:\n%s%s", mockUi.OutputWriter.String(), mockUi.ErrorWriter.String())
+	}
+
+	// The playbook didn't run so the credentials can only be placeholders
+	if command.IsDryRun(trellis.Runner) {
+		return dryRunDBCredentials(host), nil
+	}
+
+	dbCredentialsByte, err := os.ReadFile(dbCredentialsJson.Name())
+	if err != nil {
+		return credentials, fmt.Errorf("Error reading db credentials JSON file: %s", err)
+	}
+
+	if err := json.Unmarshal(dbCredentialsByte, &credentials); err != nil {
+		return credentials, fmt.Errorf("Error parsing db credentials JSON file: %s\nThis probably means the temporary playbook used to template out the JSON file with database credentials failed. Here was the output for troubleshooting:\n%s%s", err, mockUi.OutputWriter.String(), mockUi.ErrorWriter.String())
+	}
+
+	return credentials, nil
+}
+
+// Placeholder credentials for a dry run (since dump_db_credentials.yml never runs).
+func dryRunDBCredentials(host string) db_opener.DBCredentials {
+	if host == "" {
+		host = "<ansible_host>"
+	}
+
+	return db_opener.DBCredentials{
+		SSHUser:    "<web_user>",
+		SSHHost:    host,
+		SSHPort:    22,
+		DBUser:     "<db_user>",
+		DBPassword: "<db_password>",
+		DBHost:     "localhost",
+		DBPort:     3306,
+		DBName:     "<db_name>",
+		WPEnv:      "<wp_env>",
+	}
+}
+
+/*
+Selects the inventory host to fetch database credentials from.
+Returns an empty string when the environment has a single server.
+*/
+func dbInventoryHost(trellis *trellis.Trellis, environment string, siteName string, host string) (string, error) {
+	if environment == "development" {
+		return "", nil
+	}
+
+	targets := trellis.SshTargets(environment, siteName, "")
+	if len(targets) == 1 && host == "" {
+		return "", nil
+	}
+
+	target, err := selectSshTarget(targets, host)
+	if err != nil {
+		return "", err
+	}
+
+	return target.Name, nil
+}
+
+// Returns the ssh arguments to run remoteCommand on the database's server (as the web user).
+func dbSshArgs(environment string, c db_opener.DBCredentials, remoteCommand string) []string {
+	args := []string{"-C"}
+
+	if environment == "development" {
+		// Development VMs are re-created often; their host keys shouldn't be remembered
+		args = append(args, "-o", "StrictHostKeyChecking=no", "-o", "UserKnownHostsFile=/dev/null", "-o", "LogLevel=ERROR")
+	}
+
+	if c.SSHPort != 0 && c.SSHPort != 22 {
+		args = append(args, "-p", strconv.Itoa(c.SSHPort))
+	}
+
+	return append(args, fmt.Sprintf("%s@%s", c.SSHUser, c.SSHHost), remoteCommand)
+}
+
+// Quotes a string for use as a single argument in a POSIX shell.
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+/*
+Streams a mysqldump of the database over SSH into w.
+The dump is gzipped when compress is true.
+*/
+func exportDatabase(runner command.Runner, ui cli.Ui, environment string, c db_opener.DBCredentials, w io.Writer, compress bool) error {
+	remoteCommand := mysqlOptionFileScript + fmt.Sprintf(
+		`mysqldump --defaults-extra-file="$f" --single-transaction --quick --no-tablespaces %s`,
+		shellQuote(c.DBName),
+	)
+
+	var gzipWriter *gzip.Writer
+	if compress {
+		gzipWriter = gzip.NewWriter(w)
+		w = gzipWriter
+	}
+
+	dump := command.New(runner, command.WithLogging(ui)).Cmd("ssh", dbSshArgs(environment, c, remoteCommand))
+	dump.Stdin = strings.NewReader(c.OptionFile())
+	dump.Stdout = w
+	dump.Stderr = &command.UiErrorWriter{Ui: ui}
+
+	if err := dump.Run(); err != nil {
+		return fmt.Errorf("Error: could not export database %s: %v", c.DBName, err)
+	}
+
+	if gzipWriter != nil {
+		return gzipWriter.Close()
+	}
+
+	return nil
+}
+
+/*
+Streams an SQL dump from r into the database over SSH.
+Gzipped dumps are detected and decompressed automatically.
+*/
+func importDatabase(runner command.Runner, ui cli.Ui, environment string, c db_opener.DBCredentials, r io.Reader) error {
+	reader := bufio.NewReader(r)
+
+	if magic, _ := reader.Peek(2); len(magic) == 2 && magic[0] == 0x1f && magic[1] == 0x8b {
+		gzipReader, err := gzip.NewReader(reader)
+		if err != nil {
+			return fmt.Errorf("Error: could not decompress database dump: %v", err)
+		}
+		defer gzipReader.Close()
+
+		r = gzipReader
+	} else {
+		r = reader
+	}
+
+	remoteCommand := mysqlOptionFileScript + fmt.Sprintf(`mysql --defaults-extra-file="$f" %s`, shellQuote(c.DBName))
+
+	load := command.New(runner, command.WithLogging(ui)).Cmd("ssh", dbSshArgs(environment, c, remoteCommand))
+	load.Stdin = io.MultiReader(strings.NewReader(c.OptionFile()), r)
+	load.Stdout = &cli.UiWriter{Ui: ui}
+	load.Stderr = &command.UiErrorWriter{Ui: ui}
+
+	if err := load.Run(); err != nil {
+		return fmt.Errorf("Error: could not import database %s: %v", c.DBName, err)
+	}
+
+	return nil
+}
+
+/*
+Replaces URLs in the database with WP-CLI's search-replace command which
+correctly handles PHP serialized data.
+*/
+func searchReplaceDatabase(runner command.Runner, ui cli.Ui, environment string, c db_opener.DBCredentials, siteName string, site *trellis.Site, from string, to string) error {
+	if from == to {
+		return nil
+	}
+
+	args := []string{"wp", "search-replace", shellQuote(from), shellQuote(to), "--all-tables-with-prefix", "--skip-columns=guid", "--report-changed-only"}
+
+	if enabled, _ := site.Multisite["enabled"].(bool); enabled {
+		args = append(args, "--network", "--url="+shellQuote(from))
+	}
+
+	remoteCommand := fmt.Sprintf("cd /srv/www/%s/current && %s", siteName, strings.Join(args, " "))
+
+	searchReplace := command.New(
+		runner,
+		command.WithUiOutput(ui),
+		command.WithLogging(ui),
+	).Cmd("ssh", dbSshArgs(environment, c, remoteCommand))
+	searchReplace.Stdin = nil
+
+	if err := searchReplace.Run(); err != nil {
+		return fmt.Errorf("Error: could not replace %s with %s: %v", from, to, err)
+	}
+
+	return nil
+}
+
+/*
+Asks the user to confirm overwriting a production database by typing the
+environment name. Other environments don't require confirmation.
+*/
+func confirmDatabaseOverwrite(ui cli.Ui, environment string, siteName string) bool {
+	if environment != "production" {
+		return true
+	}
+
+	ui.Warn(fmt.Sprintf("The %s (%s) database will be overwritten. This can't be undone.\n", siteName, environment))
+
+	confirmation, err := ui.Ask(fmt.Sprintf("Type the environment name (%s) to confirm:", color.RedString(environment)))
+	if err != nil || confirmation != environment {
+		ui.Info("Aborted. Not overwriting database.")
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,176 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fatih/color"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func NewDBExportCommand(ui cli.Ui, trellis *trellis.Trellis) *DBExportCommand {
+	c := &DBExportCommand{UI: ui, Trellis: trellis, playbook: newDBCredentialsPlaybook(trellis)}
+	c.init()
+	return c
+}
+
+type DBExportCommand struct {
+	UI       cli.Ui
+	Trellis  *trellis.Trellis
+	flags    *flag.FlagSet
+	output   string
+	host     string
+	playbook *AdHocPlaybook
+}
+
+func (c *DBExportCommand) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Usage = func() { c.UI.Info(c.Help()) }
+	c.flags.StringVar(&c.output, "o", "", "File to export the database to")
+	c.flags.StringVar(&c.output, "output", "", "File to export the database to")
+	c.flags.StringVar(&c.host, "host", "", "Inventory host to connect to when an environment has multiple servers")
+}
+
+func (c *DBExportCommand) Run(args []string) int {
+	if err := c.Trellis.LoadProject(); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	c.Trellis.CheckVirtualenv(c.UI)
+
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = c.flags.Args()
+
+	commandArgumentValidator := &CommandArgumentValidator{required: 1, optional: 1}
+	if err := commandArgumentValidator.validate(args); err != nil {
+		c.UI.Error(err.Error())
+		c.UI.Output(c.Help())
+		return 1
+	}
+
+	environment := args[0]
+
+	if err := c.Trellis.ValidateEnvironment(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := c.Trellis.ApplyEnvironmentConfig(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	siteNameArg := c.flags.Arg(1)
+	siteName, siteNameErr := c.Trellis.FindSiteNameFromEnvironment(environment, siteNameArg)
+	if siteNameErr != nil {
+		c.UI.Error(siteNameErr.Error())
+		return 1
+	}
+
+	if c.output == "" {
+		c.output = fmt.Sprintf("%s-%s-%s.sql.gz", siteName, environment, time.Now().Format("20060102-150405"))
+	}
+
+	inventoryHost, err := dbInventoryHost(c.Trellis, environment, siteName, c.host)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	credentials, err := fetchDBCredentials(c.Trellis, c.UI, c.playbook, environment, siteName, inventoryHost)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	// Prints the export command and the file it would write without creating it
+	if command.IsDryRun(c.Trellis.Runner) {
+		if err := exportDatabase(c.Trellis.Runner, c.UI, environment, credentials, io.Discard, false); err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+
+		command.WriteFile(c.Trellis.Runner, c.output, nil, 0600)
+		return 0
+	}
+
+	file, err := os.OpenFile(c.output, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error: could not create %s: %v", c.output, err))
+		return 1
+	}
+
+	exportErr := exportDatabase(c.Trellis.Runner, c.UI, environment, credentials, file, strings.HasSuffix(c.output, ".gz"))
+	closeErr := file.Close()
+
+	if exportErr != nil || closeErr != nil {
+		os.Remove(c.output)
+
+		if exportErr == nil {
+			exportErr = closeErr
+		}
+
+		c.UI.Error(exportErr.Error())
+		return 1
+	}
+
+	c.UI.Info(color.GreenString(fmt.Sprintf("[✓] Exported %s (%s) database to %s", siteName, environment, c.output)))
+	return 0
+}
+
+func (c *DBExportCommand) Synopsis() string {
+	return "Exports a site's database to a local file"
+}
+
+func (c *DBExportCommand) Help() string {
+	helpText := `
+Usage: trellis db export [options] ENVIRONMENT [SITE]
+
+Exports a site's database to a local SQL file.
+
+The database is dumped with mysqldump on the server and streamed over SSH.
+Output files ending in '.gz' are gzipped.
+Defaults to a timestamped file in the current directory (eg: example.com-production-20240101-120000.sql.gz).
+
+Export the production database:
+
+  $ trellis db export production
+
+Export a site's production database to a specific file:
+
+  $ trellis db export -o backup.sql.gz production example.com
+
+Arguments:
+  ENVIRONMENT Name of environment (ie: production)
+  SITE        Name of the site (ie: example.com); Optional when only single site exist in the environment
+
+Options:
+      --host    Inventory host to connect to when an environment has multiple servers
+  -o, --output  File to export the database to
+  -h, --help    show this help
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DBExportCommand) AutocompleteArgs() complete.Predictor {
+	return c.Trellis.AutocompleteSite(c.flags)
+}
+
+func (c *DBExportCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"--host":   complete.PredictNothing,
+		"--output": complete.PredictFiles("*.sql*"),
+	}
+}
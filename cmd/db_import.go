@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func NewDBImportCommand(ui cli.Ui, trellis *trellis.Trellis) *DBImportCommand {
+	c := &DBImportCommand{UI: ui, Trellis: trellis, playbook: newDBCredentialsPlaybook(trellis)}
+	c.init()
+	return c
+}
+
+type DBImportCommand struct {
+	UI       cli.Ui
+	Trellis  *trellis.Trellis
+	flags    *flag.FlagSet
+	host     string
+	playbook *AdHocPlaybook
+}
+
+func (c *DBImportCommand) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Usage = func() { c.UI.Info(c.Help()) }
+	c.flags.StringVar(&c.host, "host", "", "Inventory host to connect to when an environment has multiple servers")
+}
+
+func (c *DBImportCommand) Run(args []string) int {
+	if err := c.Trellis.LoadProject(); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	c.Trellis.CheckVirtualenv(c.UI)
+
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = c.flags.Args()
+
+	commandArgumentValidator := &CommandArgumentValidator{required: 2, optional: 1}
+	if err := commandArgumentValidator.validate(args); err != nil {
+		c.UI.Error(err.Error())
+		c.UI.Output(c.Help())
+		return 1
+	}
+
+	environment := args[0]
+	path := args[1]
+
+	if err := c.Trellis.ValidateEnvironment(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := c.Trellis.ApplyEnvironmentConfig(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	siteNameArg := c.flags.Arg(2)
+	siteName, siteNameErr := c.Trellis.FindSiteNameFromEnvironment(environment, siteNameArg)
+	if siteNameErr != nil {
+		c.UI.Error(siteNameErr.Error())
+		return 1
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error: could not open %s: %v", path, err))
+		return 1
+	}
+	defer file.Close()
+
+	if !confirmDatabaseOverwrite(c.UI, environment, siteName) {
+		return 1
+	}
+
+	inventoryHost, err := dbInventoryHost(c.Trellis, environment, siteName, c.host)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	credentials, err := fetchDBCredentials(c.Trellis, c.UI, c.playbook, environment, siteName, inventoryHost)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := importDatabase(c.Trellis.Runner, c.UI, environment, credentials, file); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	c.UI.Info(color.GreenString(fmt.Sprintf("[✓] Imported %s into %s (%s) database", path, siteName, environment)))
+	return 0
+}
+
+func (c *DBImportCommand) Synopsis() string {
+	return "Imports a local SQL file into a site's database"
+}
+
+func (c *DBImportCommand) Help() string {
+	helpText := `
+Usage: trellis db import [options] ENVIRONMENT FILE [SITE]
+
+Imports a local SQL file into a site's database.
+
+The file is streamed over SSH into the mysql client on the server.
+Gzipped files (eg: from 'trellis db export') are decompressed automatically.
+
+Importing into production requires confirmation.
+
+Import a file into the development database:
+
+  $ trellis db import development backup.sql.gz
+
+Import a file into a site's staging database:
+
+  $ trellis db import staging backup.sql example.com
+
+Arguments:
+  ENVIRONMENT Name of environment (ie: production)
+  FILE        SQL file to import (optionally gzipped)
+  SITE        Name of the site (ie: example.com); Optional when only single site exist in the environment
+
+Options:
+      --host  Inventory host to connect to when an environment has multiple servers
+  -h, --help  show this help
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DBImportCommand) AutocompleteArgs() complete.Predictor {
+	return c.Trellis.PredictEnvironment(c.flags)
+}
+
+func (c *DBImportCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"--host": complete.PredictNothing,
+	}
+}
@@ -0,0 +1,97 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func TestDBImportRunValidations(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+
+	cases := []struct {
+		name            string
+		projectDetected bool
+		args            []string
+		out             string
+		code            int
+	}{
+		{
+			"no_project",
+			false,
+			nil,
+			"No Trellis project detected",
+			1,
+		},
+		{
+			"no_args",
+			true,
+			[]string{"production"},
+			"Error: missing arguments (expected between 2 and 3, got 1)",
+			1,
+		},
+		{
+			"invalid_env",
+			true,
+			[]string{"foo", "backup.sql"},
+			"Error: foo is not a valid environment",
+			1,
+		},
+		{
+			"missing_file",
+			true,
+			[]string{"production", "missing.sql"},
+			"Error: could not open missing.sql",
+			1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			trellis := trellis.NewMockTrellis(tc.projectDetected)
+			dbImportCommand := NewDBImportCommand(ui, trellis)
+
+			code := dbImportCommand.Run(tc.args)
+
+			if code != tc.code {
+				t.Errorf("expected code %d to be %d", code, tc.code)
+			}
+
+			combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+
+			if !strings.Contains(combined, tc.out) {
+				t.Errorf("expected output %q to contain %q", combined, tc.out)
+			}
+		})
+	}
+}
+
+func TestDBImportProductionRequiresConfirmation(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+
+	ui := cli.NewMockUi()
+	ui.InputReader = strings.NewReader("nope\n")
+
+	project := trellis.NewTrellis()
+	MockUiExec(ui, project)
+	dbImportCommand := NewDBImportCommand(ui, project)
+
+	code := dbImportCommand.Run([]string{"production", "group_vars/all/vault.yml"})
+
+	if code != 1 {
+		t.Errorf("expected code %d to be 1", code)
+	}
+
+	combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+
+	if !strings.Contains(combined, "Aborted. Not overwriting database.") {
+		t.Errorf("expected output %q to contain abort message", combined)
+	}
+
+	if strings.Contains(combined, "ansible-playbook") {
+		t.Errorf("expected no commands to run, got %q", combined)
+	}
+}
@@ -2,17 +2,13 @@ package cmd
 
 import (
 	_ "embed"
-	"encoding/json"
 	"flag"
 	"fmt"
-	"os"
 	"strings"
 
 	"github.com/fatih/color"
 	"github.com/mitchellh/cli"
 	"github.com/posener/complete"
-	"github.com/roots/trellis-cli/command"
-	"github.com/roots/trellis-cli/pkg/ansible"
 	"github.com/roots/trellis-cli/pkg/db_opener"
 	"github.com/roots/trellis-cli/trellis"
 )
@@ -24,15 +20,7 @@ var dumpDbCredentialsYml string
 var dbCredentialsJsonJ2 string
 
 func NewDBOpenCommand(ui cli.Ui, trellis *trellis.Trellis) *DBOpenCommand {
-	playbook := &AdHocPlaybook{
-		path: trellis.Path,
-		files: map[string]string{
-			"dump_db_credentials.yml": dumpDbCredentialsYml,
-			"db_credentials.json.j2":  dbCredentialsJsonJ2,
-		},
-	}
-
-	c := &DBOpenCommand{UI: ui, Trellis: trellis, dbOpenerFactory: &db_opener.Factory{}, playbook: playbook}
+	c := &DBOpenCommand{UI: ui, Trellis: trellis, dbOpenerFactory: &db_opener.Factory{Runner: trellis.Runner}, playbook: newDBCredentialsPlaybook(trellis)}
 	c.init()
 	return c
 }
@@ -41,6 +29,7 @@ type DBOpenCommand struct {
 	UI              cli.Ui
 	flags           *flag.FlagSet
 	app             string
+	host            string
 	Trellis         *trellis.Trellis
 	dbOpenerFactory *db_opener.Factory
 	playbook        *AdHocPlaybook
@@ -51,6 +40,7 @@ func (c *DBOpenCommand) init() {
 	c.flags.Usage = func() { c.UI.Info(c.Help()) }
 	appUsage := fmt.Sprintf("Database client to be used; Supported: %s", c.dbOpenerFactory.GetSupportedApps())
 	c.flags.StringVar(&c.app, "app", "", appUsage)
+	c.flags.StringVar(&c.host, "host", "", "Inventory host to connect to when an environment has multiple servers")
 }
 
 func (c *DBOpenCommand) Run(args []string) int {
@@ -85,6 +75,11 @@ func (c *DBOpenCommand) Run(args []string) int {
 		return 1
 	}
 
+	if err := c.Trellis.ApplyEnvironmentConfig(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
 	siteNameArg := c.flags.Arg(1)
 	siteName, siteNameErr := c.Trellis.FindSiteNameFromEnvironment(environment, siteNameArg)
 	if siteNameErr != nil {
@@ -104,54 +99,15 @@ func (c *DBOpenCommand) Run(args []string) int {
 		return 1
 	}
 
-	// Prepare JSON file for db credentials
-	dbCredentialsJson, dbCredentialsErr := os.CreateTemp("", "*.json")
-	if dbCredentialsErr != nil {
-		c.UI.Error(fmt.Sprintf("Error creating temporary db credentials JSON file: %s", dbCredentialsErr))
-	}
-	defer os.Remove(dbCredentialsJson.Name())
-
-	defer c.playbook.DumpFiles()()
-
-	// Template db credentials to JSON file.
-	playbook := ansible.Playbook{
-		Name: "dump_db_credentials.yml",
-		Env:  environment,
-		ExtraVars: map[string]string{
-			"site": siteName,
-			"dest": dbCredentialsJson.Name(),
-		},
-	}
-
-	if environment == "development" {
-		playbook.SetInventory(findDevInventory(c.Trellis, c.UI))
-	}
-
-	mockUi := cli.NewMockUi()
-	dumpDbCredentials := command.WithOptions(
-		command.WithUiOutput(mockUi),
-	).Cmd("ansible-playbook", playbook.CmdArgs())
-
-	if err := dumpDbCredentials.Run(); err != nil {
-		c.UI.Error("Error opening database. Temporary playbook failed to execute:")
-		c.UI.Error(mockUi.OutputWriter.String())
-		c.UI.Error(mockUi.ErrorWriter.String())
+	inventoryHost, err := dbInventoryHost(c.Trellis, environment, siteName, c.host)
+	if err != nil {
+		c.UI.Error(err.Error())
 		return 1
 	}
 
-	// Read db credentials from JSON file.
-	dbCredentialsByte, readErr := os.ReadFile(dbCredentialsJson.Name())
-	if readErr != nil {
-		c.UI.Error(fmt.Sprintf("Error reading db credentials JSON file: %s", readErr))
-		return 1
-	}
-	var dbCredentials db_opener.DBCredentials
-	unmarshalErr := json.Unmarshal(dbCredentialsByte, &dbCredentials)
-	if unmarshalErr != nil {
-		c.UI.Error(fmt.Sprintf("Error parsing db credentials JSON file: %s", unmarshalErr))
-		c.UI.Error("This probably means the temporary playbook used to template out the JSON file with database credentials failed. Here was the output for troubleshooting:")
-		c.UI.Error(mockUi.OutputWriter.String())
-		c.UI.Error(mockUi.ErrorWriter.String())
+	dbCredentials, err := fetchDBCredentials(c.Trellis, c.UI, c.playbook, environment, siteName, inventoryHost)
+	if err != nil {
+		c.UI.Error(err.Error())
 		return 1
 	}
 
@@ -183,6 +139,10 @@ Open a site's production database with Sequel Ace:
 
   $ trellis db open --app sequel-ace production example.com
 
+Open the production database of a specific server in a multi-server environment:
+
+  $ trellis db open --host web2 production
+
 To set a default database app, set the 'databae_app' option in your CLI (project or global) config file:
 
   database_app: sequel-ace
@@ -193,6 +153,7 @@ Arguments:
 
 Options:
       --app         Database client to be open with; Supported: %s
+      --host        Inventory host to connect to when an environment has multiple servers
   -h, --help        show this help
 `
 
@@ -205,6 +166,7 @@ func (c *DBOpenCommand) AutocompleteArgs() complete.Predictor {
 
 func (c *DBOpenCommand) AutocompleteFlags() complete.Flags {
 	return complete.Flags{
-		"--app": complete.PredictSet(c.dbOpenerFactory.GetSupportedApps()...),
+		"--app":  complete.PredictSet(c.dbOpenerFactory.GetSupportedApps()...),
+		"--host": complete.PredictNothing,
 	}
 }
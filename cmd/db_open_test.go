@@ -101,7 +101,7 @@ func TestDBOpenPlaybook(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			ui := cli.NewMockUi()
-			defer MockUiExec(t, ui)()
+			MockUiExec(ui, trellis)
 
 			dbOpenCommand := NewDBOpenCommand(ui, trellis)
 			dbOpenCommand.Run(tc.args)
@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/pkg/db_opener"
+	"github.com/roots/trellis-cli/pkg/vm"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+// Reads a MySQL option file from stdin into a private temporary file and prints its path.
+const mysqlOptionFileUploadScript = `umask 077; f=$(mktemp) && cat > "$f" && echo "$f"`
+
+func NewDBShellCommand(ui cli.Ui, trellis *trellis.Trellis) *DBShellCommand {
+	c := &DBShellCommand{UI: ui, Trellis: trellis, playbook: newDBCredentialsPlaybook(trellis)}
+	c.init()
+	return c
+}
+
+type DBShellCommand struct {
+	UI       cli.Ui
+	Trellis  *trellis.Trellis
+	flags    *flag.FlagSet
+	execute  string
+	host     string
+	playbook *AdHocPlaybook
+}
+
+func (c *DBShellCommand) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Usage = func() { c.UI.Info(c.Help()) }
+	c.flags.StringVar(&c.execute, "e", "", "Execute SQL statement(s), print tab-separated results, and exit")
+	c.flags.StringVar(&c.execute, "execute", "", "Execute SQL statement(s), print tab-separated results, and exit")
+	c.flags.StringVar(&c.host, "host", "", "Inventory host to connect to when an environment has multiple servers")
+}
+
+func (c *DBShellCommand) Run(args []string) int {
+	if err := c.Trellis.LoadProject(); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	c.Trellis.CheckVirtualenv(c.UI)
+
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = c.flags.Args()
+
+	commandArgumentValidator := &CommandArgumentValidator{required: 0, optional: 2}
+	if err := commandArgumentValidator.validate(args); err != nil {
+		c.UI.Error(err.Error())
+		c.UI.Output(c.Help())
+		return 1
+	}
+
+	environment := c.flags.Arg(0)
+
+	if environment == "" {
+		environment = "development"
+	}
+
+	if err := c.Trellis.ValidateEnvironment(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := c.Trellis.ApplyEnvironmentConfig(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	siteNameArg := c.flags.Arg(1)
+	siteName, siteNameErr := c.Trellis.FindSiteNameFromEnvironment(environment, siteNameArg)
+	if siteNameErr != nil {
+		c.UI.Error(siteNameErr.Error())
+		return 1
+	}
+
+	inventoryHost, err := dbInventoryHost(c.Trellis, environment, siteName, c.host)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	credentials, err := fetchDBCredentials(c.Trellis, c.UI, c.playbook, environment, siteName, inventoryHost)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if environment == "development" {
+		if manager, err := newVmManager(c.Trellis, c.UI); err == nil && findDevInventory(c.Trellis, c.UI) == manager.InventoryPath() {
+			if err := c.vmShell(manager, siteName, credentials); err != nil {
+				c.UI.Error(err.Error())
+				return 1
+			}
+
+			return 0
+		}
+		// Without a VM manager (eg: Vagrant), fall back to SSH
+	}
+
+	if err := c.sshShell(environment, credentials); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+func (c *DBShellCommand) Synopsis() string {
+	return "Opens a MySQL shell for a site's database"
+}
+
+func (c *DBShellCommand) Help() string {
+	helpText := `
+Usage: trellis db shell [options] [ENVIRONMENT=development] [SITE]
+
+Opens an interactive MySQL shell for a site's database (defaults to development environment).
+
+Development connects to the mysql client inside the VM. Other environments
+connect over SSH (as the web user). Database credentials are passed with a
+temporary MySQL option file and never appear on the command line.
+
+Open a shell for the development database:
+
+  $ trellis db shell
+
+Open a shell for a site's production database:
+
+  $ trellis db shell production example.com
+
+Run a one-off query (results are tab-separated):
+
+  $ trellis db shell -e "SELECT ID, post_title FROM wp_posts LIMIT 5" production
+
+Arguments:
+  ENVIRONMENT Name of environment (default: development)
+  SITE        Name of the site (ie: example.com); Optional when only single site exist in the environment
+
+Options:
+  -e, --execute  Execute SQL statement(s), print tab-separated results, and exit
+      --host     Inventory host to connect to when an environment has multiple servers
+  -h, --help     show this help
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DBShellCommand) AutocompleteArgs() complete.Predictor {
+	return c.Trellis.AutocompleteSite(c.flags)
+}
+
+func (c *DBShellCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"--execute": complete.PredictNothing,
+		"--host":    complete.PredictNothing,
+	}
+}
+
+func (c *DBShellCommand) mysqlArgs(optionFile string, dbName string) []string {
+	args := []string{"mysql", "--defaults-extra-file=" + optionFile}
+
+	if c.execute != "" {
+		args = append(args, "--batch", "--execute", c.execute)
+	}
+
+	return append(args, dbName)
+}
+
+/*
+Runs mysql inside the development VM (named after the main site) in the site's
+directory. Like sshShell, the option file is uploaded over stdin to a private
+temporary file in the VM (never the synced site directory) which is removed
+once mysql exits.
+*/
+func (c *DBShellCommand) vmShell(manager vm.Manager, siteName string, credentials db_opener.DBCredentials) error {
+	instanceName, err := vmInstanceName(c.Trellis)
+	if err != nil {
+		return err
+	}
+
+	upload, err := manager.ShellCmd(instanceName, "/", []string{"sh", "-c", mysqlOptionFileUploadScript})
+	if err != nil {
+		return fmt.Errorf("Error: could not create temporary MySQL option file: %s", err)
+	}
+
+	upload.Stdin = strings.NewReader(credentials.OptionFile())
+	upload.Stderr = &command.UiErrorWriter{Ui: c.UI}
+
+	output, err := upload.Output()
+	if err != nil {
+		return fmt.Errorf("Error: could not create temporary MySQL option file: %s", err)
+	}
+
+	vmDir := fmt.Sprintf("/srv/www/%s/current", siteName)
+	mysql := c.mysqlCommand(strings.TrimSpace(string(output)), credentials.DBName)
+
+	if err := manager.OpenShell(instanceName, vmDir, []string{"sh", "-c", mysql}); err != nil {
+		return fmt.Errorf("Error running mysql: %s", err)
+	}
+
+	return nil
+}
+
+// Returns a shell command which runs mysql and then removes the option file.
+func (c *DBShellCommand) mysqlCommand(optionFile string, dbName string) string {
+	mysqlArgs := []string{}
+	for _, arg := range c.mysqlArgs(optionFile, dbName) {
+		mysqlArgs = append(mysqlArgs, shellQuote(arg))
+	}
+
+	return fmt.Sprintf("%s; status=$?; rm -f %s; exit $status", strings.Join(mysqlArgs, " "), shellQuote(optionFile))
+}
+
+/*
+Runs mysql on the server over SSH. The option file is first uploaded over
+stdin to a private temporary file which is removed once mysql exits.
+*/
+func (c *DBShellCommand) sshShell(environment string, credentials db_opener.DBCredentials) error {
+	upload := command.New(c.Trellis.Runner).Cmd("ssh", dbSshArgs(environment, credentials, mysqlOptionFileUploadScript))
+	upload.Stdin = strings.NewReader(credentials.OptionFile())
+	upload.Stderr = &command.UiErrorWriter{Ui: c.UI}
+
+	output, err := upload.Output()
+	if err != nil {
+		return fmt.Errorf("Error: could not create temporary MySQL option file: %s", err)
+	}
+
+	remoteCommand := c.mysqlCommand(strings.TrimSpace(string(output)), credentials.DBName)
+	sshArgs := dbSshArgs(environment, credentials, remoteCommand)
+
+	if c.execute == "" {
+		sshArgs = append([]string{"-t"}, sshArgs...)
+	}
+
+	mysql := command.New(
+		c.Trellis.Runner,
+		command.WithTermOutput(),
+		command.WithLogging(c.UI),
+	).Cmd("ssh", sshArgs)
+
+	if err := mysql.Run(); err != nil {
+		return fmt.Errorf("Error running mysql: %s", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"io"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/pkg/db_opener"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func TestDBShellRunValidations(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+
+	cases := []struct {
+		name            string
+		projectDetected bool
+		args            []string
+		out             string
+		code            int
+	}{
+		{
+			"no_project",
+			false,
+			nil,
+			"No Trellis project detected",
+			1,
+		},
+		{
+			"too_many_args",
+			true,
+			[]string{"production", "example.com", "foo"},
+			"Error: too many arguments",
+			1,
+		},
+		{
+			"invalid_env",
+			true,
+			[]string{"foo"},
+			"Error: foo is not a valid environment",
+			1,
+		},
+		{
+			"invalid_site",
+			true,
+			[]string{"production", "nosite"},
+			"Error: nosite is not a valid site",
+			1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			trellis := trellis.NewMockTrellis(tc.projectDetected)
+			dbShellCommand := NewDBShellCommand(ui, trellis)
+
+			code := dbShellCommand.Run(tc.args)
+
+			if code != tc.code {
+				t.Errorf("expected code %d to be %d", code, tc.code)
+			}
+
+			combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+
+			if !strings.Contains(combined, tc.out) {
+				t.Errorf("expected output %q to contain %q", combined, tc.out)
+			}
+		})
+	}
+}
+
+func TestDBShellMysqlArgs(t *testing.T) {
+	ui := cli.NewMockUi()
+	dbShellCommand := NewDBShellCommand(ui, trellis.NewMockTrellis(true))
+
+	actual := dbShellCommand.mysqlArgs("/tmp/db.cnf", "example_com")
+	expected := []string{"mysql", "--defaults-extra-file=/tmp/db.cnf", "example_com"}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected args %q to be %q", actual, expected)
+	}
+
+	dbShellCommand.execute = "SELECT 1"
+
+	actual = dbShellCommand.mysqlArgs("/tmp/db.cnf", "example_com")
+	expected = []string{"mysql", "--defaults-extra-file=/tmp/db.cnf", "--batch", "--execute", "SELECT 1", "example_com"}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected args %q to be %q", actual, expected)
+	}
+}
+
+func TestDBShellVmShell(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+
+	ui := cli.NewMockUi()
+	project := trellis.NewTrellis()
+	runner := mockLimaProject(t, ui, project)
+
+	if err := project.LoadProject(); err != nil {
+		t.Fatal(err)
+	}
+
+	runner.Stubs = append(runner.Stubs, command.MockCommand{
+		Command: "limactl",
+		Args:    []string{"shell", "--workdir", "/", "example.com", "sh", "-c", mysqlOptionFileUploadScript},
+		Output:  "/tmp/tmp.abc123\n",
+	})
+
+	manager, err := newVmManager(project, ui)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dbShellCommand := NewDBShellCommand(ui, project)
+	credentials := db_opener.DBCredentials{DBUser: "example", DBPassword: "s3cret", DBName: "example2_development"}
+
+	if err := dbShellCommand.vmShell(manager, "example2.com", credentials); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "limactl shell --workdir /srv/www/example2.com/current example.com sh -c 'mysql' '--defaults-extra-file=/tmp/tmp.abc123' 'example2_development'; status=$?; rm -f '/tmp/tmp.abc123'; exit $status"
+
+	if !slices.Contains(runner.Commands(), expected) {
+		t.Errorf("expected commands %q to contain %q", runner.Commands(), expected)
+	}
+
+	uploaded := false
+
+	for _, cmd := range runner.Cmds() {
+		if slices.Contains(cmd.Args, mysqlOptionFileUploadScript) && cmd.Stdin != nil {
+			stdin, _ := io.ReadAll(cmd.Stdin)
+			uploaded = strings.Contains(string(stdin), `password="s3cret"`)
+		}
+	}
+
+	if !uploaded {
+		t.Error("expected the option file to be uploaded over stdin")
+	}
+
+	siteDir := project.SiteFromEnvironmentAndName("development", "example2.com").AbsLocalPath
+	if matches, _ := filepath.Glob(filepath.Join(siteDir, ".trellis-db-*")); len(matches) != 0 {
+		t.Errorf("expected no option file in the site directory, got %v", matches)
+	}
+}
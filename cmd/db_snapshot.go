@@ -0,0 +1,173 @@
+package cmd
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/pkg/db_opener"
+)
+
+const defaultDBSnapshotKeep = 5
+
+/*
+A database snapshot stored on the server in /srv/www/<site>/shared/db-snapshots.
+Snapshots are named '<timestamp>_<release>.sql.gz' where release is the deploy
+release that was current when the snapshot was created.
+*/
+type dbSnapshot struct {
+	Name    string
+	Release string
+	Size    int64
+	Created time.Time
+}
+
+func dbSnapshotsDir(siteName string) string {
+	return fmt.Sprintf("/srv/www/%s/shared/db-snapshots", siteName)
+}
+
+/*
+Dumps a site's database on the server to a snapshot tied to the current release,
+then prunes all but the newest keep snapshots.
+*/
+func createDBSnapshot(runner command.Runner, ui cli.Ui, environment string, c db_opener.DBCredentials, siteName string, keep int) error {
+	dir := dbSnapshotsDir(siteName)
+	name := time.Now().Format("20060102150405")
+
+	remoteCommand := mysqlOptionFileScript + fmt.Sprintf(
+		`current=/srv/www/%[1]s/current; `+
+			`if [ ! -e "$current" ]; then echo "No current release found; skipping database snapshot."; exit 0; fi; `+
+			`release=$(basename "$(readlink -f "$current")"); dir=%[2]s; mkdir -p "$dir"; `+
+			`snapshot="$dir/%[3]s_$release.sql"; `+
+			`mysqldump --defaults-extra-file="$f" --single-transaction --quick --no-tablespaces %[4]s > "$snapshot.tmp"; `+
+			`gzip -c "$snapshot.tmp" > "$snapshot.gz"; rm -f "$snapshot.tmp"; `+
+			`echo "Created database snapshot $snapshot.gz"; `+
+			`ls -1t "$dir"/*.sql.gz | tail -n +%[5]d | xargs -r rm -f`,
+		siteName,
+		shellQuote(dir),
+		name,
+		shellQuote(c.DBName),
+		keep+1,
+	)
+
+	snapshot := command.New(
+		runner,
+		command.WithUiOutput(ui),
+		command.WithLogging(ui),
+	).Cmd("ssh", dbSshArgs(environment, c, remoteCommand))
+	snapshot.Stdin = strings.NewReader(c.OptionFile())
+
+	if err := snapshot.Run(); err != nil {
+		return fmt.Errorf("Error: could not create database snapshot: %v", err)
+	}
+
+	return nil
+}
+
+// Returns a site's database snapshots on the server, newest first.
+func listDBSnapshots(runner command.Runner, environment string, c db_opener.DBCredentials, siteName string) ([]dbSnapshot, error) {
+	remoteCommand := fmt.Sprintf(
+		`dir=%s; [ -d "$dir" ] || exit 0; find "$dir" -maxdepth 1 -name '*.sql.gz' -printf '%%f\t%%s\t%%T@\n'`,
+		shellQuote(dbSnapshotsDir(siteName)),
+	)
+
+	list := command.New(runner).Cmd("ssh", dbSshArgs(environment, c, remoteCommand))
+	list.Stdin = nil
+
+	output, err := list.Output()
+	if err != nil {
+		return nil, fmt.Errorf("Error: could not list database snapshots: %v", err)
+	}
+
+	return parseDBSnapshots(string(output)), nil
+}
+
+/*
+Restores a database snapshot. If name is empty, the newest snapshot of the
+current release is restored.
+*/
+func restoreDBSnapshot(runner command.Runner, ui cli.Ui, environment string, c db_opener.DBCredentials, siteName string, name string) error {
+	snapshot := shellQuote(name)
+	notFound := shellQuote(fmt.Sprintf("Database snapshot %s not found", name))
+
+	if name == "" {
+		snapshot = `$(ls -1t "$dir"/*_"$release".sql.gz 2>/dev/null | head -n 1)`
+		notFound = `"No database snapshot found for release $release"`
+	}
+
+	// The snapshots dir doesn't exist until a snapshot is created
+	remoteCommand := mysqlOptionFileScript + fmt.Sprintf(
+		`dir=%s; release=$(basename "$(readlink -f /srv/www/%s/current)"); `+
+			`if [ ! -d "$dir" ]; then echo %s >&2; exit 1; fi; cd "$dir"; snapshot=%s; `+
+			`if [ -z "$snapshot" ] || [ ! -f "$snapshot" ]; then echo %s >&2; exit 1; fi; `+
+			`gzip -t "$snapshot"; gzip -dc "$snapshot" | mysql --defaults-extra-file="$f" %s; `+
+			`echo "Restored database snapshot $(basename "$snapshot")"`,
+		shellQuote(dbSnapshotsDir(siteName)),
+		siteName,
+		notFound,
+		snapshot,
+		notFound,
+		shellQuote(c.DBName),
+	)
+
+	restore := command.New(
+		runner,
+		command.WithUiOutput(ui),
+		command.WithLogging(ui),
+	).Cmd("ssh", dbSshArgs(environment, c, remoteCommand))
+	restore.Stdin = strings.NewReader(c.OptionFile())
+
+	if err := restore.Run(); err != nil {
+		return fmt.Errorf("Error: could not restore database snapshot: %v", err)
+	}
+
+	return nil
+}
+
+// Returns the newest of the snapshots (sorted newest first) tied to the release.
+func findReleaseDBSnapshot(snapshots []dbSnapshot, release string) (dbSnapshot, bool) {
+	for _, snapshot := range snapshots {
+		if snapshot.Release == release {
+			return snapshot, true
+		}
+	}
+
+	return dbSnapshot{}, false
+}
+
+// Parses lines of '<name>\t<size>\t<mtime>' (from find -printf), sorted newest first.
+func parseDBSnapshots(output string) []dbSnapshot {
+	snapshots := []dbSnapshot{}
+
+	for _, line := range strings.Split(strings.TrimSpace(output), "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 3 {
+			continue
+		}
+
+		size, _ := strconv.ParseInt(fields[1], 10, 64)
+		mtime, _ := strconv.ParseFloat(fields[2], 64)
+
+		release := ""
+		if _, after, found := strings.Cut(strings.TrimSuffix(fields[0], ".sql.gz"), "_"); found {
+			release = after
+		}
+
+		snapshots = append(snapshots, dbSnapshot{
+			Name:    fields[0],
+			Release: release,
+			Size:    size,
+			Created: time.Unix(int64(mtime), 0),
+		})
+	}
+
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].Created.After(snapshots[j].Created)
+	})
+
+	return snapshots
+}
@@ -0,0 +1,94 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/pkg/db_opener"
+)
+
+func TestParseDBSnapshots(t *testing.T) {
+	output := "20240101120000_20231201000000.sql.gz\t1048576\t1704110400.5\n" +
+		"20240201120000_20240101120000.sql.gz\t2048\t1706788800.0\n" +
+		"invalid line\n"
+
+	expected := []dbSnapshot{
+		{Name: "20240201120000_20240101120000.sql.gz", Release: "20240101120000", Size: 2048, Created: time.Unix(1706788800, 0)},
+		{Name: "20240101120000_20231201000000.sql.gz", Release: "20231201000000", Size: 1048576, Created: time.Unix(1704110400, 0)},
+	}
+
+	snapshots := parseDBSnapshots(output)
+
+	if !reflect.DeepEqual(snapshots, expected) {
+		t.Errorf("expected %v to equal %v", snapshots, expected)
+	}
+
+	if empty := parseDBSnapshots(""); len(empty) != 0 {
+		t.Errorf("expected no snapshots, got %v", empty)
+	}
+}
+
+func TestParseDBSnapshotsSortsNewestFirst(t *testing.T) {
+	output := "20240201120000_20240101120000.sql.gz\t10\t1706788800\n" +
+		"20240101120000_20231201000000.sql.gz\t10\t1704110400\n" +
+		"20240301120000_20240201120000.sql.gz\t10\t1709294400\n" +
+		"20240115120000_20240101120000.sql.gz\t10\t1705320000\n"
+
+	expected := []string{
+		"20240301120000_20240201120000.sql.gz",
+		"20240201120000_20240101120000.sql.gz",
+		"20240115120000_20240101120000.sql.gz",
+		"20240101120000_20231201000000.sql.gz",
+	}
+
+	names := []string{}
+	for _, snapshot := range parseDBSnapshots(output) {
+		names = append(names, snapshot.Name)
+	}
+
+	if !reflect.DeepEqual(names, expected) {
+		t.Errorf("expected %v to equal %v", names, expected)
+	}
+}
+
+func TestFindReleaseDBSnapshot(t *testing.T) {
+	snapshots := []dbSnapshot{
+		{Name: "20240301120000_20240201120000.sql.gz", Release: "20240201120000"},
+		{Name: "20240201120000_20240101120000.sql.gz", Release: "20240101120000"},
+		{Name: "20240115120000_20240101120000.sql.gz", Release: "20240101120000"},
+	}
+
+	snapshot, ok := findReleaseDBSnapshot(snapshots, "20240101120000")
+	if !ok || snapshot.Name != "20240201120000_20240101120000.sql.gz" {
+		t.Errorf("expected the newest snapshot of release 20240101120000, got %v", snapshot)
+	}
+
+	if _, ok := findReleaseDBSnapshot(snapshots, "20231201000000"); ok {
+		t.Error("expected no snapshot for release 20231201000000")
+	}
+}
+
+func TestRestoreDBSnapshotChecksDirBeforeChangingToIt(t *testing.T) {
+	runner := command.NewFakeRunner()
+	credentials := db_opener.DBCredentials{SSHUser: "web", SSHHost: "example.com", DBName: "example"}
+
+	if err := restoreDBSnapshot(runner, cli.NewMockUi(), "production", credentials, "example.com", ""); err != nil {
+		t.Fatal(err)
+	}
+
+	cmds := runner.Cmds()
+	if len(cmds) != 1 {
+		t.Fatalf("expected 1 command, got %d", len(cmds))
+	}
+
+	script := cmds[0].Args[len(cmds[0].Args)-1]
+	check := `if [ ! -d "$dir" ]; then echo "No database snapshot found for release $release" >&2; exit 1; fi; cd "$dir";`
+
+	if !strings.Contains(script, check) {
+		t.Errorf("expected script %q to contain %q", script, check)
+	}
+}
@@ -0,0 +1,180 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func NewDBSnapshotsCommand(ui cli.Ui, trellis *trellis.Trellis) *DBSnapshotsCommand {
+	c := &DBSnapshotsCommand{UI: ui, Trellis: trellis, playbook: newDBCredentialsPlaybook(trellis)}
+	c.init()
+	return c
+}
+
+type DBSnapshotsCommand struct {
+	UI       cli.Ui
+	Trellis  *trellis.Trellis
+	flags    *flag.FlagSet
+	host     string
+	restore  string
+	playbook *AdHocPlaybook
+}
+
+func (c *DBSnapshotsCommand) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Usage = func() { c.UI.Info(c.Help()) }
+	c.flags.StringVar(&c.host, "host", "", "Inventory host to connect to when an environment has multiple servers")
+	c.flags.StringVar(&c.restore, "restore", "", "Name of snapshot to restore")
+}
+
+func (c *DBSnapshotsCommand) Run(args []string) int {
+	if err := c.Trellis.LoadProject(); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	c.Trellis.CheckVirtualenv(c.UI)
+
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = c.flags.Args()
+
+	commandArgumentValidator := &CommandArgumentValidator{required: 1, optional: 1}
+	if err := commandArgumentValidator.validate(args); err != nil {
+		c.UI.Error(err.Error())
+		c.UI.Output(c.Help())
+		return 1
+	}
+
+	environment := args[0]
+
+	if err := c.Trellis.ValidateEnvironment(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := c.Trellis.ApplyEnvironmentConfig(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	siteNameArg := c.flags.Arg(1)
+	siteName, siteNameErr := c.Trellis.FindSiteNameFromEnvironment(environment, siteNameArg)
+	if siteNameErr != nil {
+		c.UI.Error(siteNameErr.Error())
+		return 1
+	}
+
+	if strings.Contains(c.restore, "/") {
+		c.UI.Error("Error: --restore must be a snapshot name (see the list of snapshots)")
+		return 1
+	}
+
+	if c.restore != "" && !confirmDatabaseOverwrite(c.UI, environment, siteName) {
+		return 1
+	}
+
+	inventoryHost, err := dbInventoryHost(c.Trellis, environment, siteName, c.host)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	credentials, err := fetchDBCredentials(c.Trellis, c.UI, c.playbook, environment, siteName, inventoryHost)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if c.restore != "" {
+		if err := restoreDBSnapshot(c.Trellis.Runner, c.UI, environment, credentials, siteName, c.restore); err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+
+		c.UI.Info(color.GreenString(fmt.Sprintf("[✓] Restored %s (%s) database from %s", siteName, environment, c.restore)))
+		return 0
+	}
+
+	snapshots, err := listDBSnapshots(c.Trellis.Runner, environment, credentials, siteName)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if len(snapshots) == 0 {
+		c.UI.Info(fmt.Sprintf("No database snapshots found for %s (%s).", siteName, environment))
+		return 0
+	}
+
+	var output strings.Builder
+	w := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tRELEASE\tCREATED\tSIZE")
+
+	for _, snapshot := range snapshots {
+		fmt.Fprintf(w, "%s\t%s\t%s\t%.2fMB\n", snapshot.Name, snapshot.Release, snapshot.Created.Format("2006-01-02 15:04:05"), float64(snapshot.Size)/1024/1024)
+	}
+
+	w.Flush()
+	c.UI.Output(strings.TrimSuffix(output.String(), "\n"))
+
+	return 0
+}
+
+func (c *DBSnapshotsCommand) Synopsis() string {
+	return "Lists and restores database snapshots created by deploys"
+}
+
+func (c *DBSnapshotsCommand) Help() string {
+	helpText := `
+Usage: trellis db snapshots [options] ENVIRONMENT [SITE]
+
+Lists (or restores) a site's database snapshots stored on the server.
+
+Snapshots are created by 'trellis deploy --db-snapshot' in /srv/www/<site>/shared/db-snapshots.
+Each snapshot is named after the time it was created and the release which was
+current at the time (ie: 20240102030405_20240101120000.sql.gz). Only the newest
+snapshots are kept (see 'trellis deploy --db-snapshot-keep').
+
+Restoring a production snapshot requires confirmation.
+
+List the production database snapshots:
+
+  $ trellis db snapshots production
+
+Restore a snapshot:
+
+  $ trellis db snapshots --restore=20240102030405_20240101120000.sql.gz production example.com
+
+Arguments:
+  ENVIRONMENT Name of environment (ie: production)
+  SITE        Name of the site (ie: example.com); Optional when only single site exist in the environment
+
+Options:
+      --host     Inventory host to connect to when an environment has multiple servers
+      --restore  Name of snapshot to restore
+  -h, --help     show this help
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DBSnapshotsCommand) AutocompleteArgs() complete.Predictor {
+	return c.Trellis.AutocompleteSite(c.flags)
+}
+
+func (c *DBSnapshotsCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"--host":    complete.PredictNothing,
+		"--restore": complete.PredictNothing,
+	}
+}
@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+	"github.com/roots/trellis-cli/pkg/db_opener"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func NewDBPullCommand(ui cli.Ui, trellis *trellis.Trellis) *DBSyncCommand {
+	c := &DBSyncCommand{UI: ui, Trellis: trellis, direction: "pull", playbook: newDBCredentialsPlaybook(trellis)}
+	c.init()
+	return c
+}
+
+func NewDBPushCommand(ui cli.Ui, trellis *trellis.Trellis) *DBSyncCommand {
+	c := &DBSyncCommand{UI: ui, Trellis: trellis, direction: "push", playbook: newDBCredentialsPlaybook(trellis)}
+	c.init()
+	return c
+}
+
+/*
+Copies a site's database from one environment to another (db pull and db push).
+Both directions work the same way; they only differ in their help text.
+*/
+type DBSyncCommand struct {
+	UI        cli.Ui
+	Trellis   *trellis.Trellis
+	flags     *flag.FlagSet
+	direction string
+	playbook  *AdHocPlaybook
+}
+
+func (c *DBSyncCommand) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Usage = func() { c.UI.Info(c.Help()) }
+}
+
+func (c *DBSyncCommand) Run(args []string) int {
+	if err := c.Trellis.LoadProject(); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	c.Trellis.CheckVirtualenv(c.UI)
+
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = c.flags.Args()
+
+	commandArgumentValidator := &CommandArgumentValidator{required: 2, optional: 1}
+	if err := commandArgumentValidator.validate(args); err != nil {
+		c.UI.Error(err.Error())
+		c.UI.Output(c.Help())
+		return 1
+	}
+
+	source := args[0]
+	target := args[1]
+
+	for _, environment := range []string{source, target} {
+		if err := c.Trellis.ValidateEnvironment(environment); err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+	}
+
+	if source == target {
+		c.UI.Error("Error: SOURCE and TARGET environments must be different")
+		return 1
+	}
+
+	siteNameArg := c.flags.Arg(2)
+	siteName, siteNameErr := c.Trellis.FindSiteNameFromEnvironment(source, siteNameArg)
+	if siteNameErr != nil {
+		c.UI.Error(siteNameErr.Error())
+		return 1
+	}
+
+	if _, err := c.Trellis.FindSiteNameFromEnvironment(target, siteName); err != nil {
+		c.UI.Error(fmt.Sprintf("Error: %s does not exist in the %s environment", siteName, target))
+		return 1
+	}
+
+	if !confirmDatabaseOverwrite(c.UI, target, siteName) {
+		return 1
+	}
+
+	sourceCredentials, err := c.credentials(source, siteName)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	targetCredentials, err := c.credentials(target, siteName)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	dump, err := os.CreateTemp("", "trellis-db-*.sql.gz")
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error creating temporary database dump file: %v", err))
+		return 1
+	}
+	defer os.Remove(dump.Name())
+	defer dump.Close()
+
+	// Each step runs with the CLI config of the environment it acts on
+	if err := c.Trellis.ApplyEnvironmentConfig(source); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	c.UI.Info(fmt.Sprintf("Exporting %s (%s) database...", siteName, source))
+
+	if err := exportDatabase(c.Trellis.Runner, c.UI, source, sourceCredentials, dump, true); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if _, err := dump.Seek(0, 0); err != nil {
+		c.UI.Error(fmt.Sprintf("Error reading temporary database dump file: %v", err))
+		return 1
+	}
+
+	if err := c.Trellis.ApplyEnvironmentConfig(target); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	c.UI.Info(fmt.Sprintf("Importing into %s (%s) database...", siteName, target))
+
+	if err := importDatabase(c.Trellis.Runner, c.UI, target, targetCredentials, dump); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	sourceUrl := c.Trellis.SiteFromEnvironmentAndName(source, siteName).MainUrl()
+	targetSite := c.Trellis.SiteFromEnvironmentAndName(target, siteName)
+	targetUrl := targetSite.MainUrl()
+
+	if sourceUrl != targetUrl {
+		c.UI.Info(fmt.Sprintf("Replacing %s with %s...", sourceUrl, targetUrl))
+
+		if err := searchReplaceDatabase(c.Trellis.Runner, c.UI, target, targetCredentials, siteName, targetSite, sourceUrl, targetUrl); err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+	}
+
+	c.UI.Info(color.GreenString(fmt.Sprintf("[✓] Copied %s database from %s to %s", siteName, source, target)))
+	return 0
+}
+
+func (c *DBSyncCommand) Synopsis() string {
+	if c.direction == "push" {
+		return "Pushes a site's database to another environment"
+	}
+
+	return "Pulls a site's database from another environment"
+}
+
+func (c *DBSyncCommand) Help() string {
+	helpText := `
+Usage: trellis db pull [options] SOURCE TARGET [SITE]
+
+Pulls a site's database from the SOURCE environment into the TARGET environment.
+
+The SOURCE database is exported with mysqldump and imported into the TARGET
+database over SSH. URLs are then replaced from SOURCE's site URL to TARGET's
+(eg: https://example.com => http://example.test) with WP-CLI's search-replace
+command which safely handles serialized data.
+
+Pulling into production requires confirmation.
+
+Pull the production database into development:
+
+  $ trellis db pull production development
+
+Pull a site's staging database into development:
+
+  $ trellis db pull staging development example.com
+
+Arguments:
+  SOURCE Name of environment to copy the database from (ie: production)
+  TARGET Name of environment to copy the database to (ie: development)
+  SITE   Name of the site (ie: example.com); Optional when only single site exist in the environment
+
+Options:
+  -h, --help  show this help
+`
+
+	if c.direction == "push" {
+		helpText = `
+Usage: trellis db push [options] SOURCE TARGET [SITE]
+
+Pushes a site's database from the SOURCE environment to the TARGET environment.
+
+The SOURCE database is exported with mysqldump and imported into the TARGET
+database over SSH. URLs are then replaced from SOURCE's site URL to TARGET's
+(eg: http://example.test => https://example.com) with WP-CLI's search-replace
+command which safely handles serialized data.
+
+Pushing to production requires confirmation.
+
+Push the development database to staging:
+
+  $ trellis db push development staging
+
+Push a site's development database to production:
+
+  $ trellis db push development production example.com
+
+Arguments:
+  SOURCE Name of environment to copy the database from (ie: development)
+  TARGET Name of environment to copy the database to (ie: staging)
+  SITE   Name of the site (ie: example.com); Optional when only single site exist in the environment
+
+Options:
+  -h, --help  show this help
+`
+	}
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DBSyncCommand) AutocompleteArgs() complete.Predictor {
+	return c.Trellis.PredictEnvironment(c.flags)
+}
+
+func (c *DBSyncCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{}
+}
+
+func (c *DBSyncCommand) credentials(environment string, siteName string) (db_opener.DBCredentials, error) {
+	if err := c.Trellis.ApplyEnvironmentConfig(environment); err != nil {
+		return db_opener.DBCredentials{}, err
+	}
+
+	inventoryHost, err := dbInventoryHost(c.Trellis, environment, siteName, "")
+	if err != nil {
+		return db_opener.DBCredentials{}, err
+	}
+
+	return fetchDBCredentials(c.Trellis, c.UI, c.playbook, environment, siteName, inventoryHost)
+}
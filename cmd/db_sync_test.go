@@ -0,0 +1,86 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func TestDBSyncRunValidations(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+
+	cases := []struct {
+		name            string
+		projectDetected bool
+		args            []string
+		out             string
+		code            int
+	}{
+		{
+			"no_project",
+			false,
+			nil,
+			"No Trellis project detected",
+			1,
+		},
+		{
+			"no_args",
+			true,
+			[]string{"production"},
+			"Error: missing arguments (expected between 2 and 3, got 1)",
+			1,
+		},
+		{
+			"invalid_source",
+			true,
+			[]string{"foo", "development"},
+			"Error: foo is not a valid environment",
+			1,
+		},
+		{
+			"invalid_target",
+			true,
+			[]string{"production", "foo"},
+			"Error: foo is not a valid environment",
+			1,
+		},
+		{
+			"same_environment",
+			true,
+			[]string{"production", "production"},
+			"Error: SOURCE and TARGET environments must be different",
+			1,
+		},
+		{
+			"invalid_site",
+			true,
+			[]string{"production", "development", "nosite"},
+			"Error: nosite is not a valid site",
+			1,
+		},
+	}
+
+	for _, tc := range cases {
+		for _, newCommand := range []func(cli.Ui, *trellis.Trellis) *DBSyncCommand{NewDBPullCommand, NewDBPushCommand} {
+			t.Run(tc.name, func(t *testing.T) {
+				ui := cli.NewMockUi()
+				trellis := trellis.NewMockTrellis(tc.projectDetected)
+				dbSyncCommand := newCommand(ui, trellis)
+
+				code := dbSyncCommand.Run(tc.args)
+
+				if code != tc.code {
+					t.Errorf("expected code %d to be %d", code, tc.code)
+				}
+
+				combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+
+				if !strings.Contains(combined, tc.out) {
+					t.Errorf("expected output %q to contain %q", combined, tc.out)
+				}
+			})
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package cmd
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/roots/trellis-cli/pkg/db_opener"
+)
+
+func TestShellQuote(t *testing.T) {
+	if actual := shellQuote("it's"); actual != `'it'\''s'` {
+		t.Errorf("expected %q to be quoted, got %q", "it's", actual)
+	}
+}
+
+func TestDbSshArgs(t *testing.T) {
+	credentials := db_opener.DBCredentials{SSHUser: "web", SSHHost: "1.2.3.4", SSHPort: 2222}
+
+	args := dbSshArgs("production", credentials, "mysql")
+	expected := []string{"-C", "-p", "2222", "web@1.2.3.4", "mysql"}
+
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected args %q to be %q", args, expected)
+	}
+}
@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/pkg/db_opener"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func NewDBTunnelCommand(ui cli.Ui, trellis *trellis.Trellis) *DBTunnelCommand {
+	c := &DBTunnelCommand{UI: ui, Trellis: trellis, playbook: newDBCredentialsPlaybook(trellis)}
+	c.init()
+	return c
+}
+
+type DBTunnelCommand struct {
+	UI       cli.Ui
+	Trellis  *trellis.Trellis
+	flags    *flag.FlagSet
+	host     string
+	port     int
+	playbook *AdHocPlaybook
+}
+
+func (c *DBTunnelCommand) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Usage = func() { c.UI.Info(c.Help()) }
+	c.flags.StringVar(&c.host, "host", "", "Inventory host to connect to when an environment has multiple servers")
+	c.flags.IntVar(&c.port, "p", 0, "Local port to listen on (default: a random free port)")
+	c.flags.IntVar(&c.port, "port", 0, "Local port to listen on (default: a random free port)")
+}
+
+func (c *DBTunnelCommand) Run(args []string) int {
+	if err := c.Trellis.LoadProject(); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	c.Trellis.CheckVirtualenv(c.UI)
+
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = c.flags.Args()
+
+	commandArgumentValidator := &CommandArgumentValidator{required: 1, optional: 1}
+	if err := commandArgumentValidator.validate(args); err != nil {
+		c.UI.Error(err.Error())
+		c.UI.Output(c.Help())
+		return 1
+	}
+
+	environment := args[0]
+
+	if err := c.Trellis.ValidateEnvironment(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := c.Trellis.ApplyEnvironmentConfig(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	siteNameArg := c.flags.Arg(1)
+	siteName, siteNameErr := c.Trellis.FindSiteNameFromEnvironment(environment, siteNameArg)
+	if siteNameErr != nil {
+		c.UI.Error(siteNameErr.Error())
+		return 1
+	}
+
+	inventoryHost, err := dbInventoryHost(c.Trellis, environment, siteName, c.host)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	credentials, err := fetchDBCredentials(c.Trellis, c.UI, c.playbook, environment, siteName, inventoryHost)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	tunnel, err := db_opener.OpenTunnel(c.Trellis.Runner, credentials, c.port)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+	defer tunnel.Close()
+
+	tunneled := tunnel.Credentials(credentials)
+
+	if command.IsDryRun(c.Trellis.Runner) {
+		return 0
+	}
+
+	c.UI.Info(color.GreenString(fmt.Sprintf("[✓] SSH tunnel to %s (%s) database open on 127.0.0.1:%d", siteName, environment, tunnel.LocalPort)))
+	c.UI.Info(fmt.Sprintf("\nDSN: %s\n", tunneled.DSN()))
+	c.UI.Info("Press Ctrl-C to close the tunnel.")
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	select {
+	case <-ctx.Done():
+		c.UI.Info("\nClosing tunnel.")
+	case err := <-tunnel.Done():
+		c.UI.Error(fmt.Sprintf("Error: SSH tunnel closed unexpectedly: %v", err))
+		return 1
+	}
+
+	return 0
+}
+
+func (c *DBTunnelCommand) Synopsis() string {
+	return "Opens an SSH tunnel to a site's database"
+}
+
+func (c *DBTunnelCommand) Help() string {
+	helpText := `
+Usage: trellis db tunnel [options] ENVIRONMENT [SITE]
+
+Opens an SSH tunnel (local port forward) to a site's database and prints
+a DSN to connect to it with any MySQL client.
+
+The tunnel stays open until you press Ctrl-C.
+
+Open a tunnel to the production database:
+
+  $ trellis db tunnel production
+
+Open a tunnel to a site's staging database on local port 3307:
+
+  $ trellis db tunnel --port 3307 staging example.com
+
+Arguments:
+  ENVIRONMENT Name of environment (ie: production)
+  SITE        Name of the site (ie: example.com); Optional when only single site exist in the environment
+
+Options:
+      --host  Inventory host to connect to when an environment has multiple servers
+  -p, --port  Local port to listen on (default: a random free port)
+  -h, --help  show this help
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DBTunnelCommand) AutocompleteArgs() complete.Predictor {
+	return c.Trellis.AutocompleteSite(c.flags)
+}
+
+func (c *DBTunnelCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"--host": complete.PredictNothing,
+		"--port": complete.PredictNothing,
+	}
+}
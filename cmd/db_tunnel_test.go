@@ -0,0 +1,98 @@
+package cmd
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func TestDBTunnelRunValidations(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+
+	cases := []struct {
+		name            string
+		projectDetected bool
+		args            []string
+		out             string
+		code            int
+	}{
+		{
+			"no_project",
+			false,
+			nil,
+			"No Trellis project detected",
+			1,
+		},
+		{
+			"no_args",
+			true,
+			nil,
+			"Error: missing arguments (expected between 1 and 2, got 0)",
+			1,
+		},
+		{
+			"too_many_args",
+			true,
+			[]string{"production", "example.com", "foo"},
+			"Error: too many arguments",
+			1,
+		},
+		{
+			"invalid_env",
+			true,
+			[]string{"foo"},
+			"Error: foo is not a valid environment",
+			1,
+		},
+		{
+			"invalid_site",
+			true,
+			[]string{"production", "nosite"},
+			"Error: nosite is not a valid site",
+			1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			trellis := trellis.NewMockTrellis(tc.projectDetected)
+			dbTunnelCommand := NewDBTunnelCommand(ui, trellis)
+
+			code := dbTunnelCommand.Run(tc.args)
+
+			if code != tc.code {
+				t.Errorf("expected code %d to be %d", code, tc.code)
+			}
+
+			combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+
+			if !strings.Contains(combined, tc.out) {
+				t.Errorf("expected output %q to contain %q", combined, tc.out)
+			}
+		})
+	}
+}
+
+func TestDBTunnelDryRun(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+	trellis := trellis.NewTrellis()
+
+	ui := cli.NewMockUi()
+	trellis.Runner = command.NewDryRunner(ui.OutputWriter)
+
+	dbTunnelCommand := NewDBTunnelCommand(ui, trellis)
+	code := dbTunnelCommand.Run([]string{"--port", "3307", "production"})
+
+	if code != 0 {
+		t.Errorf("expected code %d to be 0: %s", code, ui.ErrorWriter.String())
+	}
+
+	expected := "[dry-run] ssh -N -o ExitOnForwardFailure=yes -L 127.0.0.1:3307:"
+	if output := ui.OutputWriter.String(); !strings.Contains(output, expected) {
+		t.Errorf("expected output %q to contain %q", output, expected)
+	}
+}
@@ -2,28 +2,33 @@ package cmd
 
 import (
 	"flag"
+	"fmt"
 	"strings"
 
 	"github.com/mitchellh/cli"
 	"github.com/posener/complete"
 	"github.com/roots/trellis-cli/command"
 	"github.com/roots/trellis-cli/pkg/ansible"
+	"github.com/roots/trellis-cli/pkg/events"
 	"github.com/roots/trellis-cli/trellis"
 )
 
 func NewDeployCommand(ui cli.Ui, trellis *trellis.Trellis) *DeployCommand {
-	c := &DeployCommand{UI: ui, Trellis: trellis}
+	c := &DeployCommand{UI: ui, Trellis: trellis, dbPlaybook: newDBCredentialsPlaybook(trellis)}
 	c.init()
 	return c
 }
 
 type DeployCommand struct {
-	UI        cli.Ui
-	flags     *flag.FlagSet
-	branch    string
-	extraVars string
-	Trellis   *trellis.Trellis
-	verbose   bool
+	UI             cli.Ui
+	flags          *flag.FlagSet
+	branch         string
+	extraVars      string
+	dbSnapshot     bool
+	dbSnapshotKeep int
+	dbPlaybook     *AdHocPlaybook
+	Trellis        *trellis.Trellis
+	verbose        bool
 }
 
 func (c *DeployCommand) init() {
@@ -31,6 +36,8 @@ func (c *DeployCommand) init() {
 	c.flags.Usage = func() { c.UI.Info(c.Help()) }
 	c.flags.StringVar(&c.branch, "branch", "", "Optional git branch to deploy which overrides the branch set in your site config (default: master)")
 	c.flags.StringVar(&c.extraVars, "extra-vars", "", "Additional variables which are passed through to Ansible as 'extra-vars'")
+	c.flags.BoolVar(&c.dbSnapshot, "db-snapshot", false, "Create a snapshot of the site's database on the server before deploying")
+	c.flags.IntVar(&c.dbSnapshotKeep, "db-snapshot-keep", defaultDBSnapshotKeep, "Number of database snapshots to keep on the server")
 	c.flags.BoolVar(&c.verbose, "verbose", false, "Enable Ansible's verbose mode")
 }
 
@@ -63,6 +70,11 @@ func (c *DeployCommand) Run(args []string) int {
 		return 1
 	}
 
+	if err := c.Trellis.ApplyEnvironmentConfig(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
 	siteNameArg := c.flags.Arg(1)
 	siteName, siteNameErr := c.Trellis.FindSiteNameFromEnvironment(environment, siteNameArg)
 	if siteNameErr != nil {
@@ -109,13 +121,34 @@ func (c *DeployCommand) Run(args []string) int {
 		playbook.AddExtraVars(c.extraVars)
 	}
 
-	deploy := command.WithOptions(
+	if err := emitEvent(c.UI, c.Trellis, events.Event{Name: events.PreDeploy, Environment: environment, Site: siteName}); err != nil {
+		c.UI.Error(err.Error())
+		c.UI.Error("Aborting deploy since a pre-deploy hook failed.")
+		return 1
+	}
+
+	if c.dbSnapshot {
+		if err := c.createDBSnapshot(environment, siteName); err != nil {
+			c.UI.Error(err.Error())
+			c.UI.Error("Aborting deploy since the database snapshot could not be created.")
+			return 1
+		}
+	}
+
+	deploy := command.New(
+		c.Trellis.Runner,
 		command.WithUiOutput(c.UI),
 		command.WithLogging(c.UI),
 	).Cmd("ansible-playbook", playbook.CmdArgs())
 
-	if err := deploy.Run(); err != nil {
+	err := deploy.Run()
+	if err != nil {
 		c.UI.Error(err.Error())
+	}
+
+	emitEvent(c.UI, c.Trellis, events.Event{Name: events.PostDeploy, Environment: environment, Site: siteName, Status: eventStatus(err)})
+
+	if err != nil {
 		return 1
 	}
 
@@ -146,15 +179,21 @@ Deploy a site to staging with a different git branch:
 
   $ trellis deploy --branch=feature-123 production example.com
 
+Snapshot the site's database before deploying (see 'trellis db snapshots'):
+
+  $ trellis deploy --db-snapshot production
+
 Arguments:
   ENVIRONMENT Name of environment (ie: production)
   SITE        Name of the site (ie: example.com)
 
 Options:
-      --branch      Optional git branch to deploy which overrides the branch set in your site config (default: master)
-      --extra-vars  (multiple) set additional variables as key=value or YAML/JSON, if filename prepend with @
-      --verbose     Enable Ansible's verbose mode
-  -h, --help        show this help
+      --branch            Optional git branch to deploy which overrides the branch set in your site config (default: master)
+      --db-snapshot       Create a snapshot of the site's database on the server before deploying
+      --db-snapshot-keep  Number of database snapshots to keep on the server (default: 5)
+      --extra-vars        (multiple) set additional variables as key=value or YAML/JSON, if filename prepend with @
+      --verbose           Enable Ansible's verbose mode
+  -h, --help              show this help
 `
 
 	return strings.TrimSpace(helpText)
@@ -166,8 +205,34 @@ func (c *DeployCommand) AutocompleteArgs() complete.Predictor {
 
 func (c *DeployCommand) AutocompleteFlags() complete.Flags {
 	return complete.Flags{
-		"--branch":     complete.PredictNothing,
-		"--extra-vars": complete.PredictNothing,
-		"--verbose":    complete.PredictNothing,
+		"--branch":           complete.PredictNothing,
+		"--db-snapshot":      complete.PredictNothing,
+		"--db-snapshot-keep": complete.PredictNothing,
+		"--extra-vars":       complete.PredictNothing,
+		"--verbose":          complete.PredictNothing,
+	}
+}
+
+/*
+Snapshots the database before the new release is deployed. The snapshot is tied
+to the current release so 'trellis rollback --restore-db' can restore it.
+*/
+func (c *DeployCommand) createDBSnapshot(environment string, siteName string) error {
+	if c.dbSnapshotKeep < 1 {
+		return fmt.Errorf("Error: --db-snapshot-keep must be at least 1")
 	}
+
+	inventoryHost, err := dbInventoryHost(c.Trellis, environment, siteName, "")
+	if err != nil {
+		return err
+	}
+
+	credentials, err := fetchDBCredentials(c.Trellis, c.UI, c.dbPlaybook, environment, siteName, inventoryHost)
+	if err != nil {
+		return err
+	}
+
+	c.UI.Info(fmt.Sprintf("Creating %s (%s) database snapshot...", siteName, environment))
+
+	return createDBSnapshot(c.Trellis.Runner, c.UI, environment, credentials, siteName, c.dbSnapshotKeep)
 }
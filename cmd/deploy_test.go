@@ -1,10 +1,14 @@
 package cmd
 
 import (
+	"errors"
+	"reflect"
 	"strings"
 	"testing"
 
 	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/pkg/events"
 	"github.com/roots/trellis-cli/trellis"
 )
 
@@ -60,6 +64,13 @@ func TestDeployRunValidations(t *testing.T) {
 			"Error: too many arguments",
 			1,
 		},
+		{
+			"invalid_db_snapshot_keep",
+			true,
+			[]string{"--db-snapshot", "--db-snapshot-keep=0", "production"},
+			"Aborting deploy since the database snapshot could not be created",
+			1,
+		},
 	}
 
 	for _, tc := range cases {
@@ -128,7 +139,7 @@ func TestDeployRun(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			ui := cli.NewMockUi()
-			defer MockUiExec(t, ui)()
+			MockUiExec(ui, trellis)
 
 			deployCommand := NewDeployCommand(ui, trellis)
 			code := deployCommand.Run(tc.args)
@@ -146,6 +157,39 @@ func TestDeployRun(t *testing.T) {
 	}
 }
 
+func TestDeployDryRun(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+	trellis := trellis.NewTrellis()
+
+	ui := cli.NewMockUi()
+	trellis.Runner = command.NewDryRunner(ui.OutputWriter)
+
+	deployCommand := NewDeployCommand(ui, trellis)
+	code := deployCommand.Run([]string{"--db-snapshot", "--extra-vars", "vault_password=s3cret", "production"})
+
+	if code != 0 {
+		t.Errorf("expected code %d to be 0: %s", code, ui.ErrorWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+
+	expected := []string{
+		"[dry-run] ansible-playbook dump_db_credentials.yml -e dest=",
+		"[dry-run] ssh -C <web_user>@<ansible_host> set -e;",
+		"[dry-run] ansible-playbook deploy.yml -e vault_password=**** -e env=production -e site=example.com",
+	}
+
+	for _, line := range expected {
+		if !strings.Contains(output, line) {
+			t.Errorf("expected output %q to contain %q", output, line)
+		}
+	}
+
+	if strings.Contains(output, "s3cret") {
+		t.Errorf("expected output %q to mask secrets", output)
+	}
+}
+
 func TestDeployNotAllowedForDevelopment(t *testing.T) {
 	defer trellis.LoadFixtureProject(t)()
 
@@ -186,3 +230,69 @@ func TestDeployAllowedForDevelopmentWhenEnabled(t *testing.T) {
 		t.Errorf("expected output %q to NOT contain %q", combined, expected)
 	}
 }
+
+func TestDeployRunEvents(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+
+	cases := []struct {
+		name     string
+		preErr   error
+		out      string
+		code     int
+		expected []string
+	}{
+		{
+			"success",
+			nil,
+			"ansible-playbook deploy.yml",
+			0,
+			[]string{"pre-deploy production example.com ", "post-deploy production example.com success"},
+		},
+		{
+			"pre_deploy_hook_failure",
+			errors.New("Error: pre-deploy hook trellis-foo failed"),
+			"Aborting deploy since a pre-deploy hook failed.",
+			1,
+			[]string{"pre-deploy production example.com "},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			project := trellis.NewTrellis()
+			MockUiExec(ui, project)
+
+			emitted := []string{}
+
+			for _, name := range []string{events.PreDeploy, events.PostDeploy} {
+				project.Events.Subscribe(name, func(event events.Event, payload []byte) error {
+					emitted = append(emitted, strings.Join([]string{event.Name, event.Environment, event.Site, event.Status}, " "))
+
+					if event.IsPre() {
+						return tc.preErr
+					}
+
+					return nil
+				})
+			}
+
+			deployCommand := NewDeployCommand(ui, project)
+			code := deployCommand.Run([]string{"production"})
+
+			if code != tc.code {
+				t.Errorf("expected code %d to be %d", code, tc.code)
+			}
+
+			combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+
+			if !strings.Contains(combined, tc.out) {
+				t.Errorf("expected output %q to contain %q", combined, tc.out)
+			}
+
+			if !reflect.DeepEqual(emitted, tc.expected) {
+				t.Errorf("expected events %q to be %q", emitted, tc.expected)
+			}
+		})
+	}
+}
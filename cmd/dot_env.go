@@ -68,7 +68,12 @@ func (c *DotEnvCommand) Run(args []string) int {
 		return 1
 	}
 
-	defer c.playbook.DumpFiles()()
+	if err := c.Trellis.ApplyEnvironmentConfig(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	defer c.playbook.DumpFiles(c.Trellis.Runner)()
 
 	playbook := ansible.Playbook{
 		Name: "dotenv.yml",
@@ -80,7 +85,8 @@ func (c *DotEnvCommand) Run(args []string) int {
 	}
 
 	mockUi := cli.NewMockUi()
-	dotenv := command.WithOptions(
+	dotenv := command.New(
+		c.Trellis.Runner,
 		command.WithUiOutput(mockUi),
 	).Cmd("ansible-playbook", playbook.CmdArgs())
 
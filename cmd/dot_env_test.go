@@ -90,7 +90,7 @@ func TestDotEnvRun(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			ui := cli.NewMockUi()
-			defer MockUiExec(t, ui)()
+			MockUiExec(ui, trellis)
 
 			dotEnvCommand := NewDotEnvCommand(ui, trellis)
 			code := dotEnvCommand.Run(tc.args)
@@ -29,7 +29,8 @@ func (c *DownCommand) Run(args []string) int {
 		return 1
 	}
 
-	vagrantHalt := command.WithOptions(
+	vagrantHalt := command.New(
+		c.Trellis.Runner,
 		command.WithLogging(c.UI),
 		command.WithTermOutput(),
 	).Cmd("vagrant", []string{"halt"})
@@ -73,7 +73,7 @@ func TestDownRun(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			ui := cli.NewMockUi()
-			defer MockUiExec(t, ui)()
+			MockUiExec(ui, trellis)
 
 			downCommand := &DownCommand{ui, trellis}
 			code := downCommand.Run(tc.args)
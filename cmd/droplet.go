@@ -0,0 +1,125 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/digitalocean/godo"
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/digitalocean"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+const dropletSnapshotTimeout = 30 * time.Minute
+
+// Creates a DigitalOcean client which only prints API calls that would make changes during a dry run.
+func newDigitalOceanClient(trellis *trellis.Trellis, accessToken string) *digitalocean.Client {
+	if dryRunner, ok := trellis.Runner.(*command.DryRunner); ok {
+		return digitalocean.NewClient(accessToken, digitalocean.WithDryRun(dryRunner.Out))
+	}
+
+	return digitalocean.NewClient(accessToken)
+}
+
+// Finds the droplet whose public or reserved IP matches a host in the environment's hosts file.
+func findEnvironmentDroplet(ctx context.Context, doClient *digitalocean.Client, trellis *trellis.Trellis, environment string) (*godo.Droplet, error) {
+	hosts, err := trellis.EnvironmentHosts(environment)
+	if err != nil {
+		return nil, fmt.Errorf("Error: could not read hosts/%s\n%v", environment, err)
+	}
+
+	droplet, err := findHostsDroplet(ctx, doClient, hosts)
+	if err != nil {
+		return nil, err
+	}
+
+	if droplet == nil {
+		return nil, fmt.Errorf("Error: no droplet found matching the hosts in hosts/%s %v", environment, hosts)
+	}
+
+	return droplet, nil
+}
+
+// Returns the first droplet with one of the hosts as its IP, or nil if none match.
+func findHostsDroplet(ctx context.Context, doClient *digitalocean.Client, hosts []string) (*godo.Droplet, error) {
+	for _, host := range hosts {
+		droplet, err := doClient.GetDropletByIP(ctx, host)
+		if err != nil {
+			return nil, fmt.Errorf("Error: could not list droplets\n%v", err)
+		}
+
+		if droplet != nil {
+			return droplet, nil
+		}
+	}
+
+	return nil, nil
+}
+
+// Returns the droplet's public IP followed by any reserved IPs assigned to it.
+func dropletIPs(droplet godo.Droplet, reservedIPs []godo.ReservedIP) []string {
+	ips := []string{}
+
+	if ip, err := droplet.PublicIPv4(); err == nil && ip != "" {
+		ips = append(ips, ip)
+	}
+
+	for _, reservedIP := range dropletReservedIPs(droplet, reservedIPs) {
+		ips = append(ips, reservedIP.IP)
+	}
+
+	return ips
+}
+
+func dropletReservedIPs(droplet godo.Droplet, reservedIPs []godo.ReservedIP) []godo.ReservedIP {
+	assigned := []godo.ReservedIP{}
+
+	for _, reservedIP := range reservedIPs {
+		if reservedIP.Droplet != nil && reservedIP.Droplet.ID == droplet.ID {
+			assigned = append(assigned, reservedIP)
+		}
+	}
+
+	return assigned
+}
+
+func defaultSnapshotName(droplet *godo.Droplet) string {
+	return fmt.Sprintf("%s-%s", droplet.Name, time.Now().Format("20060102-150405"))
+}
+
+// Takes a snapshot of the droplet and waits for the action to complete.
+func snapshotDroplet(ctx context.Context, doClient *digitalocean.Client, droplet *godo.Droplet, name string) error {
+	_, monitorUri, err := doClient.CreateSnapshot(ctx, droplet, name)
+	if err != nil {
+		return fmt.Errorf("Error: could not create snapshot\n%v", err)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, dropletSnapshotTimeout)
+	defer cancel()
+
+	return waitForAction(ctx, doClient, monitorUri, SpinnerCfg{
+		Message:     fmt.Sprintf("Creating snapshot %s (this may take several minutes)", name),
+		StopMessage: fmt.Sprintf("Snapshot created: %s", name),
+		FailMessage: "Snapshot failed (or timed out)",
+	})
+}
+
+// Waits for a DigitalOcean action to complete while showing its progress in a spinner.
+func waitForAction(ctx context.Context, doClient *digitalocean.Client, monitorUri string, config SpinnerCfg) error {
+	s := NewSpinner(config)
+	s.Start()
+
+	err := doClient.WaitForAction(ctx, monitorUri, func(action *godo.Action, elapsed time.Duration) {
+		s.Message(fmt.Sprintf("%s [%s, %s]", config.Message, action.Status, elapsed.Round(time.Second)))
+	})
+
+	if err != nil {
+		s.StopFail()
+		return err
+	}
+
+	s.Stop()
+
+	return nil
+}
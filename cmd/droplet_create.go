@@ -5,14 +5,15 @@ import (
 	"errors"
 	"flag"
 	"fmt"
+	"os"
 	"strings"
 	"time"
 
 	"github.com/digitalocean/godo"
-	"github.com/digitalocean/godo/util"
 	"github.com/fatih/color"
 	"github.com/manifoldco/promptui"
 	"github.com/mitchellh/cli"
+	"github.com/mitchellh/go-homedir"
 	"github.com/posener/complete"
 	"github.com/roots/trellis-cli/digitalocean"
 	"github.com/roots/trellis-cli/trellis"
@@ -37,6 +38,12 @@ type DropletCreateCommand struct {
 	image         string
 	size          string
 	skipProvision bool
+	backups       bool
+	monitoring    bool
+	vpcUUID       string
+	firewall      bool
+	reservedIP    bool
+	userData      string
 }
 
 func (c *DropletCreateCommand) init() {
@@ -47,6 +54,12 @@ func (c *DropletCreateCommand) init() {
 	c.flags.StringVar(&c.image, "image", "ubuntu-20-04-x64", "Server image")
 	c.flags.StringVar(&c.size, "size", "", "Server size/type to create")
 	c.flags.BoolVar(&c.skipProvision, "skip-provision", false, "Create the server but skip provisioning")
+	c.flags.BoolVar(&c.backups, "backups", false, "Enable automated backups")
+	c.flags.BoolVar(&c.monitoring, "monitoring", false, "Enable the DigitalOcean monitoring agent")
+	c.flags.StringVar(&c.vpcUUID, "vpc-uuid", "", "UUID of the VPC to create the server in")
+	c.flags.BoolVar(&c.firewall, "firewall", false, "Create a Cloud Firewall which only allows SSH, HTTP and HTTPS traffic")
+	c.flags.BoolVar(&c.reservedIP, "reserved-ip", false, "Create a reserved IP and assign it to the server")
+	c.flags.StringVar(&c.userData, "user-data", "", "Path to a cloud-init user data file")
 }
 
 func (c *DropletCreateCommand) Run(args []string) int {
@@ -62,6 +75,7 @@ func (c *DropletCreateCommand) Run(args []string) int {
 	}
 
 	args = c.flags.Args()
+	c.applyConfigDefaults()
 
 	commandArgumentValidator := &CommandArgumentValidator{required: 1, optional: 0}
 	commandArgumentErr := commandArgumentValidator.validate(args)
@@ -79,6 +93,11 @@ func (c *DropletCreateCommand) Run(args []string) int {
 		return 1
 	}
 
+	if err := c.Trellis.ApplyEnvironmentConfig(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
 	if environment == "development" {
 		c.UI.Error("create command only supports staging/production environments")
 		return 1
@@ -90,7 +109,10 @@ func (c *DropletCreateCommand) Run(args []string) int {
 		return 1
 	}
 
-	c.doClient = digitalocean.NewClient(accessToken)
+	c.doClient = newDigitalOceanClient(c.Trellis, accessToken)
+
+	ctx, stop := interruptContext()
+	defer stop()
 
 	sshKeys := defaultSshKeys
 	if c.sshKey != "" {
@@ -99,7 +121,7 @@ func (c *DropletCreateCommand) Run(args []string) int {
 
 	sshKeyPath, contents, publicKey, err := digitalocean.LoadSSHKey(sshKeys)
 	if err == nil {
-		err = c.checkSSHKey(sshKeyPath, contents, publicKey)
+		err = c.checkSSHKey(ctx, sshKeyPath, contents, publicKey)
 	}
 
 	if err != nil {
@@ -114,7 +136,7 @@ func (c *DropletCreateCommand) Run(args []string) int {
 	var region *godo.Region
 
 	if c.region == "" {
-		region, err = c.selectRegion()
+		region, err = c.selectRegion(ctx)
 
 		if err != nil {
 			c.UI.Error(err.Error())
@@ -125,7 +147,7 @@ func (c *DropletCreateCommand) Run(args []string) int {
 	}
 
 	if c.size == "" {
-		c.size, err = c.selectSize(region)
+		c.size, err = c.selectSize(ctx, region)
 
 		if err != nil {
 			c.UI.Error(err.Error())
@@ -133,23 +155,69 @@ func (c *DropletCreateCommand) Run(args []string) int {
 		}
 	}
 
+	userData := ""
+	if c.userData != "" {
+		userData, err = readUserData(c.userData)
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error reading user data file: %s", err))
+			return 1
+		}
+	}
+
 	siteNames := c.Trellis.SiteNamesFromEnvironment(environment)
 	name, err := c.askDropletName(siteNames[0])
 	if err != nil {
 		return 1
 	}
 
-	droplet, err := c.createDroplet(c.region, c.size, c.image, publicKey, name, environment)
+	droplet, err := c.createDroplet(ctx, digitalocean.DropletOptions{
+		Name:       name,
+		Env:        environment,
+		Region:     c.region,
+		Size:       c.size,
+		Image:      c.image,
+		PublicKey:  publicKey,
+		Backups:    c.backups,
+		Monitoring: c.monitoring,
+		VpcUUID:    c.vpcUUID,
+		UserData:   userData,
+	})
 	if err != nil {
 		return 1
 	}
 
-	droplet, err = c.waitForSSH(droplet)
+	if c.doClient.DryRun() {
+		c.UI.Info("\nDry run: skipping the firewall, reserved IP, hosts file update and provision since the droplet wasn't created.")
+		return 0
+	}
+
+	if c.firewall {
+		firewallName := fmt.Sprintf("%s-%s", strings.ReplaceAll(name, "_", "-"), environment)
+		if _, err := c.doClient.CreateFirewall(ctx, firewallName, droplet); err != nil {
+			c.UI.Error(fmt.Sprintf("Error creating firewall: %s", err))
+			return 1
+		}
+
+		c.UI.Info(fmt.Sprintf("%s Firewall created: %s (allows ports %s)", color.GreenString("[✓]"), firewallName, "22, 80, 443"))
+	}
+
+	droplet, ip, err := c.doClient.GetDroplet(ctx, droplet)
 	if err != nil {
+		c.UI.Error(err.Error())
 		return 1
 	}
 
-	ip, _ := droplet.PublicIPv4()
+	if c.reservedIP {
+		ip, err = c.assignReservedIP(ctx, droplet)
+		if err != nil {
+			return 1
+		}
+	}
+
+	if err = c.waitForSSH(ctx, ip); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
 
 	_, err = c.Trellis.UpdateHosts(environment, ip)
 	if err != nil {
@@ -206,15 +274,34 @@ Create a server but skip provisioning:
 
   $ trellis droplet create --skip-provision production
 
+Create a hardened production server with backups, monitoring, a firewall and a reserved IP:
+
+  $ trellis droplet create --backups --monitoring --firewall --reserved-ip production
+
+Defaults for these options can be set in the 'droplet' section of your CLI config:
+
+  droplet:
+    region: nyc3
+    size: s-1vcpu-1gb
+    backups: true
+    firewall: true
+    reserved_ip: true
+
 Arguments:
   ENVIRONMENT Name of environment (ie: production)
 
 Options:
-      --region          Region to create the server in
+      --backups         Enable automated backups
+      --firewall        Create a Cloud Firewall which only allows SSH (22), HTTP (80) and HTTPS (443)
       --image           (default: ubuntu-20-04-x64) Server image (ie: Linux distribution)
+      --monitoring      Enable the DigitalOcean monitoring agent
+      --region          Region to create the server in
+      --reserved-ip     Create a reserved IP, assign it to the server, and use it in the hosts file
       --size            Server size/type
       --skip-provision  Skip provision after server is created
       --ssh-key         (default: ~/.ssh/id_rsa.pub or ~/.ssh/id_ed25519.pub) path to SSH public key to be added on the server
+      --user-data       Path to a cloud-init user data file
+      --vpc-uuid        UUID of the VPC to create the server in
   -h, --help            show this help
 `
 
@@ -227,10 +314,17 @@ func (c *DropletCreateCommand) AutocompleteArgs() complete.Predictor {
 
 func (c *DropletCreateCommand) AutocompleteFlags() complete.Flags {
 	return complete.Flags{
+		"--backups":         complete.PredictNothing,
+		"--firewall":        complete.PredictNothing,
+		"--image":           complete.PredictNothing,
+		"--monitoring":      complete.PredictNothing,
 		"--region":          complete.PredictNothing,
+		"--reserved-ip":     complete.PredictNothing,
 		"--size":            complete.PredictNothing,
 		"--skip--provision": complete.PredictNothing,
 		"--ssh-key":         complete.PredictFiles("*.pub"),
+		"--user-data":       complete.PredictFiles("*"),
+		"--vpc-uuid":        complete.PredictNothing,
 	}
 }
 
@@ -247,38 +341,97 @@ func (c *DropletCreateCommand) askDropletName(siteName string) (name string, err
 	return name, nil
 }
 
-func (c *DropletCreateCommand) createDroplet(region string, size string, image string, publicKey ssh.PublicKey, name string, env string) (droplet *godo.Droplet, err error) {
-	droplet, monitorUri, err := c.doClient.CreateDroplet(region, size, image, publicKey, name, env)
+// Applies defaults from the 'droplet' CLI config section for options not set via flags.
+func (c *DropletCreateCommand) applyConfigDefaults() {
+	config := c.Trellis.CliConfig.Droplet
+	setFlags := map[string]bool{}
+	c.flags.Visit(func(f *flag.Flag) { setFlags[f.Name] = true })
+
+	if !setFlags["region"] && config.Region != "" {
+		c.region = config.Region
+	}
+	if !setFlags["size"] && config.Size != "" {
+		c.size = config.Size
+	}
+	if !setFlags["image"] && config.Image != "" {
+		c.image = config.Image
+	}
+	if !setFlags["vpc-uuid"] && config.VpcUUID != "" {
+		c.vpcUUID = config.VpcUUID
+	}
+	if !setFlags["user-data"] && config.UserData != "" {
+		c.userData = config.UserData
+	}
+	if !setFlags["backups"] {
+		c.backups = config.Backups
+	}
+	if !setFlags["monitoring"] {
+		c.monitoring = config.Monitoring
+	}
+	if !setFlags["firewall"] {
+		c.firewall = config.Firewall
+	}
+	if !setFlags["reserved-ip"] {
+		c.reservedIP = config.ReservedIP
+	}
+}
+
+func (c *DropletCreateCommand) assignReservedIP(ctx context.Context, droplet *godo.Droplet) (ip string, err error) {
+	reservedIP, monitorUri, err := c.doClient.CreateReservedIP(ctx, droplet)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error creating reserved IP: %s", err))
+		return "", err
+	}
+
+	if monitorUri != "" {
+		err = waitForAction(ctx, c.doClient, monitorUri, SpinnerCfg{
+			Message:     "Assigning reserved IP",
+			StopMessage: "Reserved IP assigned",
+			FailMessage: "Reserved IP could not be assigned",
+		})
+
+		if err != nil {
+			c.UI.Error(fmt.Sprintf("Error assigning reserved IP: %s", err))
+			return "", err
+		}
+	}
+
+	c.UI.Info(fmt.Sprintf("%s Reserved IP assigned: %s", color.GreenString("[✓]"), reservedIP.IP))
+
+	return reservedIP.IP, nil
+}
+
+func (c *DropletCreateCommand) createDroplet(ctx context.Context, options digitalocean.DropletOptions) (droplet *godo.Droplet, err error) {
+	droplet, monitorUri, err := c.doClient.CreateDroplet(ctx, options)
 	if err != nil {
 		return nil, err
 	}
 
-	c.UI.Info(fmt.Sprintf("\n%s Server created => https://cloud.digitalocean.com/droplets/%d", color.GreenString("[✓]"), droplet.ID))
+	if c.doClient.DryRun() {
+		return droplet, nil
+	}
 
-	s := NewSpinner(
-		SpinnerCfg{
-			Message:     "Waiting for server to boot (this may take a minute)",
-			StopMessage: "Server booted",
-			FailMessage: "Server did not become active (or timed out)",
-		},
-	)
+	c.UI.Info(fmt.Sprintf("\n%s Server created => https://cloud.digitalocean.com/droplets/%d", color.GreenString("[✓]"), droplet.ID))
 
-	s.Start()
-	err = util.WaitForActive(context.TODO(), c.doClient.Client, monitorUri)
+	err = waitForAction(ctx, c.doClient, monitorUri, SpinnerCfg{
+		Message:     "Waiting for server to boot (this may take a minute)",
+		StopMessage: "Server booted",
+		FailMessage: "Server did not become active (or timed out)",
+	})
 
 	if err != nil {
-		s.StopFail()
 		c.UI.Error(err.Error())
 		return nil, err
 	}
 
-	s.Stop()
-
 	return droplet, nil
 }
 
-func (c *DropletCreateCommand) checkSSHKey(path string, contents []byte, publicKey ssh.PublicKey) error {
-	response, err := c.doClient.GetSSHKey(publicKey)
+func (c *DropletCreateCommand) checkSSHKey(ctx context.Context, path string, contents []byte, publicKey ssh.PublicKey) error {
+	response, err := c.doClient.GetSSHKey(ctx, publicKey)
+	if response == nil {
+		return fmt.Errorf("Could not get SSH key from DigitalOcean: %v", err)
+	}
 
 	switch response.StatusCode {
 	case 404:
@@ -295,7 +448,7 @@ func (c *DropletCreateCommand) checkSSHKey(path string, contents []byte, publicK
 			return errors.New("Can't continue without an SSH key on your account.")
 		}
 
-		return c.doClient.CreateSSHKey(string(contents))
+		return c.doClient.CreateSSHKey(ctx, string(contents))
 	case 200:
 		return nil
 	default:
@@ -303,8 +456,8 @@ func (c *DropletCreateCommand) checkSSHKey(path string, contents []byte, publicK
 	}
 }
 
-func (c *DropletCreateCommand) selectRegion() (region *godo.Region, err error) {
-	availableRegions, err := c.doClient.GetAvailableRegions()
+func (c *DropletCreateCommand) selectRegion(ctx context.Context) (region *godo.Region, err error) {
+	availableRegions, err := c.doClient.GetAvailableRegions(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -333,8 +486,15 @@ func (c *DropletCreateCommand) selectRegion() (region *godo.Region, err error) {
 	return &availableRegions[i], nil
 }
 
-func (c *DropletCreateCommand) selectSize(region *godo.Region) (size string, err error) {
-	sizes, err := c.doClient.GetSizesByRegion(region)
+func (c *DropletCreateCommand) selectSize(ctx context.Context, region *godo.Region) (size string, err error) {
+	if region == nil {
+		region, err = c.findRegion(ctx, c.region)
+		if err != nil {
+			return "", err
+		}
+	}
+
+	sizes, err := c.doClient.GetSizesByRegion(ctx, region)
 	if err != nil {
 		return "", err
 	}
@@ -363,14 +523,9 @@ func (c *DropletCreateCommand) selectSize(region *godo.Region) (size string, err
 	return sizes[i].Slug, nil
 }
 
-func (c *DropletCreateCommand) waitForSSH(droplet *godo.Droplet) (*godo.Droplet, error) {
-	droplet, ip, err := c.doClient.GetDroplet(droplet)
-	if err != nil {
-		return droplet, err
-	}
-
+func (c *DropletCreateCommand) waitForSSH(ctx context.Context, ip string) error {
 	ctx, cancel := context.WithTimeout(
-		context.Background(),
+		ctx,
 		3*time.Minute,
 	)
 	defer cancel()
@@ -383,12 +538,42 @@ func (c *DropletCreateCommand) waitForSSH(droplet *godo.Droplet) (*godo.Droplet,
 		},
 	)
 	s.Start()
-	err = digitalocean.CheckSSH(ip, ctx)
 
-	if err != nil {
+	if err := digitalocean.CheckSSH(ip, ctx); err != nil {
 		s.StopFail()
+		return err
 	}
+
 	s.Stop()
 
-	return droplet, nil
+	return nil
+}
+
+func (c *DropletCreateCommand) findRegion(ctx context.Context, slug string) (*godo.Region, error) {
+	regions, err := c.doClient.GetAvailableRegions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, region := range regions {
+		if region.Slug == slug {
+			return &region, nil
+		}
+	}
+
+	return nil, fmt.Errorf("Error: %s is not an available region", slug)
+}
+
+func readUserData(path string) (string, error) {
+	path, err := homedir.Expand(path)
+	if err != nil {
+		return "", err
+	}
+
+	contents, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return string(contents), nil
 }
@@ -68,3 +68,37 @@ func TestDropletCreateRunValidations(t *testing.T) {
 		})
 	}
 }
+
+func TestDropletCreateApplyConfigDefaults(t *testing.T) {
+	ui := cli.NewMockUi()
+	trellis := trellis.NewMockTrellis(true)
+	trellis.CliConfig.Droplet.Region = "nyc3"
+	trellis.CliConfig.Droplet.Size = "s-1vcpu-1gb"
+	trellis.CliConfig.Droplet.Backups = true
+	trellis.CliConfig.Droplet.Firewall = true
+	trellis.CliConfig.Droplet.ReservedIP = true
+
+	dropletCreateCommand := NewDropletCreateCommand(ui, trellis)
+
+	if err := dropletCreateCommand.flags.Parse([]string{"--region=sfo3", "--firewall=false", "production"}); err != nil {
+		t.Fatal(err)
+	}
+
+	dropletCreateCommand.applyConfigDefaults()
+
+	if dropletCreateCommand.region != "sfo3" {
+		t.Errorf("expected region flag to override config, got %s", dropletCreateCommand.region)
+	}
+
+	if dropletCreateCommand.size != "s-1vcpu-1gb" {
+		t.Errorf("expected size to default to config value, got %s", dropletCreateCommand.size)
+	}
+
+	if !dropletCreateCommand.backups || !dropletCreateCommand.reservedIP {
+		t.Errorf("expected backups and reserved IP to default to config values")
+	}
+
+	if dropletCreateCommand.firewall {
+		t.Errorf("expected firewall flag to override config")
+	}
+}
@@ -0,0 +1,330 @@
+package cmd
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/digitalocean/godo"
+	"github.com/fatih/color"
+	"github.com/manifoldco/promptui"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+	"github.com/roots/trellis-cli/digitalocean"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func NewDropletDestroyCommand(ui cli.Ui, trellis *trellis.Trellis) *DropletDestroyCommand {
+	c := &DropletDestroyCommand{UI: ui, Trellis: trellis}
+	c.init()
+	return c
+}
+
+type DropletDestroyCommand struct {
+	UI       cli.Ui
+	Trellis  *trellis.Trellis
+	doClient *digitalocean.Client
+	flags    *flag.FlagSet
+	dns      bool
+}
+
+func (c *DropletDestroyCommand) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Usage = func() { c.UI.Info(c.Help()) }
+	c.flags.BoolVar(&c.dns, "dns", false, "Also delete DNS records pointing to the droplet's IPs")
+}
+
+func (c *DropletDestroyCommand) Run(args []string) int {
+	if err := c.Trellis.LoadProject(); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = c.flags.Args()
+
+	commandArgumentValidator := &CommandArgumentValidator{required: 1, optional: 0}
+	commandArgumentErr := commandArgumentValidator.validate(args)
+	if commandArgumentErr != nil {
+		c.UI.Error(commandArgumentErr.Error())
+		c.UI.Output(c.Help())
+		return 1
+	}
+
+	environment := args[0]
+
+	environmentErr := c.Trellis.ValidateEnvironment(environment)
+	if environmentErr != nil {
+		c.UI.Error(environmentErr.Error())
+		return 1
+	}
+
+	if err := c.Trellis.ApplyEnvironmentConfig(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if environment == "development" {
+		c.UI.Error("destroy command only supports non-development environments")
+		return 1
+	}
+
+	accessToken, err := digitalocean.GetAccessToken(c.UI)
+	if err != nil {
+		c.UI.Error("Error: DigitalOcean access token is required.")
+		return 1
+	}
+
+	c.doClient = newDigitalOceanClient(c.Trellis, accessToken)
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	droplet, err := c.findDroplet(ctx, environment)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	reservedIPs, err := c.doClient.GetReservedIPs(ctx)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error: could not list reserved IPs\n%v", err))
+		return 1
+	}
+
+	reservedIPs = dropletReservedIPs(*droplet, reservedIPs)
+	ips := dropletIPs(*droplet, reservedIPs)
+
+	firewalls, err := c.doClient.GetDropletFirewalls(ctx, droplet)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error: could not list firewalls\n%v", err))
+		return 1
+	}
+
+	// Firewalls which also apply to other droplets (or by tag) are left in place
+	ownFirewalls := []godo.Firewall{}
+	sharedFirewalls := []godo.Firewall{}
+
+	for _, firewall := range firewalls {
+		if len(firewall.Tags) == 0 && len(firewall.DropletIDs) == 1 && firewall.DropletIDs[0] == droplet.ID {
+			ownFirewalls = append(ownFirewalls, firewall)
+		} else {
+			sharedFirewalls = append(sharedFirewalls, firewall)
+		}
+	}
+
+	resources := fmt.Sprintf("  %s [%s] => https://cloud.digitalocean.com/droplets/%d\n", droplet.Name, strings.Join(ips, ", "), droplet.ID)
+
+	for _, reservedIP := range reservedIPs {
+		resources += fmt.Sprintf("  reserved IP %s\n", reservedIP.IP)
+	}
+
+	for _, firewall := range ownFirewalls {
+		resources += fmt.Sprintf("  firewall %s\n", firewall.Name)
+	}
+
+	c.UI.Warn(fmt.Sprintf("The following droplet and resources will be permanently destroyed:\n\n%s", resources))
+
+	for _, firewall := range sharedFirewalls {
+		c.UI.Warn(fmt.Sprintf("Firewall %s also applies to other droplets and will not be deleted.", firewall.Name))
+	}
+
+	confirmation, err := c.UI.Ask(fmt.Sprintf("Type the environment name (%s) to confirm:", color.RedString(environment)))
+	if err != nil || confirmation != environment {
+		c.UI.Info("Aborted. Not destroying droplet.")
+		return 1
+	}
+
+	if err := c.doClient.DeleteDroplet(ctx, droplet); err != nil {
+		c.UI.Error(fmt.Sprintf("Error: could not destroy droplet %s\n%v", droplet.Name, err))
+		return 1
+	}
+
+	c.UI.Info(fmt.Sprintf("%s Droplet destroyed: %s", color.GreenString("[✓]"), droplet.Name))
+
+	failed := false
+
+	for _, reservedIP := range reservedIPs {
+		if err := c.doClient.DeleteReservedIP(ctx, reservedIP); err != nil {
+			c.UI.Error(fmt.Sprintf("Error: could not release reserved IP %s\n%v", reservedIP.IP, err))
+			failed = true
+			continue
+		}
+
+		c.UI.Info(fmt.Sprintf("%s Reserved IP released: %s", color.GreenString("[✓]"), reservedIP.IP))
+	}
+
+	for _, firewall := range ownFirewalls {
+		if err := c.doClient.DeleteFirewall(ctx, firewall); err != nil {
+			c.UI.Error(fmt.Sprintf("Error: could not delete firewall %s\n%v", firewall.Name, err))
+			failed = true
+			continue
+		}
+
+		c.UI.Info(fmt.Sprintf("%s Firewall deleted: %s", color.GreenString("[✓]"), firewall.Name))
+	}
+
+	if c.dns {
+		c.deleteDnsRecords(ctx, environment, ips)
+	}
+
+	c.UI.Info(fmt.Sprintf("\nNote: hosts/%s still references %s. Update it before provisioning a new server.", environment, strings.Join(ips, ", ")))
+
+	if failed {
+		return 1
+	}
+
+	return 0
+}
+
+func (c *DropletDestroyCommand) Synopsis() string {
+	return "Destroys the DigitalOcean Droplet for an environment"
+}
+
+func (c *DropletDestroyCommand) Help() string {
+	helpText := `
+Usage: trellis droplet destroy [options] ENVIRONMENT
+
+Destroys the droplet (server) on DigitalOcean for the environment specified.
+
+Only droplets created by Trellis (tagged with 'trellis' and the environment name)
+are considered. The droplet whose public or reserved IP matches the environment's
+hosts file (eg: 'hosts/production') is preferred.
+
+The droplet's reserved IPs and its firewalls (unless they also apply to other
+droplets) are deleted along with it.
+
+This is a destructive action which can't be undone. You will be asked to type
+the environment name to confirm.
+
+This command requires a DigitalOcean personal access token.
+If the DIGITALOCEAN_ACCESS_TOKEN environment variable is not set, the command
+will prompt for one.
+
+Destroy the production droplet:
+
+  $ trellis droplet destroy production
+
+Destroy the production droplet and delete the DNS records pointing to it:
+
+  $ trellis droplet destroy --dns production
+
+Arguments:
+  ENVIRONMENT Name of environment (ie: production)
+
+Options:
+      --dns   Also delete DNS records pointing to the droplet's IPs
+  -h, --help  show this help
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DropletDestroyCommand) AutocompleteArgs() complete.Predictor {
+	return c.Trellis.PredictEnvironment(c.flags)
+}
+
+func (c *DropletDestroyCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"--dns": complete.PredictNothing,
+	}
+}
+
+func (c *DropletDestroyCommand) deleteDnsRecords(ctx context.Context, environment string, ips []string) {
+	hostsByDomain := c.Trellis.Environments[environment].AllHostsByDomain()
+	hostRecords := c.doClient.GetHostRecords(ctx, hostsByDomain)
+
+	for _, host := range hostRecords {
+		if host.Record == nil || !slices.Contains(ips, host.Record.Data) {
+			c.UI.Info(fmt.Sprintf("%s %s", color.YellowString("[SKIPPED]"), host.Fqdn))
+			continue
+		}
+
+		if err := c.doClient.DeleteDomainRecord(ctx, *host.Record, host.Domain.Name); err != nil {
+			c.UI.Info(fmt.Sprintf("%s %s", color.RedString("[ERROR]"), host.Fqdn))
+			c.UI.Error(err.Error())
+			continue
+		}
+
+		c.UI.Info(fmt.Sprintf("%s %s", color.GreenString("[DELETED]"), host.Fqdn))
+	}
+}
+
+func (c *DropletDestroyCommand) findDroplet(ctx context.Context, environment string) (*godo.Droplet, error) {
+	droplets, err := c.doClient.GetTrellisDroplets(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("Error: could not list droplets\n%v", err)
+	}
+
+	candidates := []godo.Droplet{}
+
+	for _, droplet := range droplets {
+		if digitalocean.DropletEnvironment(droplet) == environment {
+			candidates = append(candidates, droplet)
+		}
+	}
+
+	if len(candidates) == 0 {
+		return nil, fmt.Errorf("Error: no Trellis droplets found for the %s environment", environment)
+	}
+
+	hosts, _ := c.Trellis.EnvironmentHosts(environment)
+
+	droplet, err := findHostsDroplet(ctx, c.doClient, hosts)
+	if err != nil {
+		return nil, err
+	}
+
+	if droplet != nil {
+		for _, candidate := range candidates {
+			if candidate.ID == droplet.ID {
+				return &candidate, nil
+			}
+		}
+	}
+
+	if len(candidates) == 1 {
+		return &candidates[0], nil
+	}
+
+	return c.selectDroplet(candidates)
+}
+
+func (c *DropletDestroyCommand) selectDroplet(droplets []godo.Droplet) (*godo.Droplet, error) {
+	tpl := `{{.Name}} [{{PublicIP . | faint}}]`
+
+	templates := &promptui.SelectTemplates{
+		Active:   fmt.Sprintf("%s %s", promptui.IconSelect, tpl),
+		Inactive: tpl,
+		Selected: fmt.Sprintf(`{{ "%s" | green }} %s`, promptui.IconGood, tpl),
+		FuncMap: template.FuncMap{
+			"green": promptui.Styler(promptui.FGGreen),
+			"faint": promptui.Styler(promptui.FGFaint),
+			"PublicIP": func(droplet godo.Droplet) string {
+				ip, _ := droplet.PublicIPv4()
+				return ip
+			},
+		},
+	}
+
+	prompt := promptui.Select{
+		Label:     "Select Droplet to destroy",
+		Templates: templates,
+		Items:     droplets,
+		Size:      len(droplets),
+	}
+
+	i, _, err := prompt.Run()
+
+	if err != nil {
+		return nil, err
+	}
+
+	return &droplets[i], nil
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"context"
 	"flag"
 	"fmt"
 	"strings"
@@ -67,6 +68,11 @@ func (c *DropletDnsCommand) Run(args []string) int {
 		return 1
 	}
 
+	if err := c.Trellis.ApplyEnvironmentConfig(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
 	if environment == "development" {
 		c.UI.Error("dns command only supports non-development environments")
 		return 1
@@ -78,10 +84,13 @@ func (c *DropletDnsCommand) Run(args []string) int {
 		return 1
 	}
 
-	c.doClient = digitalocean.NewClient(accessToken)
+	c.doClient = newDigitalOceanClient(c.Trellis, accessToken)
+
+	ctx, stop := interruptContext()
+	defer stop()
 
 	if c.ip == "" {
-		c.ip, err = c.selectIP()
+		c.ip, err = c.selectIP(ctx)
 		c.UI.Info("")
 
 		if err != nil {
@@ -106,11 +115,11 @@ func (c *DropletDnsCommand) Run(args []string) int {
 		return 0
 	}
 
-	hostRecords := c.doClient.GetHostRecords(hostsByDomain)
+	hostRecords := c.doClient.GetHostRecords(ctx, hostsByDomain)
 
 	for _, host := range hostRecords {
 		if !host.Domain.Exists {
-			if _, err := c.doClient.CreateDomain(host.Domain.Name); err != nil {
+			if _, err := c.doClient.CreateDomain(ctx, host.Domain.Name); err != nil {
 				c.UI.Error(fmt.Sprintf("Error: could not create domain %s\n%v", host.Domain.Name, err))
 				return 1
 			}
@@ -118,7 +127,7 @@ func (c *DropletDnsCommand) Run(args []string) int {
 
 		if host.Record != nil {
 			if c.force {
-				err := c.doClient.DeleteDomainRecord(*host.Record, host.Domain.Name)
+				err := c.doClient.DeleteDomainRecord(ctx, *host.Record, host.Domain.Name)
 
 				if err != nil {
 					c.UI.Error(fmt.Sprintf("Error: could not delete existing record %s\n%v", host.Fqdn, err))
@@ -130,7 +139,7 @@ func (c *DropletDnsCommand) Run(args []string) int {
 			}
 		}
 
-		_, err := c.doClient.CreateDomainRecord(host.Domain.Name, host.Name, c.ip)
+		_, err := c.doClient.CreateDomainRecord(ctx, host.Domain.Name, host.Name, c.ip)
 
 		if err == nil {
 			c.UI.Info(fmt.Sprintf("%s %s", color.GreenString("[CREATED]"), host.Fqdn))
@@ -225,8 +234,8 @@ func (c *DropletDnsCommand) AutocompleteFlags() complete.Flags {
 	}
 }
 
-func (c *DropletDnsCommand) selectIP() (ip string, err error) {
-	droplets, err := c.doClient.GetDroplets()
+func (c *DropletDnsCommand) selectIP(ctx context.Context) (ip string, err error) {
+	droplets, err := c.doClient.GetDroplets(ctx)
 	if err != nil {
 		return "", err
 	}
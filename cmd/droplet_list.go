@@ -0,0 +1,165 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"slices"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/fatih/color"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+	"github.com/roots/trellis-cli/digitalocean"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func NewDropletListCommand(ui cli.Ui, trellis *trellis.Trellis) *DropletListCommand {
+	c := &DropletListCommand{UI: ui, Trellis: trellis}
+	c.init()
+	return c
+}
+
+type DropletListCommand struct {
+	UI       cli.Ui
+	Trellis  *trellis.Trellis
+	doClient *digitalocean.Client
+	flags    *flag.FlagSet
+}
+
+func (c *DropletListCommand) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Usage = func() { c.UI.Info(c.Help()) }
+}
+
+func (c *DropletListCommand) Run(args []string) int {
+	if err := c.Trellis.LoadProject(); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = c.flags.Args()
+
+	commandArgumentValidator := &CommandArgumentValidator{required: 0, optional: 0}
+	commandArgumentErr := commandArgumentValidator.validate(args)
+	if commandArgumentErr != nil {
+		c.UI.Error(commandArgumentErr.Error())
+		c.UI.Output(c.Help())
+		return 1
+	}
+
+	accessToken, err := digitalocean.GetAccessToken(c.UI)
+	if err != nil {
+		c.UI.Error("Error: DigitalOcean access token is required.")
+		return 1
+	}
+
+	c.doClient = newDigitalOceanClient(c.Trellis, accessToken)
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	droplets, err := c.doClient.GetTrellisDroplets(ctx)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error: could not list droplets\n%v", err))
+		return 1
+	}
+
+	if len(droplets) == 0 {
+		c.UI.Info("No Trellis droplets found.")
+		return 0
+	}
+
+	reservedIPs, err := c.doClient.GetReservedIPs(ctx)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error: could not list reserved IPs\n%v", err))
+		return 1
+	}
+
+	var output strings.Builder
+	w := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tENVIRONMENT\tIP\tREGION\tSIZE\tSTATUS\tHOSTS FILE")
+
+	for _, droplet := range droplets {
+		env := digitalocean.DropletEnvironment(droplet)
+		ips := dropletIPs(droplet, reservedIPs)
+
+		hostsMatch := ""
+		if c.inHostsFile(env, ips) {
+			hostsMatch = color.GreenString("hosts/%s", env)
+		}
+
+		region := ""
+		if droplet.Region != nil {
+			region = droplet.Region.Slug
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\t%s\t%s\n", droplet.Name, env, strings.Join(ips, ", "), region, droplet.SizeSlug, droplet.Status, hostsMatch)
+	}
+
+	w.Flush()
+	c.UI.Output(strings.TrimSuffix(output.String(), "\n"))
+
+	return 0
+}
+
+func (c *DropletListCommand) Synopsis() string {
+	return "Lists DigitalOcean Droplets created by Trellis"
+}
+
+func (c *DropletListCommand) Help() string {
+	helpText := `
+Usage: trellis droplet list [options]
+
+Lists droplets (servers) on DigitalOcean which were created by Trellis.
+
+Droplets created with 'trellis droplet create' are tagged with 'trellis' and
+the environment name. The IP column includes reserved IPs assigned to a droplet.
+The HOSTS FILE column indicates which droplet's IP matches the environment's
+hosts file (eg: 'hosts/production').
+
+This command requires a DigitalOcean personal access token.
+If the DIGITALOCEAN_ACCESS_TOKEN environment variable is not set, the command
+will prompt for one.
+
+List all Trellis droplets:
+
+  $ trellis droplet list
+
+Options:
+  -h, --help  show this help
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DropletListCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *DropletListCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{}
+}
+
+func (c *DropletListCommand) inHostsFile(env string, ips []string) bool {
+	if env == "" || len(ips) == 0 {
+		return false
+	}
+
+	hosts, err := c.Trellis.EnvironmentHosts(env)
+	if err != nil {
+		return false
+	}
+
+	for _, host := range hosts {
+		if slices.Contains(ips, host) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,151 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+	"github.com/roots/trellis-cli/digitalocean"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func NewDropletSnapshotCommand(ui cli.Ui, trellis *trellis.Trellis) *DropletSnapshotCommand {
+	c := &DropletSnapshotCommand{UI: ui, Trellis: trellis}
+	c.init()
+	return c
+}
+
+type DropletSnapshotCommand struct {
+	UI       cli.Ui
+	Trellis  *trellis.Trellis
+	doClient *digitalocean.Client
+	flags    *flag.FlagSet
+	name     string
+}
+
+func (c *DropletSnapshotCommand) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Usage = func() { c.UI.Info(c.Help()) }
+	c.flags.StringVar(&c.name, "name", "", "Name of the snapshot (default: <droplet>-<timestamp>)")
+}
+
+func (c *DropletSnapshotCommand) Run(args []string) int {
+	if err := c.Trellis.LoadProject(); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = c.flags.Args()
+
+	commandArgumentValidator := &CommandArgumentValidator{required: 1, optional: 0}
+	commandArgumentErr := commandArgumentValidator.validate(args)
+	if commandArgumentErr != nil {
+		c.UI.Error(commandArgumentErr.Error())
+		c.UI.Output(c.Help())
+		return 1
+	}
+
+	environment := args[0]
+
+	environmentErr := c.Trellis.ValidateEnvironment(environment)
+	if environmentErr != nil {
+		c.UI.Error(environmentErr.Error())
+		return 1
+	}
+
+	if err := c.Trellis.ApplyEnvironmentConfig(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if environment == "development" {
+		c.UI.Error("snapshot command only supports non-development environments")
+		return 1
+	}
+
+	accessToken, err := digitalocean.GetAccessToken(c.UI)
+	if err != nil {
+		c.UI.Error("Error: DigitalOcean access token is required.")
+		return 1
+	}
+
+	c.doClient = newDigitalOceanClient(c.Trellis, accessToken)
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	droplet, err := findEnvironmentDroplet(ctx, c.doClient, c.Trellis, environment)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if c.name == "" {
+		c.name = defaultSnapshotName(droplet)
+	}
+
+	if err := snapshotDroplet(ctx, c.doClient, droplet, c.name); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	c.UI.Info(fmt.Sprintf("\nSnapshots => https://cloud.digitalocean.com/droplets/%d/snapshots", droplet.ID))
+
+	return 0
+}
+
+func (c *DropletSnapshotCommand) Synopsis() string {
+	return "Takes a snapshot of the DigitalOcean Droplet for an environment"
+}
+
+func (c *DropletSnapshotCommand) Help() string {
+	helpText := `
+Usage: trellis droplet snapshot [options] ENVIRONMENT
+
+Takes a snapshot of the droplet (server) on DigitalOcean for the environment specified
+and waits for it to complete.
+
+The droplet is found by matching its IP to the hosts in the environment's hosts file
+(eg: 'hosts/production').
+
+Snapshots are useful before risky operations like major Ubuntu or PHP upgrades.
+See also 'trellis provision --snapshot'.
+
+This command requires a DigitalOcean personal access token.
+If the DIGITALOCEAN_ACCESS_TOKEN environment variable is not set, the command
+will prompt for one.
+
+Snapshot the production droplet:
+
+  $ trellis droplet snapshot production
+
+Snapshot the production droplet with a custom name:
+
+  $ trellis droplet snapshot --name=before-php-upgrade production
+
+Arguments:
+  ENVIRONMENT Name of environment (ie: production)
+
+Options:
+      --name  Name of the snapshot (default: <droplet>-<timestamp>)
+  -h, --help  show this help
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DropletSnapshotCommand) AutocompleteArgs() complete.Predictor {
+	return c.Trellis.PredictEnvironment(c.flags)
+}
+
+func (c *DropletSnapshotCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"--name": complete.PredictNothing,
+	}
+}
@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+	"github.com/roots/trellis-cli/digitalocean"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func NewDropletSnapshotsCommand(ui cli.Ui, trellis *trellis.Trellis) *DropletSnapshotsCommand {
+	c := &DropletSnapshotsCommand{UI: ui, Trellis: trellis}
+	c.init()
+	return c
+}
+
+type DropletSnapshotsCommand struct {
+	UI       cli.Ui
+	Trellis  *trellis.Trellis
+	doClient *digitalocean.Client
+	flags    *flag.FlagSet
+}
+
+func (c *DropletSnapshotsCommand) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Usage = func() { c.UI.Info(c.Help()) }
+}
+
+func (c *DropletSnapshotsCommand) Run(args []string) int {
+	if err := c.Trellis.LoadProject(); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = c.flags.Args()
+
+	commandArgumentValidator := &CommandArgumentValidator{required: 1, optional: 0}
+	commandArgumentErr := commandArgumentValidator.validate(args)
+	if commandArgumentErr != nil {
+		c.UI.Error(commandArgumentErr.Error())
+		c.UI.Output(c.Help())
+		return 1
+	}
+
+	environment := args[0]
+
+	environmentErr := c.Trellis.ValidateEnvironment(environment)
+	if environmentErr != nil {
+		c.UI.Error(environmentErr.Error())
+		return 1
+	}
+
+	if err := c.Trellis.ApplyEnvironmentConfig(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if environment == "development" {
+		c.UI.Error("snapshots command only supports non-development environments")
+		return 1
+	}
+
+	accessToken, err := digitalocean.GetAccessToken(c.UI)
+	if err != nil {
+		c.UI.Error("Error: DigitalOcean access token is required.")
+		return 1
+	}
+
+	c.doClient = newDigitalOceanClient(c.Trellis, accessToken)
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	droplet, err := findEnvironmentDroplet(ctx, c.doClient, c.Trellis, environment)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	snapshots, err := c.doClient.GetDropletSnapshots(ctx, droplet)
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error: could not list snapshots\n%v", err))
+		return 1
+	}
+
+	if len(snapshots) == 0 {
+		c.UI.Info(fmt.Sprintf("No snapshots found for droplet %s.", droplet.Name))
+		return 0
+	}
+
+	var output strings.Builder
+	w := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tNAME\tCREATED\tSIZE\tREGIONS")
+
+	for _, snapshot := range snapshots {
+		fmt.Fprintf(w, "%d\t%s\t%s\t%.2fGB\t%s\n", snapshot.ID, snapshot.Name, snapshot.Created, snapshot.SizeGigaBytes, strings.Join(snapshot.Regions, ", "))
+	}
+
+	w.Flush()
+	c.UI.Output(strings.TrimSuffix(output.String(), "\n"))
+
+	return 0
+}
+
+func (c *DropletSnapshotsCommand) Synopsis() string {
+	return "Lists snapshots of the DigitalOcean Droplet for an environment"
+}
+
+func (c *DropletSnapshotsCommand) Help() string {
+	helpText := `
+Usage: trellis droplet snapshots [options] ENVIRONMENT
+
+Lists snapshots of the droplet (server) on DigitalOcean for the environment specified.
+
+The droplet is found by matching its IP to the hosts in the environment's hosts file
+(eg: 'hosts/production').
+
+This command requires a DigitalOcean personal access token.
+If the DIGITALOCEAN_ACCESS_TOKEN environment variable is not set, the command
+will prompt for one.
+
+List snapshots of the production droplet:
+
+  $ trellis droplet snapshots production
+
+Arguments:
+  ENVIRONMENT Name of environment (ie: production)
+
+Options:
+  -h, --help  show this help
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *DropletSnapshotsCommand) AutocompleteArgs() complete.Predictor {
+	return c.Trellis.PredictEnvironment(c.flags)
+}
+
+func (c *DropletSnapshotsCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{}
+}
@@ -0,0 +1,68 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/digitalocean/godo"
+	"github.com/roots/trellis-cli/digitalocean"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func TestFindEnvironmentDropletByReservedIP(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+	trellis := trellis.NewTrellis()
+	if err := trellis.LoadProject(); err != nil {
+		t.Fatal(err)
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/droplets":
+			fmt.Fprint(w, `{"droplets":[{"id":2,"name":"example.com","networks":{"v4":[{"ip_address":"5.6.7.8","type":"public"}]}}]}`)
+		case "/v2/reserved_ips/1.2.3.4":
+			fmt.Fprint(w, `{"reserved_ip":{"ip":"1.2.3.4","droplet":{"id":2,"name":"example.com"}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"id":"not_found","message":"The resource you were accessing could not be found."}`)
+		}
+	}))
+	defer server.Close()
+
+	doClient := digitalocean.NewClient("token", digitalocean.WithBaseURL(server.URL+"/"))
+
+	droplet, err := findEnvironmentDroplet(context.Background(), doClient, trellis, "production")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if droplet.ID != 2 {
+		t.Errorf("expected droplet 2 (assigned the reserved IP in hosts/production), got %d", droplet.ID)
+	}
+}
+
+func TestDropletIPs(t *testing.T) {
+	droplet := godo.Droplet{
+		ID: 1,
+		Networks: &godo.Networks{
+			V4: []godo.NetworkV4{{IPAddress: "5.6.7.8", Type: "public"}},
+		},
+	}
+
+	reservedIPs := []godo.ReservedIP{
+		{IP: "1.2.3.4", Droplet: &godo.Droplet{ID: 1}},
+		{IP: "1.2.3.5", Droplet: &godo.Droplet{ID: 2}},
+		{IP: "1.2.3.6"},
+	}
+
+	ips := dropletIPs(droplet, reservedIPs)
+	expected := []string{"5.6.7.8", "1.2.3.4"}
+
+	if !reflect.DeepEqual(ips, expected) {
+		t.Errorf("expected %v, got %v", expected, ips)
+	}
+}
@@ -0,0 +1,36 @@
+package cmd
+
+import (
+	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/pkg/events"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+/*
+Emits a core event to its subscribers (eg: plugin hooks). Errors from "pre-"
+event handlers are returned so the action can be aborted; other errors are
+only displayed as warnings.
+*/
+func emitEvent(ui cli.Ui, trellis *trellis.Trellis, event events.Event) error {
+	event.ProjectPath = trellis.Path
+
+	err := trellis.Events.Emit(event)
+	if err == nil {
+		return nil
+	}
+
+	if event.IsPre() {
+		return err
+	}
+
+	ui.Warn(err.Error())
+	return nil
+}
+
+func eventStatus(err error) string {
+	if err != nil {
+		return events.StatusFailure
+	}
+
+	return events.StatusSuccess
+}
@@ -8,6 +8,7 @@ import (
 	"syscall"
 
 	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/command"
 	"github.com/roots/trellis-cli/trellis"
 )
 
@@ -24,7 +25,7 @@ func (c *ExecCommand) Run(args []string) int {
 
 	c.Trellis.CheckVirtualenv(c.UI)
 
-	var command string
+	var name string
 	var cmdArgs []string
 
 	switch len(args) {
@@ -33,16 +34,22 @@ func (c *ExecCommand) Run(args []string) int {
 		c.UI.Output(c.Help())
 		return 1
 	default:
-		command = args[0]
+		name = args[0]
 		cmdArgs = args
 	}
 
-	cmdPath, err := exec.LookPath(command)
+	cmdPath, err := exec.LookPath(name)
 	if err != nil {
-		c.UI.Error(fmt.Sprintf("Error: %s not found", command))
+		c.UI.Error(fmt.Sprintf("Error: %s not found", name))
 		return 1
 	}
 
+	// A dry run only prints the command
+	if command.IsDryRun(c.Trellis.Runner) {
+		cmd := command.New(c.Trellis.Runner, command.WithTermOutput()).Cmd(cmdPath, cmdArgs[1:])
+		return command.ExitCode(cmd.Run())
+	}
+
 	env := os.Environ()
 	execErr := syscall.Exec(cmdPath, cmdArgs, env)
 	if execErr != nil {
@@ -1,10 +1,12 @@
 package cmd
 
 import (
+	"os/exec"
 	"strings"
 	"testing"
 
 	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/command"
 	"github.com/roots/trellis-cli/trellis"
 )
 
@@ -45,3 +47,26 @@ func TestExecRunValidations(t *testing.T) {
 		})
 	}
 }
+
+func TestExecDryRun(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+	ui := cli.NewMockUi()
+	project := trellis.NewTrellis()
+	project.Runner = command.NewDryRunner(ui.OutputWriter)
+
+	execCommand := &ExecCommand{ui, project}
+
+	if code := execCommand.Run([]string{"true", "--foo"}); code != 0 {
+		t.Errorf("expected code %d to be 0", code)
+	}
+
+	path, err := exec.LookPath("true")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "[dry-run] " + path + " --foo\n"
+	if output := ui.OutputWriter.String(); !strings.Contains(output, expected) {
+		t.Errorf("expected output %q to contain %q", output, expected)
+	}
+}
@@ -55,7 +55,8 @@ func (c *GalaxyInstallCommand) Run(args []string) int {
 
 	mockUi := cli.NewMockUi()
 
-	galaxyInstall := command.WithOptions(
+	galaxyInstall := command.New(
+		c.Trellis.Runner,
 		command.WithUiOutput(mockUi),
 	).Cmd("ansible-galaxy", []string{"install", "-r", files[0]})
 
@@ -86,7 +87,7 @@ func (c *GalaxyInstallCommand) Run(args []string) int {
 	if len(rolesToForceUpdate) > 0 {
 		c.UI.Info(fmt.Sprintf("Updating roles: %s\n", strings.Join(rolesToForceUpdate, ", ")))
 		installArgs := append([]string{"install", "-f", "-r", files[0]}, rolesToForceUpdate...)
-		galaxyInstall := command.WithOptions(command.WithLogging(c.UI)).Cmd("ansible-galaxy", installArgs)
+		galaxyInstall := command.New(c.Trellis.Runner, command.WithLogging(c.UI)).Cmd("ansible-galaxy", installArgs)
 		err = galaxyInstall.Run()
 
 		if err != nil {
@@ -89,7 +89,7 @@ func TestGalaxyInstallRun(t *testing.T) {
 			"default",
 			[]string{},
 			[]string{"galaxy.yml", "requirements.yml"},
-			"ansible-galaxy install -r galaxy.yml\nWarning: multiple role files found. Defaulting to galaxy.yml",
+			"ansible-galaxy install -r galaxy.yml\n\nWarning: multiple role files found. Defaulting to galaxy.yml",
 			0,
 		},
 	}
@@ -97,9 +97,9 @@ func TestGalaxyInstallRun(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			ui := cli.NewMockUi()
-			defer MockUiExec(t, ui)()
 
 			trellis := trellis.NewTrellis()
+			MockUiExec(ui, trellis)
 			galaxyInstallCommand := GalaxyInstallCommand{ui, trellis}
 
 			for _, file := range tc.roleFiles {
@@ -6,7 +6,6 @@ import (
 	"fmt"
 	"io"
 	"os"
-	"os/exec"
 	"strings"
 	"time"
 
@@ -182,7 +181,7 @@ func (c *InitCommand) deleteVirtualenv() error {
 	return nil
 }
 
-func (c *InitCommand) createVirtualenv(virtualenvCmd *exec.Cmd) error {
+func (c *InitCommand) createVirtualenv(virtualenvCmd *command.Cmd) error {
 	spinner := NewSpinner(
 		SpinnerCfg{
 			Message:     "Creating virtualenv",
@@ -220,7 +219,7 @@ func (c *InitCommand) upgradePip() error {
 		},
 	)
 	spinner.Start()
-	pipUpgradeOutput, err := command.Cmd("python3", []string{"-m", "pip", "install", "--upgrade", "pip"}).CombinedOutput()
+	pipUpgradeOutput, err := command.New(c.Trellis.Runner).Cmd("python3", []string{"-m", "pip", "install", "--upgrade", "pip"}).CombinedOutput()
 
 	if err != nil {
 		spinner.StopFail()
@@ -241,7 +240,7 @@ func (c *InitCommand) pipInstall() error {
 		},
 	)
 	spinner.Start()
-	pipCmd := command.Cmd("pip", []string{"install", "-r", "requirements.txt"})
+	pipCmd := command.New(c.Trellis.Runner).Cmd("pip", []string{"install", "-r", "requirements.txt"})
 
 	// Wrap pipCmd's Stdout in a custom writer that only displays output once the timer has elapsed.
 	timer := time.NewTimer(30 * time.Second)
@@ -17,6 +17,7 @@ import (
 	"github.com/mitchellh/go-homedir"
 	"github.com/posener/complete"
 	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/pkg/inventory"
 	"github.com/roots/trellis-cli/trellis"
 )
 
@@ -88,7 +89,7 @@ func (c *KeyGenerateCommand) Run(args []string) int {
 			return 1
 		}
 
-		_, err = command.Cmd("gh", []string{"auth", "status"}).Output()
+		_, err = command.New(c.Trellis.Runner).Cmd("gh", []string{"auth", "status"}).Output()
 		if err != nil {
 			c.UI.Error("Error: GitHub CLI is not authenticated.")
 			c.UI.Error("Run `gh auth login` first.")
@@ -121,7 +122,9 @@ func (c *KeyGenerateCommand) Run(args []string) int {
 	publicKeyPath := filepath.Join(c.path, publicKeyName)
 	trellisPublicKeysPath := filepath.Join(c.Trellis.Path, "public_keys")
 	trellisPublicKeyPath := filepath.Join(trellisPublicKeysPath, publicKeyName)
-	os.Mkdir(trellisPublicKeysPath, os.ModePerm)
+	if !command.IsDryRun(c.Trellis.Runner) {
+		os.Mkdir(trellisPublicKeysPath, os.ModePerm)
+	}
 
 	keyExists, _ := os.Stat(keyPath)
 	publicKeyExists, _ := os.Stat(trellisPublicKeyPath)
@@ -133,14 +136,14 @@ func (c *KeyGenerateCommand) Run(args []string) int {
 		return 1
 	}
 
-	if err := generateKey(deployKeyName, keyPath); err != nil {
+	if err := c.generateKey(deployKeyName, keyPath); err != nil {
 		c.UI.Error(err.Error())
 		return 1
 	}
 
 	c.UI.Info(fmt.Sprintf("%s Generated SSH key [%s]", color.GreenString("[✓]"), keyPath))
 
-	err := os.Rename(publicKeyPath, trellisPublicKeyPath)
+	err := command.Rename(c.Trellis.Runner, publicKeyPath, trellisPublicKeyPath)
 
 	if err != nil {
 		c.UI.Error("Error: could not move public key")
@@ -155,14 +158,14 @@ func (c *KeyGenerateCommand) Run(args []string) int {
 		return 0
 	}
 
-	if err := setPrivateKeySecret(keyPath, c.repo); err != nil {
+	if err := c.setPrivateKeySecret(keyPath, c.repo); err != nil {
 		c.UI.Error(err.Error())
 		return 1
 	}
 
 	c.UI.Info(fmt.Sprintf("%s GitHub private key secret set [%s]", color.GreenString("[✓]"), sshKeySecret))
 
-	if err := setDeployKey(deployKeyName, trellisPublicKeyPath, c.repo); err != nil {
+	if err := c.setDeployKey(deployKeyName, trellisPublicKeyPath, c.repo); err != nil {
 		c.UI.Error(err.Error())
 		return 1
 	}
@@ -170,7 +173,7 @@ func (c *KeyGenerateCommand) Run(args []string) int {
 	c.UI.Info(fmt.Sprintf("%s GitHub deploy key added [%s]", color.GreenString("[✓]"), deployKeyName))
 
 	if c.knownHosts == "" {
-		hosts, err := getAnsibleHosts()
+		hosts, err := getAnsibleHosts(c.Trellis)
 		if err != nil {
 			c.UI.Warn("Warning: could not get Ansible hosts as defaults for known hosts")
 		}
@@ -193,7 +196,7 @@ func (c *KeyGenerateCommand) Run(args []string) int {
 		}
 	}
 
-	if err := setSshKnownHostsSecret(sshKnownHostsSecret, c.knownHosts, c.repo); err != nil {
+	if err := c.setSshKnownHostsSecret(sshKnownHostsSecret, c.knownHosts, c.repo); err != nil {
 		c.UI.Error("Error: could not set SSH known hosts.")
 		c.UI.Error(err.Error())
 		return 1
@@ -312,17 +315,17 @@ func (c *KeyGenerateCommand) AutocompleteFlags() complete.Flags {
 	}
 }
 
-func githubCLI(args ...string) error {
-	ghCmd := command.Cmd("gh", args)
+func (c *KeyGenerateCommand) githubCLI(args ...string) error {
+	ghCmd := command.New(c.Trellis.Runner).Cmd("gh", args)
 	ghCmd.Stdout = io.Discard
 	ghCmd.Stderr = os.Stderr
 
 	return ghCmd.Run()
 }
 
-func generateKey(name string, path string) error {
+func (c *KeyGenerateCommand) generateKey(name string, path string) error {
 	keygenArgs := []string{"-t", "ed25519", "-C", name, "-f", path, "-P", ""}
-	sshKeygen := command.Cmd("ssh-keygen", keygenArgs)
+	sshKeygen := command.New(c.Trellis.Runner).Cmd("ssh-keygen", keygenArgs)
 	sshKeygen.Stdout = io.Discard
 	sshKeygen.Stderr = os.Stderr
 	err := sshKeygen.Run()
@@ -334,60 +337,52 @@ func generateKey(name string, path string) error {
 	return nil
 }
 
-func getAnsibleHosts() (hosts []string, err error) {
-	args := []string{"all", "--list-hosts", "--limit", "!development"}
-	hostsOutput, err := command.Cmd("ansible", args).Output()
-
+/*
+Returns the connection addresses of all hosts in the project's non-development
+inventory files (eg: 'hosts/production', 'hosts/staging').
+Placeholder hosts (eg: 'your_server_hostname') are skipped.
+*/
+func getAnsibleHosts(t *trellis.Trellis) (hosts []string, err error) {
+	entries, err := os.ReadDir(filepath.Join(t.Path, "hosts"))
 	if err != nil {
 		return nil, err
 	}
 
-	hosts = parseAnsibleHosts(string(hostsOutput))
+	seen := map[string]bool{}
 
-	if len(hosts) == 0 {
-		return nil, errors.New("No hosts found by Ansible. This is either a bug in trellis-cli or your host files are invalid.")
-	}
-
-	return hosts, nil
-}
-
-/*
-
-Parses the output of `ansible all --list-hosts` into a slice of host strings
-
-Example input:
-  hosts (3):
-    192.168.56.5
-    192.168.56.10
-    your_server_hostname
-*/
+	for _, entry := range entries {
+		if entry.IsDir() || entry.Name() == "development" {
+			continue
+		}
 
-func parseAnsibleHosts(output string) (hosts []string) {
-	lines := strings.Split(string(output), "\n")
+		inv, err := t.LoadInventory(entry.Name())
+		if err != nil {
+			return nil, err
+		}
 
-	for _, host := range lines {
-		host = strings.TrimSpace(host)
+		for _, name := range inv.GroupHosts(inventory.AllGroup) {
+			host := inv.Connection(name).Host
 
-		if strings.HasPrefix(host, "hosts (") || host == "" {
-			continue
-		}
+			if name == trellis.PlaceholderHost || seen[host] {
+				continue
+			}
 
-		// remove default placeholder since it will cause  an error
-		// this isn't ideal, but it will do
-		if host == "your_server_hostname" {
-			continue
+			seen[host] = true
+			hosts = append(hosts, host)
 		}
+	}
 
-		hosts = append(hosts, host)
+	if len(hosts) == 0 {
+		return nil, errors.New("No hosts found in your inventory files (hosts/*).")
 	}
 
-	return hosts
+	return hosts, nil
 }
 
-func keyscanHosts(hosts []string) (knownHosts []string) {
+func (c *KeyGenerateCommand) keyscanHosts(hosts []string) (knownHosts []string) {
 	for _, host := range hosts {
 		host = strings.TrimSpace(host)
-		output, err := command.Cmd("ssh-keyscan", []string{"-t", "ed25519", "-H", "-T", "1", host}).Output()
+		output, err := command.New(c.Trellis.Runner).Cmd("ssh-keyscan", []string{"-t", "ed25519", "-H", "-T", "1", host}).Output()
 
 		if err == nil {
 			knownHosts = append(knownHosts, string(output))
@@ -397,8 +392,17 @@ func keyscanHosts(hosts []string) (knownHosts []string) {
 	return knownHosts
 }
 
-func setDeployKey(name string, path string, repo string) error {
-	publicKeyContent, err := os.ReadFile(path)
+// Keys aren't generated during a dry run so their contents are placeholders.
+func (c *KeyGenerateCommand) readKeyFile(path string) ([]byte, error) {
+	if command.IsDryRun(c.Trellis.Runner) {
+		return []byte(fmt.Sprintf("<contents of %s>", path)), nil
+	}
+
+	return os.ReadFile(path)
+}
+
+func (c *KeyGenerateCommand) setDeployKey(name string, path string, repo string) error {
+	publicKeyContent, err := c.readKeyFile(path)
 	if err != nil {
 		return fmt.Errorf("Error: could not read SSH public key file\n%v", err)
 	}
@@ -411,7 +415,7 @@ func setDeployKey(name string, path string, repo string) error {
 		endpoint = strings.Replace(endpoint, "{owner}/{repo}", repo, 1)
 	}
 
-	err = githubCLI("api", endpoint, "-f", title, "-f", key, "-f", "read_only=true")
+	err = c.githubCLI("api", endpoint, "-f", title, "-f", key, "-f", "read_only=true")
 	if err != nil {
 		return fmt.Errorf("Error: could not create GitHub deploy key\n%v", err)
 	}
@@ -419,8 +423,8 @@ func setDeployKey(name string, path string, repo string) error {
 	return nil
 }
 
-func setPrivateKeySecret(path string, repo string) error {
-	privateKeyContent, err := os.ReadFile(path)
+func (c *KeyGenerateCommand) setPrivateKeySecret(path string, repo string) error {
+	privateKeyContent, err := c.readKeyFile(path)
 	if err != nil {
 		return fmt.Errorf("Error: could not read SSH private key file\n%v", err)
 	}
@@ -430,7 +434,7 @@ func setPrivateKeySecret(path string, repo string) error {
 		ghCLIArgs = append(ghCLIArgs, "--repo", repo)
 	}
 
-	err = githubCLI(ghCLIArgs...)
+	err = c.githubCLI(ghCLIArgs...)
 	if err != nil {
 		return fmt.Errorf("could not set GitHub secret\n%v", err)
 	}
@@ -438,8 +442,8 @@ func setPrivateKeySecret(path string, repo string) error {
 	return nil
 }
 
-func setSshKnownHostsSecret(sshKnownHostsSecret string, knownHosts string, repo string) error {
-	sshKnownHosts := keyscanHosts(strings.Split(knownHosts, ","))
+func (c *KeyGenerateCommand) setSshKnownHostsSecret(sshKnownHostsSecret string, knownHosts string, repo string) error {
+	sshKnownHosts := c.keyscanHosts(strings.Split(knownHosts, ","))
 	if len(sshKnownHosts) == 0 {
 		return fmt.Errorf("ssh-keyscan command failed for all hosts: %s", sshKnownHosts)
 	}
@@ -449,7 +453,7 @@ func setSshKnownHostsSecret(sshKnownHostsSecret string, knownHosts string, repo
 		ghCLIArgs = append(ghCLIArgs, "--repo", repo)
 	}
 
-	err := githubCLI(ghCLIArgs...)
+	err := c.githubCLI(ghCLIArgs...)
 	if err != nil {
 		return fmt.Errorf("Error: could not set GitHub secret\n%v", err)
 	}
@@ -1,9 +1,9 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"reflect"
 	"strings"
@@ -159,16 +159,8 @@ func TestKeyGenerateKeyscan(t *testing.T) {
 	path := os.Getenv("PATH")
 	t.Setenv("PATH", fmt.Sprintf("PATH=%s:%s", path, tmpDir))
 
-	mockExecCommand := func(command string, args []string) *exec.Cmd {
-		cs := []string{"-test.run=TestKeyGenerateHelperProcess", "--", command}
-		cs = append(cs, args...)
-		cmd := exec.Command(os.Args[0], cs...)
-		cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1", fmt.Sprintf("GO_TEST_HELPER_TMP_PATH=%s", tmpDir)}
-		return cmd
-	}
-
-	command.Mock(mockExecCommand)
-	defer command.Restore()
+	runner := &keygenRunner{FakeRunner: command.NewFakeRunner(), dir: tmpDir}
+	trellis.Runner = runner
 
 	ui := cli.NewMockUi()
 	keyGenerateCommand := NewKeyGenerateCommand(ui, trellis)
@@ -178,56 +170,47 @@ func TestKeyGenerateKeyscan(t *testing.T) {
 	if code != 0 {
 		t.Errorf("expected code %d to be %d", code, 0)
 	}
+
+	expected := "ssh-keyscan -t ed25519 -H -T 1 example.test"
+	commands := strings.Join(runner.Commands(), "\n")
+
+	if !strings.Contains(commands, expected) {
+		t.Errorf("expected commands %q to contain %q", commands, expected)
+	}
 }
 
-func TestParseAnsibleHosts(t *testing.T) {
-	output := `
-  hosts (3):
-    192.168.56.5
-    192.168.56.10
-    your_server_hostname
-`
+func TestGetAnsibleHosts(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+	trellis := trellis.NewTrellis()
 
-	hosts := parseAnsibleHosts(output)
+	if err := trellis.LoadProject(); err != nil {
+		t.Fatal(err)
+	}
 
-	expectedHosts := []string{
-		"192.168.56.5",
-		"192.168.56.10",
+	hosts, err := getAnsibleHosts(trellis)
+	if err != nil {
+		t.Fatal(err)
 	}
 
+	expectedHosts := []string{"1.2.3.4"}
+
 	if !reflect.DeepEqual(hosts, expectedHosts) {
 		t.Errorf("expected hosts %q to equal %q", hosts, expectedHosts)
 	}
 }
 
-func TestKeyGenerateHelperProcess(t *testing.T) {
-	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
-		return
-	}
-
-	switch os.Args[3] {
-	case "ssh-keyscan":
-		switch os.Args[len(os.Args)-1] {
-		case "good_host":
-			// return fake hash for a good host
-			host := "|1|5XBUprxMy6abCgLQkQ0= ssh-ed25519 AAAAC3NzaC1lZYqEOf"
-			fmt.Fprintf(os.Stdout, host)
-			os.Exit(0)
-		case "bad_host":
-			// simulate error for a bad host
-			os.Exit(1)
-		}
-	case "ssh-keygen":
-		tmpDir := os.Getenv("GO_TEST_HELPER_TMP_PATH")
-		path := filepath.Join(tmpDir, "trellis_example_com_ed25519.pub")
-		os.OpenFile(path, os.O_CREATE, 0644)
-		path = filepath.Join(tmpDir, "trellis_example_com_ed25519")
-		os.OpenFile(path, os.O_CREATE, 0644)
-		os.Exit(0)
-	case "gh":
-		// make all gh commands succeed. No output needed
-		os.Exit(0)
-	default:
-		t.Fatalf("unexpected command %s", os.Args[3])
+// Creates the key files ssh-keygen would since later steps read them.
+type keygenRunner struct {
+	*command.FakeRunner
+	dir string
+}
+
+func (r *keygenRunner) Start(ctx context.Context, cmd *command.Cmd) (command.Process, error) {
+	if cmd.Name == "ssh-keygen" {
+		path := filepath.Join(r.dir, "trellis_example_com_ed25519")
+		os.WriteFile(path, []byte{}, 0600)
+		os.WriteFile(path+".pub", []byte{}, 0644)
 	}
+
+	return r.FakeRunner.Start(ctx, cmd)
 }
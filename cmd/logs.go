@@ -3,6 +3,7 @@ package cmd
 import (
 	"flag"
 	"fmt"
+	"os"
 	"os/exec"
 	"strings"
 
@@ -22,10 +23,17 @@ type LogsCommand struct {
 	UI            cli.Ui
 	flags         *flag.FlagSet
 	access        bool
+	analyze       bool
 	error         bool
 	goaccess      bool
 	goaccessFlags string
+	json          bool
 	number        string
+	host          string
+	path          string
+	since         string
+	source        string
+	status        string
 	Trellis       *trellis.Trellis
 }
 
@@ -39,6 +47,13 @@ func (c *LogsCommand) init() {
 	c.flags.BoolVar(&c.goaccess, "goaccess", false, "Uses goaccess as the log viewer instead of tail")
 	c.flags.StringVar(&c.number, "n", "", "Location (number lines) corresponding to tail's '-n' option")
 	c.flags.StringVar(&c.number, "number", "", "Location (number lines) corresponding to tail's '-n' option")
+	c.flags.StringVar(&c.host, "host", "", "Inventory host to connect to when an environment has multiple servers")
+	c.flags.BoolVar(&c.analyze, "analyze", false, "Show a live-updating summary of the access log")
+	c.flags.BoolVar(&c.json, "json", false, "Output access log entries as JSON lines")
+	c.flags.StringVar(&c.status, "status", "", "Only include requests with these status codes or classes (eg: 5xx or 404,500)")
+	c.flags.StringVar(&c.path, "path", "", "Only include requests whose path starts with this prefix")
+	c.flags.StringVar(&c.since, "since", "", "Only include requests logged within this duration (eg: 30m, 1h, 2d)")
+	c.flags.StringVar(&c.source, "source", "nginx", "Log sources to tail: nginx, php, wp, mail, fail2ban, or all (comma separated)")
 }
 
 func (c *LogsCommand) Run(args []string) int {
@@ -70,6 +85,11 @@ func (c *LogsCommand) Run(args []string) int {
 		return 1
 	}
 
+	if err := c.Trellis.ApplyEnvironmentConfig(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
 	siteNameArg := ""
 	if len(args) == 2 {
 		siteNameArg = args[1]
@@ -80,107 +100,129 @@ func (c *LogsCommand) Run(args []string) int {
 		return 1
 	}
 
-	if environment == "development" {
-		// Use findDevInventory to auto-detect the VM provider
-		devInventory := findDevInventory(c.Trellis, c.UI)
-
-		// If we have a valid inventory and found a VM manager, use it
-		if devInventory != "" {
-			manager, managerErr := newVmManager(c.Trellis, c.UI)
-
-			if managerErr == nil {
-				// For Lima, we'll use the OpenShell method with the appropriate tail command
-				tailCmd := c.tailCmd(siteName, "tail")
-
-				if c.goaccess || c.goaccessFlags != "" {
-					_, err := exec.LookPath("goaccess")
-					if err == nil {
-						// For goaccess, we need to pipe the output to the local goaccess command
-						tailCmd = c.tailCmd(siteName, "goaccess")
-
-						// Run the command in the VM but pipe stdout to local goaccess
-						// With Lima we need to run the tail command within the VM shell
-						err := manager.OpenShell(siteName, fmt.Sprintf("/srv/www/%s/logs", siteName), []string{tailCmd})
-						if err != nil {
-							c.UI.Error(fmt.Sprintf("Error running VM shell: %s", err))
-							return 1
-						}
-						return 0
-					}
-				}
-
-				// Run the tail command in the VM
-				logDir := fmt.Sprintf("/srv/www/%s/logs", siteName)
-				err := manager.OpenShell(siteName, logDir, []string{tailCmd})
-				if err != nil {
-					c.UI.Error(fmt.Sprintf("Error running VM shell: %s", err))
-					return 1
-				}
-				return 0
-			}
-		}
-		// If no VM manager found or there was an error, fall back to SSH (for Vagrant)
+	if c.parsesLogs() {
+		return c.runAnalysis(environment, siteName)
 	}
 
-	// Original SSH-based method (for Vagrant or remote environments)
-	sshHost := c.Trellis.SshHost(environment, siteName, "web")
+	sources, err := parseLogSources(c.source)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
 
-	_, err := exec.LookPath("goaccess")
+	if c.goaccess || c.goaccessFlags != "" {
+		if len(sources) != 1 || sources[0] != "nginx" {
+			c.UI.Error("Error: --goaccess only supports the nginx log source")
+			return 1
+		}
 
-	if (c.goaccess || c.goaccessFlags != "") && err == nil {
-		tailCmd := c.tailCmd(siteName, "goaccess")
-		logArgs := []string{sshHost, tailCmd}
+		return c.runGoaccess(environment, siteName)
+	}
 
-		ssh := command.Cmd("ssh", logArgs)
-		goaccessArgs := []string{"--log-format=COMBINED"}
+	return c.tail(environment, siteName, sources)
+}
 
-		if c.goaccessFlags != "" {
-			goaccessArgs = append(goaccessArgs, strings.Split(c.goaccessFlags, " ")...)
-		}
+func (c *LogsCommand) tail(environment string, siteName string, sources []string) int {
+	streams, err := c.logStreams(environment, siteName, sources)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
 
-		goaccess := command.WithOptions(
-			command.WithTermOutput(),
-		).Cmd("goaccess", goaccessArgs)
+	if len(streams) > 1 {
+		return c.tailStreams(streams)
+	}
 
-		goaccess.Stdin, _ = ssh.StdoutPipe()
+	stream := streams[0]
 
-		if err := ssh.Start(); err != nil {
-			c.UI.Error(fmt.Sprintf("Error starting SSH command: %s", err))
+	if stream.vmManager != nil {
+		if err := stream.vmManager.OpenShell(stream.vmInstance, fmt.Sprintf("/srv/www/%s/logs", siteName), []string{stream.tailCmd}); err != nil {
+			c.UI.Error(fmt.Sprintf("Error running VM shell: %s", err))
 			return 1
 		}
 
-		if err := goaccess.Start(); err != nil {
-			c.UI.Error(fmt.Sprintf("Error starting goaccess command: %s", err))
-			return 1
-		}
+		return 0
+	}
 
-		if err := goaccess.Wait(); err != nil {
-			c.UI.Error(fmt.Sprintf("Error running goaccess command: %s", err))
-			return 1
-		}
-		if err := ssh.Wait(); err != nil {
-			c.UI.Error(fmt.Sprintf("Error running SSH command: %s", err))
-			return 1
-		}
-	} else {
-		logArgs := []string{sshHost, c.tailCmd(siteName, "tail")}
+	ssh := command.New(
+		c.Trellis.Runner,
+		command.WithTermOutput(),
+		command.WithLogging(c.UI),
+	).Cmd("ssh", append(stream.target.SshArgs(), stream.tailCmd))
 
-		ssh := command.WithOptions(
-			command.WithTermOutput(),
-			command.WithLogging(c.UI),
-		).Cmd("ssh", logArgs)
+	if err := ssh.Run(); err != nil {
+		c.UI.Error(fmt.Sprintf("Error running ssh: %s", err))
+		return 1
+	}
 
-		if err := ssh.Run(); err != nil {
-			c.UI.Error(fmt.Sprintf("Error running ssh: %s", err))
-			return 1
-		}
+	return 0
+}
+
+/*
+Pipes the Nginx logs into a local goaccess. Falls back to tailing the logs when
+goaccess isn't installed.
+*/
+func (c *LogsCommand) runGoaccess(environment string, siteName string) int {
+	if _, err := exec.LookPath("goaccess"); err != nil {
+		return c.tail(environment, siteName, []string{"nginx"})
+	}
+
+	ssh, err := c.logStreamCmd(environment, siteName, c.tailCmd(siteName, "goaccess"))
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	goaccessArgs := []string{"--log-format=COMBINED"}
+
+	if c.goaccessFlags != "" {
+		goaccessArgs = append(goaccessArgs, strings.Split(c.goaccessFlags, " ")...)
+	}
+
+	goaccess := command.New(
+		c.Trellis.Runner,
+		command.WithTermOutput(),
+	).Cmd("goaccess", goaccessArgs)
+
+	reader, writer, err := os.Pipe()
+	if err != nil {
+		c.UI.Error(fmt.Sprintf("Error creating pipe: %s", err))
+		return 1
+	}
+
+	ssh.Stdout = writer
+	goaccess.Stdin = reader
+
+	err = ssh.Start()
+	writer.Close()
+	if err != nil {
+		reader.Close()
+		c.UI.Error(fmt.Sprintf("Error starting SSH command: %s", err))
+		return 1
+	}
+
+	err = goaccess.Start()
+	reader.Close()
+	if err != nil {
+		ssh.Kill()
+		c.UI.Error(fmt.Sprintf("Error starting goaccess command: %s", err))
+		return 1
+	}
+
+	if err := goaccess.Wait(); err != nil {
+		c.UI.Error(fmt.Sprintf("Error running goaccess command: %s", err))
+		return 1
+	}
+	if err := ssh.Wait(); err != nil {
+		c.UI.Error(fmt.Sprintf("Error running SSH command: %s", err))
+		return 1
 	}
 
 	return 0
 }
 
 func (c *LogsCommand) Synopsis() string {
-	return "Tails the Nginx log files for an environment"
+	return "Tails the Nginx (and other) log files for an environment"
 }
 
 func (c *LogsCommand) Help() string {
@@ -189,10 +231,30 @@ Usage: trellis logs [options] ENVIRONMENT [SITE]
 
 Tails the Nginx log files for an environment.
 
+Other logs can be tailed with the --source option (multiple sources are tailed at once,
+with each line prefixed by its source):
+
+  nginx     Nginx access and error logs (default)
+  php       PHP-FPM logs
+  wp        WordPress debug.log (when WP_DEBUG_LOG is enabled)
+  mail      Mail logs
+  fail2ban  fail2ban logs
+  all       All of the above
+
+System logs (php, mail, fail2ban) are read as the admin user (with sudo if possible).
+When an environment has multiple web servers, all of them are tailed unless the
+--host option is used.
+
 Automatically integrates with https://goaccess.io/ when the --goaccess option is used.
 
-Note: this command relies on an SSH connection to the environment's hostname to remotely
-tail the log files. It depends on SSH keys being setup properly for a passwordless SSH connection.
+Access logs can also be analyzed without goaccess. The --analyze option shows a
+live-updating summary (requests/sec, status codes, top paths and IPs, and the slowest
+upstream responses when the log format includes $upstream_response_time).
+The --status, --path, and --since options filter requests, and --json outputs them
+as JSON lines.
+
+Note: this command relies on an SSH connection to the environment's server (resolved from
+its inventory file, eg: 'hosts/production') to remotely tail the log files. It depends on SSH keys being setup properly for a passwordless SSH connection.
 If the 'trellis ssh' command does not work, this logs command won't work either.
 
 View production logs:
@@ -219,16 +281,47 @@ View the last 50 log lines (-n corresponds to tail's -n option):
 
   $ trellis logs -n 50 production
 
+View logs of a specific server in a multi-server environment:
+
+  $ trellis logs --host web2 production
+
+View Nginx and PHP-FPM logs:
+
+  $ trellis logs --source nginx,php production
+
+View all logs of the development VM:
+
+  $ trellis logs --source all development
+
+Show a live summary of the last hour of requests:
+
+  $ trellis logs --analyze --since 1h production
+
+Only show server errors (as JSON lines):
+
+  $ trellis logs --status 5xx --json production
+
+Only show requests to the REST API:
+
+  $ trellis logs --path /wp-json/ production
+
 Arguments:
   ENVIRONMENT Name of environment (ie: production)
   SITE        Name of site (ie: example.com)
   
 Options:
       --access          Show access logs only
+      --analyze         Show a live-updating summary of the access log
       --error           Show error logs only
   -g, --goaccess        Uses goaccess as the log viewer instead of tail
       --goaccess-flags  Flags to pass to the goaccess command (in quotes)
+      --host            Inventory host to connect to when an environment has multiple servers
+      --json            Output access log entries as JSON lines
   -n, --number          Location (number lines) corresponding to tail's '-n' argument
+      --path            Only include requests whose path starts with this prefix
+      --since           Only include requests logged within this duration (eg: 30m, 1h, 2d)
+      --source          Log sources to tail: nginx, php, wp, mail, fail2ban, or all (comma separated)
+      --status          Only include requests with these status codes or classes (eg: 5xx or 404,500)
   -h, --help            Show this help
 `
 
@@ -242,10 +335,17 @@ func (c *LogsCommand) AutocompleteArgs() complete.Predictor {
 func (c *LogsCommand) AutocompleteFlags() complete.Flags {
 	return complete.Flags{
 		"--access":         complete.PredictNothing,
+		"--analyze":        complete.PredictNothing,
 		"--error":          complete.PredictNothing,
 		"--goaccess":       complete.PredictNothing,
 		"--goaccess-flags": complete.PredictNothing,
+		"--host":           complete.PredictNothing,
+		"--json":           complete.PredictNothing,
 		"--number":         complete.PredictNothing,
+		"--path":           complete.PredictNothing,
+		"--since":          complete.PredictNothing,
+		"--source":         complete.PredictSet("nginx", "php", "wp", "mail", "fail2ban", "all"),
+		"--status":         complete.PredictSet("1xx", "2xx", "3xx", "4xx", "5xx"),
 	}
 }
 
@@ -0,0 +1,207 @@
+package cmd
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/pkg/nginx_log"
+)
+
+const (
+	logsAnalyzeTop             = 10
+	logsAnalyzeRefreshInterval = time.Second
+	clearScreen                = "\033[H\033[2J"
+)
+
+// Whether access log lines are parsed natively (instead of being tailed as is).
+func (c *LogsCommand) parsesLogs() bool {
+	return c.analyze || c.json || c.status != "" || c.path != "" || c.since != ""
+}
+
+func (c *LogsCommand) logFilter() (filter nginx_log.Filter, err error) {
+	if c.status != "" {
+		if filter.Statuses, err = nginx_log.ParseStatuses(c.status); err != nil {
+			return filter, fmt.Errorf("Error: --status: %v", err)
+		}
+	}
+
+	if c.since != "" {
+		if filter.Since, err = nginx_log.ParseSince(c.since, time.Now()); err != nil {
+			return filter, fmt.Errorf("Error: --since: %v", err)
+		}
+	}
+
+	filter.Path = c.path
+
+	return filter, nil
+}
+
+/*
+Streams the access log of a site and parses each line. Depending on the options
+it outputs a (live-updating) summary, JSON lines, or the raw lines which match
+the filters.
+*/
+func (c *LogsCommand) runAnalysis(environment string, siteName string) int {
+	if c.goaccess || c.goaccessFlags != "" {
+		c.UI.Error("Error: --goaccess can't be used with --analyze, --json, or filter options")
+		return 1
+	}
+
+	if c.error || c.source != "nginx" {
+		c.UI.Error("Error: only access logs can be analyzed or filtered")
+		return 1
+	}
+
+	if c.analyze && c.json {
+		c.UI.Error("Error: --analyze and --json can't be used together")
+		return 1
+	}
+
+	filter, err := c.logFilter()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	n := c.number
+	if n == "" && (c.analyze || c.since != "") {
+		// analysis needs the log's history (the filter drops anything too old)
+		n = "+0"
+	}
+
+	tailCmd := fmt.Sprintf("tail -F /srv/www/%s/logs/access.log", siteName)
+	if n != "" {
+		tailCmd = fmt.Sprintf("tail -n %s -F /srv/www/%s/logs/access.log", n, siteName)
+	}
+
+	stream, err := c.logStreamCmd(environment, siteName, tailCmd)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	reader, writer := io.Pipe()
+	stream.Stdin = nil
+	stream.Stdout = writer
+	stream.Stderr = &command.UiErrorWriter{Ui: c.UI}
+
+	if err := stream.Start(); err != nil {
+		c.UI.Error(fmt.Sprintf("Error streaming logs: %s", err))
+		return 1
+	}
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	waitErr := make(chan error, 1)
+	go func() {
+		err := stream.Wait()
+		writer.Close()
+		waitErr <- err
+	}()
+
+	go func() {
+		<-ctx.Done()
+		stream.Kill()
+	}()
+
+	summary := nginx_log.NewSummary(logsAnalyzeTop)
+	var mu sync.Mutex
+	live := c.analyze && isInteractiveTerminal()
+
+	if live {
+		ticker := time.NewTicker(logsAnalyzeRefreshInterval)
+		defer ticker.Stop()
+
+		go func() {
+			for range ticker.C {
+				mu.Lock()
+				c.renderSummary(summary, true)
+				mu.Unlock()
+			}
+		}()
+	}
+
+	scanner := bufio.NewScanner(reader)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		line := scanner.Text()
+
+		entry, err := nginx_log.Parse(line)
+		if err != nil || !filter.Match(entry) {
+			continue
+		}
+
+		switch {
+		case c.analyze:
+			mu.Lock()
+			summary.Add(entry)
+			mu.Unlock()
+		case c.json:
+			output, _ := json.Marshal(entry)
+			c.UI.Output(string(output))
+		default:
+			c.UI.Output(line)
+		}
+	}
+
+	err = <-waitErr
+
+	if c.analyze {
+		mu.Lock()
+		c.renderSummary(summary, live)
+		mu.Unlock()
+	}
+
+	if err != nil && ctx.Err() == nil {
+		c.UI.Error(fmt.Sprintf("Error streaming logs: %s", err))
+		return 1
+	}
+
+	return 0
+}
+
+func (c *LogsCommand) renderSummary(summary *nginx_log.Summary, clear bool) {
+	var output strings.Builder
+
+	if clear {
+		output.WriteString(clearScreen)
+	}
+
+	summary.Render(&output)
+	c.UI.Output(strings.TrimSuffix(output.String(), "\n"))
+}
+
+/*
+Returns a command which runs tailCmd in the development VM (when a VM manager
+is used) or on the server over SSH, with its output available to be read.
+The VM is named after the main development site; only the workdir selects the
+site.
+*/
+func (c *LogsCommand) logStreamCmd(environment string, siteName string, tailCmd string) (*command.Cmd, error) {
+	if environment == "development" {
+		if manager, err := newVmManager(c.Trellis, c.UI); err == nil && findDevInventory(c.Trellis, c.UI) == manager.InventoryPath() {
+			instanceName, err := vmInstanceName(c.Trellis)
+			if err != nil {
+				return nil, err
+			}
+
+			return manager.ShellCmd(instanceName, fmt.Sprintf("/srv/www/%s/logs", siteName), []string{tailCmd})
+		}
+		// Without a VM manager (eg: Vagrant), fall back to SSH
+	}
+
+	target, err := selectSshTarget(c.Trellis.SshTargets(environment, siteName, "web"), c.host)
+	if err != nil {
+		return nil, err
+	}
+
+	return command.New(c.Trellis.Runner).Cmd("ssh", append(target.SshArgs(), tailCmd)), nil
+}
@@ -0,0 +1,265 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/fatih/color"
+	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/pkg/inventory"
+	"github.com/roots/trellis-cli/pkg/vm"
+)
+
+// Log sources in the order they're listed (and tailed with --source=all).
+var logSources = []string{"nginx", "php", "wp", "mail", "fail2ban"}
+
+var logSourceColors = map[string]color.Attribute{
+	"nginx":    color.FgGreen,
+	"php":      color.FgMagenta,
+	"wp":       color.FgCyan,
+	"mail":     color.FgYellow,
+	"fail2ban": color.FgRed,
+}
+
+// System logs which the web user can't read; they're tailed as the admin user (with sudo when possible).
+var systemLogFiles = map[string]string{
+	"php":      "/var/log/php*-fpm.log",
+	"mail":     "/var/log/mail.log",
+	"fail2ban": "/var/log/fail2ban.log",
+}
+
+// A log source tailed on a server (or the development VM when vmManager is set).
+type logStream struct {
+	label     string
+	source    string
+	target    inventory.Connection
+	vmManager vm.Manager
+	// The VM is named after the main development site, not the site tailed
+	vmInstance string
+	tailCmd    string
+}
+
+// Parses a comma separated list of log sources (eg: "nginx,php" or "all").
+func parseLogSources(value string) ([]string, error) {
+	sources := []string{}
+	seen := map[string]bool{}
+
+	for _, source := range strings.Split(value, ",") {
+		source = strings.TrimSpace(source)
+
+		if source == "all" {
+			return logSources, nil
+		}
+
+		if _, ok := logSourceColors[source]; !ok {
+			return nil, fmt.Errorf("Error: invalid log source %q (expected one of: %s, all)", source, strings.Join(logSources, ", "))
+		}
+
+		if !seen[source] {
+			sources = append(sources, source)
+			seen[source] = true
+		}
+	}
+
+	return sources, nil
+}
+
+func (c *LogsCommand) sourceTailCmd(siteName string, source string) string {
+	if source == "nginx" {
+		return c.tailCmd(siteName, "tail")
+	}
+
+	n := ""
+	if c.number != "" {
+		n = fmt.Sprintf("-n %s ", c.number)
+	}
+
+	if source == "wp" {
+		// Bedrock's WP_DEBUG_LOG location (WP_CONTENT_DIR/debug.log)
+		return fmt.Sprintf("tail %s-F /srv/www/%s/current/web/app/debug.log", n, siteName)
+	}
+
+	tail := fmt.Sprintf("tail %s-F %s", n, systemLogFiles[source])
+
+	return fmt.Sprintf("sudo -n %s 2>/dev/null || %s", tail, tail)
+}
+
+/*
+Returns a stream for every source on every server. Development uses the VM
+when a VM manager is used. Other environments tail every web server unless
+--host is used.
+*/
+func (c *LogsCommand) logStreams(environment string, siteName string, sources []string) ([]logStream, error) {
+	streams := []logStream{}
+
+	if environment == "development" {
+		if manager, err := newVmManager(c.Trellis, c.UI); err == nil && findDevInventory(c.Trellis, c.UI) == manager.InventoryPath() {
+			instanceName, err := vmInstanceName(c.Trellis)
+			if err != nil {
+				return nil, err
+			}
+
+			for _, source := range sources {
+				streams = append(streams, logStream{label: source, source: source, vmManager: manager, vmInstance: instanceName, tailCmd: c.sourceTailCmd(siteName, source)})
+			}
+
+			return streams, nil
+		}
+		// Without a VM manager (eg: Vagrant), fall back to SSH
+	}
+
+	webTargets := c.Trellis.SshTargets(environment, siteName, "web")
+	adminTargets := c.Trellis.SshTargets(environment, siteName, "")
+	indexes := []int{}
+
+	if c.host != "" {
+		target, err := selectSshTarget(webTargets, c.host)
+		if err != nil {
+			return nil, err
+		}
+
+		for i := range webTargets {
+			if webTargets[i] == target {
+				indexes = append(indexes, i)
+			}
+		}
+	} else {
+		for i := range webTargets {
+			indexes = append(indexes, i)
+		}
+	}
+
+	for _, i := range indexes {
+		for _, source := range sources {
+			target := webTargets[i]
+			if _, system := systemLogFiles[source]; system {
+				target = adminTargets[i]
+			}
+
+			label := source
+			if len(indexes) > 1 {
+				label = fmt.Sprintf("%s %s", target.Name, source)
+			}
+
+			streams = append(streams, logStream{label: label, source: source, target: target, tailCmd: c.sourceTailCmd(siteName, source)})
+		}
+	}
+
+	return streams, nil
+}
+
+func (s logStream) cmd(runner command.Runner) (*command.Cmd, error) {
+	if s.vmManager != nil {
+		return s.vmManager.ShellCmd(s.vmInstance, "/", []string{s.tailCmd})
+	}
+
+	return command.New(runner).Cmd("ssh", append(s.target.SshArgs(), s.tailCmd)), nil
+}
+
+/*
+Tails multiple streams at once. Each line is prefixed with its stream's label
+and coloured by source.
+*/
+func (c *LogsCommand) tailStreams(streams []logStream) int {
+	ctx, stop := interruptContext()
+	defer stop()
+
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+	failed := false
+
+	for _, stream := range streams {
+		cmd, err := stream.cmd(c.Trellis.Runner)
+		if err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+
+		prefix := color.New(logSourceColors[stream.source]).Sprintf("[%s]", stream.label)
+		stdout := &prefixWriter{ui: c.UI, prefix: prefix, mu: &mu}
+		stderr := &prefixWriter{ui: c.UI, prefix: prefix, mu: &mu, error: true}
+
+		cmd.Stdin = nil
+		cmd.Stdout = stdout
+		cmd.Stderr = stderr
+
+		command.WithLogging(c.UI)(cmd)
+
+		if err := cmd.Start(); err != nil {
+			c.UI.Error(fmt.Sprintf("Error running %s: %s", cmd.Name, err))
+			return 1
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			err := cmd.Wait()
+			stdout.Flush()
+			stderr.Flush()
+
+			if err != nil && ctx.Err() == nil {
+				mu.Lock()
+				c.UI.Error(fmt.Sprintf("%s Error tailing logs: %s", prefix, err))
+				failed = true
+				mu.Unlock()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	if failed {
+		return 1
+	}
+
+	return 0
+}
+
+// Writes complete lines to the UI, prefixed.
+type prefixWriter struct {
+	ui     cli.Ui
+	prefix string
+	mu     *sync.Mutex
+	error  bool
+	buf    []byte
+}
+
+func (w *prefixWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+
+		w.output(string(w.buf[:i]))
+		w.buf = w.buf[i+1:]
+	}
+
+	return len(p), nil
+}
+
+func (w *prefixWriter) Flush() {
+	if len(w.buf) > 0 {
+		w.output(string(w.buf))
+		w.buf = nil
+	}
+}
+
+func (w *prefixWriter) output(line string) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	line = fmt.Sprintf("%s %s", w.prefix, strings.TrimSuffix(line, "\r"))
+
+	if w.error {
+		w.ui.Error(line)
+	} else {
+		w.ui.Output(line)
+	}
+}
@@ -4,10 +4,12 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"slices"
 	"strings"
 	"testing"
 
 	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/command"
 	"github.com/roots/trellis-cli/trellis"
 )
 
@@ -111,7 +113,7 @@ func TestLogsRun(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			ui := cli.NewMockUi()
-			defer MockUiExec(t, ui)()
+			MockUiExec(ui, trellis)
 
 			logsCommand := NewLogsCommand(ui, trellis)
 			code := logsCommand.Run(tc.args)
@@ -188,7 +190,7 @@ func TestLogsRunGoAccess(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			ui := cli.NewMockUi()
-			defer MockUiExec(t, ui)()
+			MockUiExec(ui, trellis)
 
 			logsCommand := NewLogsCommand(ui, trellis)
 			code := logsCommand.Run(tc.args)
@@ -288,18 +290,312 @@ func TestLogsTailCommand(t *testing.T) {
 	}
 }
 
-func TestLogsHelperProcess(t *testing.T) {
-	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
-		return
+func TestLogsRunAnalysis(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+	trellis := trellis.NewTrellis()
+
+	lines := []string{
+		`1.1.1.1 - - [10/Oct/2024:13:55:36 +0000] "GET / HTTP/1.1" 200 512 "-" "curl/8.0"`,
+		`2.2.2.2 - - [10/Oct/2024:13:55:37 +0000] "GET /wp-json/wp/v2/posts HTTP/1.1" 502 0 "-" "curl/8.0" "-" 1.503 1.500`,
+		`not a log line`,
+	}
+
+	cases := []struct {
+		name string
+		args []string
+		out  []string
+		code int
+	}{
+		{
+			"analyze",
+			[]string{"--analyze", "development"},
+			[]string{"Requests: 2 total", "2xx 1 (50.0%)", "5xx 1 (50.0%)", "/wp-json/wp/v2/posts", "1.500s"},
+			0,
+		},
+		{
+			"json_with_status_filter",
+			[]string{"--json", "--status=5xx", "development"},
+			[]string{`"remote_addr":"2.2.2.2"`, `"status":502`, `"upstream_time":1.5`},
+			0,
+		},
+		{
+			"path_filter",
+			[]string{"--path=/wp-json/", "development"},
+			[]string{lines[1]},
+			0,
+		},
+		{
+			"invalid_status",
+			[]string{"--status=6xx", "development"},
+			[]string{"Error: --status: invalid status"},
+			1,
+		},
+		{
+			"invalid_since",
+			[]string{"--since=1y", "development"},
+			[]string{"Error: --since: invalid duration"},
+			1,
+		},
+		{
+			"goaccess",
+			[]string{"--analyze", "--goaccess", "development"},
+			[]string{"Error: --goaccess can't be used with --analyze"},
+			1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+
+			runner := command.NewFakeRunner(command.MockCommand{Command: "ssh", Output: strings.Join(lines, "\n") + "\n"})
+			runner.Strict = true
+			trellis.Runner = runner
+
+			logsCommand := NewLogsCommand(ui, trellis)
+			code := logsCommand.Run(tc.args)
+
+			if code != tc.code {
+				t.Errorf("expected code %d to be %d", code, tc.code)
+			}
+
+			combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+
+			for _, out := range tc.out {
+				if !strings.Contains(combined, out) {
+					t.Errorf("expected output %q to contain %q", combined, out)
+				}
+			}
+
+			if strings.Contains(combined, lines[2]) {
+				t.Errorf("expected output %q to not contain unparsable lines", combined)
+			}
+
+			executed := strings.Join(runner.Commands(), "\n")
+
+			if tc.code == 0 && !strings.HasSuffix(executed, "-F /srv/www/example.com/logs/access.log") {
+				t.Errorf("expected access log to be tailed, got %q", executed)
+			}
+		})
+	}
+}
+
+func TestLogsRunSources(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+	trellis := trellis.NewTrellis()
+
+	cases := []struct {
+		name string
+		args []string
+		out  []string
+		code int
+	}{
+		{
+			"php",
+			[]string{"--source=php", "development"},
+			[]string{"ssh vagrant@example.test sudo -n tail -F /var/log/php*-fpm.log 2>/dev/null || tail -F /var/log/php*-fpm.log"},
+			0,
+		},
+		{
+			"wp",
+			[]string{"--source=wp", "-n", "20", "production"},
+			[]string{"ssh web@1.2.3.4 tail -n 20 -F /srv/www/example.com/current/web/app/debug.log"},
+			0,
+		},
+		{
+			"multiple",
+			[]string{"--source=nginx,mail", "production"},
+			[]string{
+				"ssh web@1.2.3.4 tail -f /srv/www/example.com/logs/*[^gz]?",
+				"ssh admin@1.2.3.4 sudo -n tail -F /var/log/mail.log",
+				"[nginx] log line",
+				"[mail] log line",
+			},
+			0,
+		},
+		{
+			"invalid",
+			[]string{"--source=foo", "production"},
+			[]string{`Error: invalid log source "foo"`},
+			1,
+		},
+		{
+			"goaccess",
+			[]string{"--source=php", "--goaccess", "production"},
+			[]string{"Error: --goaccess only supports the nginx log source"},
+			1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			runner := MockUiExec(ui, trellis)
+			runner.Stubs = []command.MockCommand{{Command: "ssh", Output: "log line\n"}}
+
+			logsCommand := NewLogsCommand(ui, trellis)
+			code := logsCommand.Run(tc.args)
+
+			if code != tc.code {
+				t.Errorf("expected code %d to be %d", code, tc.code)
+			}
+
+			combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+
+			for _, out := range tc.out {
+				if !strings.Contains(combined, out) {
+					t.Errorf("expected output %q to contain %q", combined, out)
+				}
+			}
+		})
+	}
+}
+
+func TestLogsRunSourcesMultipleHosts(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+
+	hosts := "[production]\nweb1 ansible_host=1.2.3.4\nweb2 ansible_host=5.6.7.8\n\n[web]\nweb1\nweb2\n"
+	if err := os.WriteFile(filepath.Join("hosts", "production"), []byte(hosts), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	project := trellis.NewTrellis()
+
+	cases := []struct {
+		name string
+		args []string
+		out  []string
+	}{
+		{
+			"all_hosts",
+			[]string{"--source=nginx", "production"},
+			[]string{"[web1 nginx] GET / from web1", "[web2 nginx] GET / from web2"},
+		},
+		{
+			"host",
+			[]string{"--source=nginx", "--host=web2", "production"},
+			[]string{"ssh web@5.6.7.8 tail -f"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			runner := MockUiExec(ui, project)
+			runner.Stubs = []command.MockCommand{
+				{Command: "ssh", Args: []string{"web@1.2.3.4", "tail -f /srv/www/example.com/logs/*[^gz]?"}, Output: "GET / from web1\n"},
+				{Command: "ssh", Args: []string{"web@5.6.7.8", "tail -f /srv/www/example.com/logs/*[^gz]?"}, Output: "GET / from web2\n"},
+			}
+
+			logsCommand := NewLogsCommand(ui, project)
+			code := logsCommand.Run(tc.args)
+
+			if code != 0 {
+				t.Errorf("expected code %d to be 0", code)
+			}
+
+			combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+
+			for _, out := range tc.out {
+				if !strings.Contains(combined, out) {
+					t.Errorf("expected output %q to contain %q", combined, out)
+				}
+			}
+		})
+	}
+}
+
+func TestParseLogSources(t *testing.T) {
+	sources, err := parseLogSources("php, nginx,php")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Join(sources, ",") != "php,nginx" {
+		t.Errorf("expected sources %v to be [php nginx]", sources)
+	}
+
+	sources, _ = parseLogSources("all")
+	if strings.Join(sources, ",") != "nginx,php,wp,mail,fail2ban" {
+		t.Errorf("expected all sources, got %v", sources)
+	}
+}
+
+func TestLogsRunSourcesDevelopmentVmMultisite(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+
+	ui := cli.NewMockUi()
+	project := trellis.NewTrellis()
+	runner := mockLimaProject(t, ui, project)
+
+	logsCommand := NewLogsCommand(ui, project)
+	code := logsCommand.Run([]string{"--source=nginx,wp", "development", "example2.com"})
+
+	if code != 0 {
+		t.Fatalf("expected code %d to be 0: %s", code, ui.ErrorWriter.String())
+	}
+
+	expected := []string{
+		"limactl shell --workdir / example.com tail -f /srv/www/example2.com/logs/*[^gz]?",
+		"limactl shell --workdir / example.com tail -F /srv/www/example2.com/current/web/app/debug.log",
+	}
+
+	for _, command := range expected {
+		if !slices.Contains(runner.Commands(), command) {
+			t.Errorf("expected commands %q to contain %q", runner.Commands(), command)
+		}
+	}
+}
+
+func TestLogsRunSourcesDryRun(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+
+	ui := cli.NewMockUi()
+	project := trellis.NewTrellis()
+	project.Runner = command.NewDryRunner(ui.OutputWriter)
+
+	logsCommand := NewLogsCommand(ui, project)
+	code := logsCommand.Run([]string{"--source=nginx,mail", "production"})
+
+	if code != 0 {
+		t.Fatalf("expected code %d to be 0: %s", code, ui.ErrorWriter.String())
+	}
+
+	output := ui.OutputWriter.String()
+
+	if strings.Contains(output, "Running command =>") {
+		t.Errorf("expected output %q not to log commands during a dry run", output)
+	}
+
+	if count := strings.Count(output, "[dry-run] ssh"); count != 2 {
+		t.Errorf("expected each command to be printed once, got %d in %q", count, output)
+	}
+}
+
+func TestLogsRunAnalysisDevelopmentVmMultisite(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+
+	ui := cli.NewMockUi()
+	project := trellis.NewTrellis()
+	runner := mockLimaProject(t, ui, project)
+
+	logsCommand := NewLogsCommand(ui, project)
+	code := logsCommand.Run([]string{"--json", "development", "example2.com"})
+
+	if code != 0 {
+		t.Fatalf("expected code %d to be 0: %s", code, ui.ErrorWriter.String())
+	}
+
+	prefix := "limactl shell --workdir /srv/www/example2.com/logs example.com "
+	found := false
+
+	for _, command := range runner.Commands() {
+		if strings.HasPrefix(command, prefix) && strings.HasSuffix(command, "/srv/www/example2.com/logs/access.log") {
+			found = true
+		}
 	}
 
-	switch os.Args[3] {
-	case "ssh":
-		fmt.Fprintf(os.Stdout, "nginx log")
-		os.Exit(0)
-	case "tail":
-		os.Exit(0)
-	default:
-		t.Fatalf("unexpected command %s", os.Args[3])
+	if !found {
+		t.Errorf("expected commands %q to tail example2.com's access log in the example.com VM", runner.Commands())
 	}
 }
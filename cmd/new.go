@@ -11,6 +11,7 @@ import (
 	"github.com/fatih/color"
 	"github.com/manifoldco/promptui"
 	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/command"
 	"github.com/roots/trellis-cli/github"
 	"github.com/roots/trellis-cli/trellis"
 	"github.com/weppos/publicsuffix-go/publicsuffix"
@@ -146,19 +147,25 @@ func (c *NewCommand) Run(args []string) int {
 	// Update default configs
 	for env, config := range c.trellis.Environments {
 		c.trellis.UpdateDefaultConfig(config, c.name, c.host, env)
-		c.trellis.WriteYamlFile(
+		if err := c.trellis.WriteYamlFile(
 			config,
 			filepath.Join("group_vars", env, "wordpress_sites.yml"),
 			c.YamlHeader("https://roots.io/trellis/docs/wordpress-sites/"),
-		)
+		); err != nil {
+			c.UI.Error(fmt.Sprintf("Error writing wordpress_sites.yml: %s", err))
+			return 1
+		}
 
 		stringGenerator := trellis.RandomStringGenerator{Length: 64}
 		vault := c.trellis.GenerateVaultConfig(c.name, env, &stringGenerator)
-		c.trellis.WriteYamlFile(
+		if err := c.trellis.WriteYamlFile(
 			vault,
 			filepath.Join("group_vars", env, "vault.yml"),
 			c.YamlHeader("https://roots.io/trellis/docs/vault/"),
-		)
+		); err != nil {
+			c.UI.Error(fmt.Sprintf("Error writing vault.yml: %s", err))
+			return 1
+		}
 	}
 
 	if err := c.trellis.GenerateVaultPassFile(c.vaultPass); err != nil {
@@ -245,9 +252,9 @@ func (c *NewCommand) YamlHeader(doc string) string {
 	return fmt.Sprintf(header, c.CliVersion, doc)
 }
 
-func addTrellisFile(path string) error {
+func addTrellisFile(runner command.Runner, path string) error {
 	path = filepath.Join(path, ".trellis.yml")
-	return os.WriteFile(path, []byte{}, 0666)
+	return command.WriteFile(runner, path, []byte{}, 0666)
 }
 
 func askDomain(ui cli.Ui, path string) (host string, err error) {
@@ -60,7 +60,8 @@ func (c *OpenCommand) Run(args []string) int {
 		return 1
 	}
 
-	open := command.WithOptions(
+	open := command.New(
+		c.Trellis.Runner,
 		command.WithTermOutput(),
 		command.WithLogging(c.UI),
 	).Cmd(openCommandName, openArgs)
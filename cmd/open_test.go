@@ -88,7 +88,7 @@ func TestOpenRun(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			ui := cli.NewMockUi()
-			defer MockUiExec(t, ui)()
+			MockUiExec(ui, trellis)
 
 			downCommand := &OpenCommand{ui, trellis}
 			code := downCommand.Run(tc.args)
@@ -14,51 +14,132 @@ limitations under the License.
 package cmd
 
 import (
+	"encoding/json"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"runtime"
+	"sort"
 	"strings"
 	"syscall"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/trellis"
 )
 
 type PassthroughCommand struct {
 	Bin  string
 	Name string
 	Args []string
+	// Optional metadata provided by the plugin's manifest
+	SynopsisText    string
+	HelpText        string
+	CompletionArgs  []string
+	CompletionFlags []string
+	// When set, the project is detected and its context exported to the plugin
+	UI      cli.Ui
+	Trellis *trellis.Trellis
 }
 
 // Taken from https://github.com/kubernetes/kubectl/blob/b155278f1f4a21a0be2d4f6f0037258dee4d1a22/pkg/cmd/cmd.go#L371
 func (c *PassthroughCommand) Run(args []string) int {
-	// Windows does not support exec syscall.
-	if runtime.GOOS == "windows" {
-		cmd := exec.Command(c.Bin, args...)
-		cmd.Stdout = os.Stdout
-		cmd.Stderr = os.Stderr
-		cmd.Stdin = os.Stdin
-		cmd.Env = os.Environ()
-		err := cmd.Run()
-		if err == nil {
-			return 0
-		}
-		return 1
+	// Must run first since loading the project may activate its virtualenv
+	projectEnv := c.projectEnv()
+	env := append(os.Environ(), projectEnv...)
+
+	var runner command.Runner = command.NewExecRunner()
+	if c.Trellis != nil {
+		runner = c.Trellis.Runner
+	}
+
+	// Windows does not support exec syscall. A dry run only prints the command.
+	if runtime.GOOS == "windows" || command.IsDryRun(runner) {
+		cmd := command.New(runner, command.WithTermOutput()).Cmd(c.Bin, args)
+		cmd.Env = env
+
+		return command.ExitCode(cmd.Run())
 	}
 
 	// invoke cmd binary relaying the environment and args given
 	// append executablePath to cmdArgs, as execve will make first argument the "binary name".
-	if err := syscall.Exec(c.Bin, append([]string{c.Bin}, args...), os.Environ()); err != nil {
+	if err := syscall.Exec(c.Bin, append([]string{c.Bin}, args...), env); err != nil {
 		return 1
 	}
 
 	return 0
 }
 
+/*
+Detects the Trellis project (if any) and returns env vars describing it for
+the plugin. Loading the project activates its virtualenv when the
+virtualenv_integration setting is enabled, so it's inherited by the plugin too.
+Plugins are still run from the current working directory.
+*/
+func (c *PassthroughCommand) projectEnv() []string {
+	if c.Trellis == nil {
+		return nil
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return nil
+	}
+	defer os.Chdir(wd)
+
+	// Plugins don't require a project
+	if err := c.Trellis.LoadProject(); err != nil {
+		return nil
+	}
+
+	return pluginEnv(c.Trellis, c.UI)
+}
+
+func pluginEnv(t *trellis.Trellis, ui cli.Ui) []string {
+	environments := t.EnvironmentNames()
+	sort.Strings(environments)
+
+	env := []string{
+		"TRELLIS_PROJECT_PATH=" + t.Path,
+		"TRELLIS_CONFIG_DIR=" + t.ConfigPath(),
+		"TRELLIS_ENVIRONMENTS=" + strings.Join(environments, ","),
+	}
+
+	if t.VenvInitialized {
+		env = append(env, "TRELLIS_VENV_PATH="+t.Virtualenv.Path)
+	}
+
+	if cliConfig, err := json.Marshal(t.CliConfig); err == nil {
+		env = append(env, "TRELLIS_CLI_CONFIG="+string(cliConfig))
+	}
+
+	if _, ok := t.Environments["development"]; ok {
+		if inventory := findDevInventory(t, ui); inventory != "" {
+			if !filepath.IsAbs(inventory) {
+				inventory = filepath.Join(t.Path, inventory)
+			}
+
+			env = append(env, "TRELLIS_VM_INVENTORY_PATH="+inventory)
+		}
+	}
+
+	return env
+}
+
 func (c *PassthroughCommand) Synopsis() string {
+	if c.SynopsisText != "" {
+		return c.SynopsisText
+	}
+
 	return fmt.Sprintf("Third party plugin: Forward command to %s", filepath.Base(c.Bin))
 }
 
 func (c *PassthroughCommand) Help() string {
+	if c.HelpText != "" {
+		return strings.TrimSpace(c.HelpText)
+	}
+
 	requested := strings.Join(c.Args, " ")
 
 	if strings.HasPrefix(requested, c.Name) {
@@ -68,3 +149,21 @@ func (c *PassthroughCommand) Help() string {
 
 	return ""
 }
+
+func (c *PassthroughCommand) AutocompleteArgs() complete.Predictor {
+	if len(c.CompletionArgs) == 0 {
+		return complete.PredictNothing
+	}
+
+	return complete.PredictSet(c.CompletionArgs...)
+}
+
+func (c *PassthroughCommand) AutocompleteFlags() complete.Flags {
+	flags := complete.Flags{}
+
+	for _, flag := range c.CompletionFlags {
+		flags[flag] = complete.PredictNothing
+	}
+
+	return flags
+}
@@ -0,0 +1,81 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func TestPassthroughProjectEnv(t *testing.T) {
+	cleanup := trellis.LoadFixtureProject(t)
+	defer cleanup()
+
+	if err := os.Mkdir("group_vars/all/nested", 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir("group_vars/all/nested"); err != nil {
+		t.Fatal(err)
+	}
+
+	wd, _ := os.Getwd()
+
+	project := trellis.NewTrellis()
+	project.CliConfig.VirtualenvIntegration = false
+
+	passthroughCommand := &PassthroughCommand{Bin: "trellis-foo", Name: "foo", UI: cli.NewMockUi(), Trellis: project}
+	env := passthroughCommand.projectEnv()
+
+	if cwd, _ := os.Getwd(); cwd != wd {
+		t.Errorf("expected working directory to be restored to %s, got %s", wd, cwd)
+	}
+
+	expected := []string{
+		"TRELLIS_PROJECT_PATH=" + project.Path,
+		"TRELLIS_CONFIG_DIR=" + filepath.Join(project.Path, ".trellis"),
+		"TRELLIS_ENVIRONMENTS=development,production,valet-link",
+		`TRELLIS_CLI_CONFIG={"aliases":`,
+		`"virtualenv_integration":false,`,
+	}
+
+	output := strings.Join(env, "\n")
+
+	for _, e := range expected {
+		if !strings.Contains(output, e) {
+			t.Errorf("expected env %q to contain %q", output, e)
+		}
+	}
+
+	if strings.Contains(output, "TRELLIS_VENV_PATH") {
+		t.Errorf("expected env %q not to contain TRELLIS_VENV_PATH when the virtualenv isn't initialized", output)
+	}
+}
+
+func TestPassthroughProjectEnvWithoutProject(t *testing.T) {
+	passthroughCommand := &PassthroughCommand{Bin: "trellis-foo", Name: "foo", UI: cli.NewMockUi(), Trellis: trellis.NewMockTrellis(false)}
+
+	if env := passthroughCommand.projectEnv(); len(env) != 0 {
+		t.Errorf("expected no env vars outside a project, got %v", env)
+	}
+}
+
+func TestPassthroughDryRun(t *testing.T) {
+	ui := cli.NewMockUi()
+	project := trellis.NewMockTrellis(false)
+	project.Runner = command.NewDryRunner(ui.OutputWriter)
+
+	passthroughCommand := &PassthroughCommand{Bin: "/usr/local/bin/trellis-foo", Name: "foo", UI: ui, Trellis: project}
+
+	if code := passthroughCommand.Run([]string{"bar", "--baz"}); code != 0 {
+		t.Errorf("expected code %d to be 0", code)
+	}
+
+	expected := "[dry-run] /usr/local/bin/trellis-foo bar --baz\n"
+	if output := ui.OutputWriter.String(); output != expected {
+		t.Errorf("expected output %q, got %q", expected, output)
+	}
+}
@@ -1,6 +1,7 @@
 package cmd
 
 import (
+	"errors"
 	"flag"
 	"os"
 	"strings"
@@ -8,7 +9,9 @@ import (
 	"github.com/mitchellh/cli"
 	"github.com/posener/complete"
 	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/digitalocean"
 	"github.com/roots/trellis-cli/pkg/ansible"
+	"github.com/roots/trellis-cli/pkg/events"
 	"github.com/roots/trellis-cli/trellis"
 )
 
@@ -22,6 +25,7 @@ type ProvisionCommand struct {
 	UI        cli.Ui
 	flags     *flag.FlagSet
 	extraVars string
+	snapshot  bool
 	tags      string
 	Trellis   *trellis.Trellis
 	verbose   bool
@@ -31,6 +35,7 @@ func (c *ProvisionCommand) init() {
 	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
 	c.flags.Usage = func() { c.UI.Info(c.Help()) }
 	c.flags.StringVar(&c.extraVars, "extra-vars", "", "Additional variables which are passed through to Ansible as 'extra-vars'")
+	c.flags.BoolVar(&c.snapshot, "snapshot", false, "Take a DigitalOcean Droplet snapshot before provisioning")
 	c.flags.StringVar(&c.tags, "tags", "", "only run roles and tasks tagged with these values")
 	c.flags.BoolVar(&c.verbose, "verbose", false, "Enable Ansible's verbose mode")
 }
@@ -65,6 +70,30 @@ func (c *ProvisionCommand) Run(args []string) int {
 		return 1
 	}
 
+	if err := c.Trellis.ApplyEnvironmentConfig(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := emitEvent(c.UI, c.Trellis, events.Event{Name: events.PreProvision, Environment: environment}); err != nil {
+		c.UI.Error(err.Error())
+		c.UI.Error("Aborting provision since a pre-provision hook failed.")
+		return 1
+	}
+
+	if c.snapshot {
+		if environment == "development" {
+			c.UI.Error("Error: the --snapshot option is only supported for non-development environments")
+			return 1
+		}
+
+		if err := c.takeSnapshot(environment); err != nil {
+			c.UI.Error(err.Error())
+			c.UI.Error("Aborting provision since the snapshot could not be created.")
+			return 1
+		}
+	}
+
 	galaxyInstallCommand := &GalaxyInstallCommand{c.UI, c.Trellis}
 	galaxyInstallCommand.Run([]string{})
 
@@ -88,13 +117,20 @@ func (c *ProvisionCommand) Run(args []string) int {
 		playbook.SetInventory(findDevInventory(c.Trellis, c.UI))
 	}
 
-	provision := command.WithOptions(
+	provision := command.New(
+		c.Trellis.Runner,
 		command.WithUiOutput(c.UI),
 		command.WithLogging(c.UI),
 	).Cmd("ansible-playbook", playbook.CmdArgs())
 
-	if err := provision.Run(); err != nil {
+	err := provision.Run()
+	if err != nil {
 		c.UI.Error(err.Error())
+	}
+
+	emitEvent(c.UI, c.Trellis, events.Event{Name: events.PostProvision, Environment: environment, Status: eventStatus(err)})
+
+	if err != nil {
 		return 1
 	}
 
@@ -130,11 +166,16 @@ Provision and provide extra vars to Ansible:
 
   $ trellis provision --extra-vars key=value production
 
+Take a DigitalOcean Droplet snapshot before provisioning:
+
+  $ trellis provision --snapshot production
+
 Arguments:
   ENVIRONMENT Name of environment (ie: production)
   
 Options:
       --extra-vars  (multiple) Set additional variables as key=value or YAML/JSON, if filename prepend with @
+      --snapshot    Take a DigitalOcean Droplet snapshot before provisioning (requires DIGITALOCEAN_ACCESS_TOKEN)
       --tags        (multiple) Only run roles and tasks tagged with these values
       --verbose     Enable Ansible's verbose mode
   -h, --help        Show this help
@@ -150,7 +191,27 @@ func (c *ProvisionCommand) AutocompleteArgs() complete.Predictor {
 func (c *ProvisionCommand) AutocompleteFlags() complete.Flags {
 	return complete.Flags{
 		"--extra-vars": complete.PredictNothing,
+		"--snapshot":   complete.PredictNothing,
 		"--tags":       complete.PredictNothing,
 		"--verbose":    complete.PredictNothing,
 	}
 }
+
+func (c *ProvisionCommand) takeSnapshot(environment string) error {
+	accessToken, err := digitalocean.GetAccessToken(c.UI)
+	if err != nil {
+		return errors.New("Error: DigitalOcean access token is required.")
+	}
+
+	doClient := newDigitalOceanClient(c.Trellis, accessToken)
+
+	ctx, stop := interruptContext()
+	defer stop()
+
+	droplet, err := findEnvironmentDroplet(ctx, doClient, c.Trellis, environment)
+	if err != nil {
+		return err
+	}
+
+	return snapshotDroplet(ctx, doClient, droplet, defaultSnapshotName(droplet))
+}
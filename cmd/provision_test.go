@@ -46,6 +46,13 @@ func TestProvisionRunValidations(t *testing.T) {
 			"Error: foo is not a valid environment",
 			1,
 		},
+		{
+			"snapshot_development",
+			true,
+			[]string{"--snapshot", "development"},
+			"Error: the --snapshot option is only supported for non-development environments",
+			1,
+		},
 	}
 
 	for _, tc := range cases {
@@ -115,7 +122,7 @@ func TestProvisionRun(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			ui := cli.NewMockUi()
-			defer MockUiExec(t, ui)()
+			MockUiExec(ui, trellis)
 
 			provisionCommand := NewProvisionCommand(ui, trellis)
 
@@ -2,33 +2,38 @@ package cmd
 
 import (
 	"flag"
+	"fmt"
 	"strings"
 
 	"github.com/mitchellh/cli"
 	"github.com/posener/complete"
 	"github.com/roots/trellis-cli/command"
 	"github.com/roots/trellis-cli/pkg/ansible"
+	"github.com/roots/trellis-cli/pkg/db_opener"
 	"github.com/roots/trellis-cli/trellis"
 )
 
 func NewRollbackCommand(ui cli.Ui, trellis *trellis.Trellis) *RollbackCommand {
-	c := &RollbackCommand{UI: ui, Trellis: trellis}
+	c := &RollbackCommand{UI: ui, Trellis: trellis, dbPlaybook: newDBCredentialsPlaybook(trellis)}
 	c.init()
 	return c
 }
 
 type RollbackCommand struct {
-	UI      cli.Ui
-	flags   *flag.FlagSet
-	release string
-	Trellis *trellis.Trellis
-	verbose bool
+	UI         cli.Ui
+	flags      *flag.FlagSet
+	release    string
+	restoreDB  bool
+	dbPlaybook *AdHocPlaybook
+	Trellis    *trellis.Trellis
+	verbose    bool
 }
 
 func (c *RollbackCommand) init() {
 	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
 	c.flags.Usage = func() { c.UI.Info(c.Help()) }
 	c.flags.StringVar(&c.release, "release", "", "Release to rollback instead of latest one")
+	c.flags.BoolVar(&c.restoreDB, "restore-db", false, "Restore the database snapshot created when deploying over the release rolled back to")
 	c.flags.BoolVar(&c.verbose, "verbose", false, "Enable Ansible's verbose mode")
 }
 
@@ -61,6 +66,11 @@ func (c *RollbackCommand) Run(args []string) int {
 		return 1
 	}
 
+	if err := c.Trellis.ApplyEnvironmentConfig(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
 	siteNameArg := c.flags.Arg(1)
 	siteName, siteNameErr := c.Trellis.FindSiteNameFromEnvironment(environment, siteNameArg)
 	if siteNameErr != nil {
@@ -68,6 +78,22 @@ func (c *RollbackCommand) Run(args []string) int {
 		return 1
 	}
 
+	var credentials db_opener.DBCredentials
+	var snapshot string
+
+	if c.restoreDB {
+		if !confirmDatabaseOverwrite(c.UI, environment, siteName) {
+			return 1
+		}
+
+		var err error
+		credentials, snapshot, err = c.findDBSnapshot(environment, siteName)
+		if err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+	}
+
 	playbook := ansible.Playbook{
 		Name:    "rollback.yml",
 		Env:     environment,
@@ -81,7 +107,8 @@ func (c *RollbackCommand) Run(args []string) int {
 		playbook.AddExtraVar("release", c.release)
 	}
 
-	rollback := command.WithOptions(
+	rollback := command.New(
+		c.Trellis.Runner,
 		command.WithTermOutput(),
 		command.WithLogging(c.UI),
 	).Cmd("ansible-playbook", playbook.CmdArgs())
@@ -91,6 +118,15 @@ func (c *RollbackCommand) Run(args []string) int {
 		return 1
 	}
 
+	if c.restoreDB {
+		c.UI.Info(fmt.Sprintf("Restoring %s (%s) database snapshot...", siteName, environment))
+
+		if err := restoreDBSnapshot(c.Trellis.Runner, c.UI, environment, credentials, siteName, snapshot); err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+	}
+
 	return 0
 }
 
@@ -116,14 +152,21 @@ Rollback a specific release:
 
   $ trellis rollback --release=12345678901234 production example.com
 
+Rollback and restore the database snapshot created by 'trellis deploy --db-snapshot':
+
+  $ trellis rollback --restore-db production
+
+The rollback is aborted if the release being rolled back to has no database snapshot.
+
 Arguments:
   ENVIRONMENT Name of environment (ie: production)
   SITE        Name of the site (ie: example.com)
 
 Options:
-      --release  Name of release to rollback instead of latest
-      --verbose  Enable Ansible's verbose mode
-  -h, --help     show this help
+      --release     Name of release to rollback instead of latest
+      --restore-db  Restore the database snapshot created when deploying over the release rolled back to
+      --verbose     Enable Ansible's verbose mode
+  -h, --help        show this help
 `
 
 	return strings.TrimSpace(helpText)
@@ -135,7 +178,76 @@ func (c *RollbackCommand) AutocompleteArgs() complete.Predictor {
 
 func (c *RollbackCommand) AutocompleteFlags() complete.Flags {
 	return complete.Flags{
-		"--release": complete.PredictNothing,
-		"--verbose": complete.PredictNothing,
+		"--release":    complete.PredictNothing,
+		"--restore-db": complete.PredictNothing,
+		"--verbose":    complete.PredictNothing,
+	}
+}
+
+/*
+Finds the newest database snapshot of the release being rolled back to before
+rolling back so there's a snapshot to restore afterwards. Deploys with
+--db-snapshot tie snapshots to the release which was current before deploying.
+*/
+func (c *RollbackCommand) findDBSnapshot(environment string, siteName string) (credentials db_opener.DBCredentials, snapshot string, err error) {
+	inventoryHost, err := dbInventoryHost(c.Trellis, environment, siteName, "")
+	if err != nil {
+		return credentials, "", err
 	}
+
+	credentials, err = fetchDBCredentials(c.Trellis, c.UI, c.dbPlaybook, environment, siteName, inventoryHost)
+	if err != nil {
+		return credentials, "", err
+	}
+
+	// Nothing is listed during a dry run; the newest snapshot of the current release is restored
+	if command.IsDryRun(c.Trellis.Runner) {
+		return credentials, "", nil
+	}
+
+	release, err := c.targetRelease(environment, credentials, siteName)
+	if err != nil {
+		return credentials, "", err
+	}
+
+	snapshots, err := listDBSnapshots(c.Trellis.Runner, environment, credentials, siteName)
+	if err != nil {
+		return credentials, "", err
+	}
+
+	found, ok := findReleaseDBSnapshot(snapshots, release)
+	if !ok {
+		return credentials, "", fmt.Errorf("Error: no database snapshot found for release %s. Not rolling back.", release)
+	}
+
+	return credentials, found.Name, nil
+}
+
+// Returns the release being rolled back to: --release or the one before the current release.
+func (c *RollbackCommand) targetRelease(environment string, credentials db_opener.DBCredentials, siteName string) (string, error) {
+	if c.release != "" {
+		return c.release, nil
+	}
+
+	remoteCommand := fmt.Sprintf(
+		`cd /srv/www/%[1]s/releases; current=$(basename "$(readlink -f /srv/www/%[1]s/current)"); `+
+			`previous=$(ls -1 | sort | grep -x -B1 -- "$current" | head -n 1); `+
+			`if [ "$previous" != "$current" ]; then echo "$previous"; fi`,
+		siteName,
+	)
+
+	list := command.New(c.Trellis.Runner).Cmd("ssh", dbSshArgs(environment, credentials, remoteCommand))
+	list.Stdin = nil
+
+	output, err := list.Output()
+	if err != nil {
+		return "", fmt.Errorf("Error: could not find the release to roll back to: %v", err)
+	}
+
+	release := strings.TrimSpace(string(output))
+	if release == "" {
+		return "", fmt.Errorf("Error: no previous release found to roll back to")
+	}
+
+	return release, nil
 }
@@ -122,7 +122,7 @@ func TestRollbackRun(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			ui := cli.NewMockUi()
-			defer MockUiExec(t, ui)()
+			MockUiExec(ui, trellis)
 
 			rollbackCommand := NewRollbackCommand(ui, trellis)
 
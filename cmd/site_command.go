@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/mattn/go-isatty"
+	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+/*
+Runs a command in a site's directory on a server (ie: /srv/www/<site>/current).
+
+Development runs inside the VM when a VM manager is used (the site's local path
+is mounted at /srv/www/<site>/current). There's a single VM for all sites; only
+dir selects the site. Otherwise the command runs over SSH as
+the web user. A TTY is allocated for SSH when tty is true.
+*/
+func runSiteCommand(t *trellis.Trellis, ui cli.Ui, environment string, siteName string, host string, dir string, args []string, tty bool) error {
+	if environment == "development" {
+		if manager, err := newVmManager(t, ui); err == nil && findDevInventory(t, ui) == manager.InventoryPath() {
+			instanceName, err := vmInstanceName(t)
+			if err != nil {
+				return err
+			}
+
+			return manager.OpenShell(instanceName, dir, args)
+		}
+		// Without a VM manager (eg: Vagrant), fall back to SSH
+	}
+
+	target, err := selectSshTarget(t.SshTargets(environment, siteName, "web"), host)
+	if err != nil {
+		return err
+	}
+
+	quotedArgs := []string{}
+	for _, arg := range args {
+		quotedArgs = append(quotedArgs, shellQuote(arg))
+	}
+
+	sshArgs := []string{}
+	if tty {
+		sshArgs = append(sshArgs, "-t")
+	}
+
+	sshArgs = append(sshArgs, target.SshArgs()...)
+	sshArgs = append(sshArgs, fmt.Sprintf("cd %s && %s", shellQuote(dir), strings.Join(quotedArgs, " ")))
+
+	return command.New(
+		t.Runner,
+		command.WithTermOutput(),
+		command.WithLogging(ui),
+	).Cmd("ssh", sshArgs).Run()
+}
+
+/*
+Returns the exit code of a command run with runSiteCommand so it can be passed
+through. Errors which didn't come from the command itself are output and
+result in 1.
+*/
+func siteCommandExitCode(ui cli.Ui, name string, err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *command.ExitError
+	if errors.As(err, &exitErr) {
+		return exitErr.ExitCode()
+	}
+
+	ui.Error(fmt.Sprintf("Error running %s: %s", name, err))
+	return 1
+}
+
+// Returns whether a TTY should be allocated for an interactive remote command.
+func isInteractiveTerminal() bool {
+	return isatty.IsTerminal(os.Stdin.Fd()) && isatty.IsTerminal(os.Stdout.Fd())
+}
+
+/*
+Parses flags which may appear before or after positional arguments, stopping
+at the -- separator. Returns the positional arguments and the arguments after
+the separator.
+*/
+func parseSiteCommandArgs(flags *flag.FlagSet, args []string) (positional []string, passthrough []string, err error) {
+	for i, arg := range args {
+		if arg == "--" {
+			passthrough = args[i+1:]
+			args = args[:i]
+			break
+		}
+	}
+
+	positional = []string{}
+
+	for {
+		if err := flags.Parse(args); err != nil {
+			return nil, nil, err
+		}
+
+		args = flags.Args()
+		if len(args) == 0 {
+			break
+		}
+
+		positional = append(positional, args[0])
+		args = args[1:]
+	}
+
+	return positional, passthrough, nil
+}
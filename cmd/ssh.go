@@ -5,9 +5,11 @@ import (
 	"fmt"
 	"strings"
 
+	"github.com/manifoldco/promptui"
 	"github.com/mitchellh/cli"
 	"github.com/posener/complete"
 	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/pkg/inventory"
 	"github.com/roots/trellis-cli/trellis"
 )
 
@@ -22,6 +24,7 @@ type SshCommand struct {
 	Trellis *trellis.Trellis
 	flags   *flag.FlagSet
 	user    string
+	host    string
 }
 
 func (c *SshCommand) init() {
@@ -29,6 +32,7 @@ func (c *SshCommand) init() {
 	c.flags.Usage = func() { c.UI.Info(c.Help()) }
 	c.flags.StringVar(&c.user, "u", "", "User to connect as")
 	c.flags.StringVar(&c.user, "user", "", "User to connect as")
+	c.flags.StringVar(&c.host, "host", "", "Inventory host to connect to when an environment has multiple servers")
 }
 
 func (c *SshCommand) Run(args []string) int {
@@ -58,6 +62,11 @@ func (c *SshCommand) Run(args []string) int {
 		return 1
 	}
 
+	if err := c.Trellis.ApplyEnvironmentConfig(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
 	siteNameArg := ""
 	if len(args) == 2 {
 		siteNameArg = args[1]
@@ -68,12 +77,17 @@ func (c *SshCommand) Run(args []string) int {
 		return 1
 	}
 
-	sshHost := c.Trellis.SshHost(environment, siteName, c.user)
+	target, err := selectSshTarget(c.Trellis.SshTargets(environment, siteName, c.user), c.host)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
 
-	ssh := command.WithOptions(
+	ssh := command.New(
+		c.Trellis.Runner,
 		command.WithTermOutput(),
 		command.WithLogging(c.UI),
-	).Cmd("ssh", []string{sshHost})
+	).Cmd("ssh", target.SshArgs())
 
 	if err := ssh.Run(); err != nil {
 		c.UI.Error(fmt.Sprintf("Error running ssh: %s", err))
@@ -91,7 +105,14 @@ func (c *SshCommand) Help() string {
 	helpText := `
 Usage: trellis ssh [options] ENVIRONMENT [SITE]
 
-Connects to the main canonical host via SSH for the specified environment.
+Connects to a server via SSH for the specified environment.
+
+The server is resolved from the environment's inventory file (eg: 'hosts/production')
+including any ansible_host, ansible_port, and ansible_user variables.
+If the inventory has no hosts, the site's main canonical host is used instead.
+
+When an environment has multiple web servers, you'll be prompted to select one
+unless the --host option is used.
 
 Connects to main production site host:
 
@@ -105,6 +126,10 @@ Connects to production as web user:
 
   $ trellis ssh -u web production
 
+Connects to a specific server in a multi-server environment:
+
+  $ trellis ssh --host web2 production
+
 Connects to main development site host:
 
   $ trellis ssh development
@@ -116,6 +141,7 @@ Arguments:
   SITE        Name of the site (ie: example.com)
 
 Options:
+      --host  Inventory host to connect to when an environment has multiple servers
   -u, --user  User to connect as
   -h, --help  Show this help
 `
@@ -129,6 +155,48 @@ func (c *SshCommand) AutocompleteArgs() complete.Predictor {
 
 func (c *SshCommand) AutocompleteFlags() complete.Flags {
 	return complete.Flags{
+		"--host": complete.PredictNothing,
 		"--user": complete.PredictNothing,
 	}
 }
+
+/*
+Selects the SSH target to connect to. If host is set, it must match a target's
+inventory name or address. Otherwise a single target is used as is, and the
+user is prompted to select one when there are multiple.
+*/
+func selectSshTarget(targets []inventory.Connection, host string) (inventory.Connection, error) {
+	if host != "" {
+		for _, target := range targets {
+			if target.Name == host || target.Host == host {
+				return target, nil
+			}
+		}
+
+		return inventory.Connection{}, fmt.Errorf("Error: host %s not found in the environment's inventory", host)
+	}
+
+	if len(targets) == 1 {
+		return targets[0], nil
+	}
+
+	tpl := `{{ .Name }} [{{ .String | faint }}]`
+
+	prompt := promptui.Select{
+		Label: "Select server",
+		Items: targets,
+		Size:  len(targets),
+		Templates: &promptui.SelectTemplates{
+			Active:   fmt.Sprintf("%s %s", promptui.IconSelect, tpl),
+			Inactive: tpl,
+			Selected: fmt.Sprintf(`{{ "%s" | green }} %s`, promptui.IconGood, tpl),
+		},
+	}
+
+	i, _, err := prompt.Run()
+	if err != nil {
+		return inventory.Connection{}, err
+	}
+
+	return targets[i], nil
+}
@@ -65,9 +65,9 @@ func TestSshRunValidations(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			ui := cli.NewMockUi()
-			defer MockUiExec(t, ui)()
 
 			trellis := trellis.NewMockTrellis(tc.projectDetected)
+			MockUiExec(ui, trellis)
 			sshCommand := NewSshCommand(ui, trellis)
 
 			code := sshCommand.Run(tc.args)
@@ -98,7 +98,7 @@ func TestSshRun(t *testing.T) {
 		{
 			"non_development",
 			[]string{"production"},
-			"ssh admin@example.com",
+			"ssh admin@1.2.3.4",
 			0,
 		},
 		{
@@ -110,7 +110,7 @@ func TestSshRun(t *testing.T) {
 		{
 			"production_with_user_flag",
 			[]string{"-u=web", "production"},
-			"ssh web@example.com",
+			"ssh web@1.2.3.4",
 			0,
 		},
 	}
@@ -118,7 +118,7 @@ func TestSshRun(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			ui := cli.NewMockUi()
-			defer MockUiExec(t, ui)()
+			MockUiExec(ui, trellis)
 
 			sshCommand := NewSshCommand(ui, trellis)
 			code := sshCommand.Run(tc.args)
@@ -71,7 +71,7 @@ func (c *UpCommand) Run(args []string) int {
 		vagrantArgs = append(vagrantArgs, "--debug")
 	}
 
-	vagrantUp := command.WithOptions(command.WithTermOutput(), command.WithLogging(c.UI)).Cmd("vagrant", vagrantArgs)
+	vagrantUp := command.New(c.Trellis.Runner, command.WithTermOutput(), command.WithLogging(c.UI)).Cmd("vagrant", vagrantArgs)
 
 	env := os.Environ()
 	// To allow moc.CmdCommand injects its environment variables.
@@ -79,7 +79,7 @@ func TestUpRun(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			ui := cli.NewMockUi()
-			defer MockUiExec(t, ui)()
+			MockUiExec(ui, trellis)
 
 			upCommand := NewUpCommand(ui, trellis)
 			code := upCommand.Run(tc.args)
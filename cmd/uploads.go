@@ -0,0 +1,335 @@
+package cmd
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/pkg/flags"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func NewUploadsPullCommand(ui cli.Ui, trellis *trellis.Trellis) *UploadsSyncCommand {
+	c := &UploadsSyncCommand{UI: ui, Trellis: trellis, direction: "pull"}
+	c.init()
+	return c
+}
+
+func NewUploadsPushCommand(ui cli.Ui, trellis *trellis.Trellis) *UploadsSyncCommand {
+	c := &UploadsSyncCommand{UI: ui, Trellis: trellis, direction: "push"}
+	c.init()
+	return c
+}
+
+/*
+Syncs a site's uploads between development and a remote environment with rsync
+(uploads pull and uploads push). Both directions work the same way; they only
+differ in their help text.
+*/
+type UploadsSyncCommand struct {
+	UI        cli.Ui
+	Trellis   *trellis.Trellis
+	flags     *flag.FlagSet
+	direction string
+	dryRun    bool
+	delete    bool
+	host      string
+	include   flags.StringSliceVar
+	exclude   flags.StringSliceVar
+}
+
+type rsyncStats struct {
+	Files string
+	Size  string
+}
+
+var (
+	rsyncFilesPattern = regexp.MustCompile(`Number of (?:regular )?files transferred: ([\d,]+)`)
+	rsyncSizePattern  = regexp.MustCompile(`Total transferred file size: ([^\s]+(?: bytes)?)`)
+)
+
+func (c *UploadsSyncCommand) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Usage = func() { c.UI.Info(c.Help()) }
+	c.flags.BoolVar(&c.dryRun, "dry-run", false, "Show what would be transferred without making any changes")
+	c.flags.BoolVar(&c.delete, "delete", false, "Delete files in TARGET which don't exist in SOURCE")
+	c.flags.StringVar(&c.host, "host", "", "Inventory host to connect to when an environment has multiple servers")
+	c.flags.Var(&c.include, "include", "Include files matching pattern (can be used multiple times)")
+	c.flags.Var(&c.exclude, "exclude", "Exclude files matching pattern (can be used multiple times)")
+}
+
+func (c *UploadsSyncCommand) Run(args []string) int {
+	if err := c.Trellis.LoadProject(); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = c.flags.Args()
+
+	commandArgumentValidator := &CommandArgumentValidator{required: 2, optional: 1}
+	if err := commandArgumentValidator.validate(args); err != nil {
+		c.UI.Error(err.Error())
+		c.UI.Output(c.Help())
+		return 1
+	}
+
+	source := args[0]
+	target := args[1]
+
+	for _, environment := range []string{source, target} {
+		if err := c.Trellis.ValidateEnvironment(environment); err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+	}
+
+	if source == target {
+		c.UI.Error("Error: SOURCE and TARGET environments must be different")
+		return 1
+	}
+
+	if source != "development" && target != "development" {
+		c.UI.Error("Error: one of SOURCE or TARGET must be the development environment")
+		return 1
+	}
+
+	siteNameArg := c.flags.Arg(2)
+	siteName, siteNameErr := c.Trellis.FindSiteNameFromEnvironment(source, siteNameArg)
+	if siteNameErr != nil {
+		c.UI.Error(siteNameErr.Error())
+		return 1
+	}
+
+	if _, err := c.Trellis.FindSiteNameFromEnvironment(target, siteName); err != nil {
+		c.UI.Error(fmt.Sprintf("Error: %s does not exist in the %s environment", siteName, target))
+		return 1
+	}
+
+	remote := source
+	if source == "development" {
+		remote = target
+	}
+
+	if err := c.Trellis.ApplyEnvironmentConfig(remote); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	localPath := filepath.Join(c.Trellis.SiteFromEnvironmentAndName("development", siteName).AbsLocalPath, "web", "app", "uploads")
+
+	if source == "development" {
+		if _, err := os.Stat(localPath); err != nil {
+			c.UI.Error(fmt.Sprintf("Error: local uploads directory %s not found", localPath))
+			return 1
+		}
+	} else if !c.dryRun {
+		if err := os.MkdirAll(localPath, 0755); err != nil {
+			c.UI.Error(fmt.Sprintf("Error: could not create local uploads directory %s: %v", localPath, err))
+			return 1
+		}
+	}
+
+	sshTarget, err := selectSshTarget(c.Trellis.SshTargets(remote, siteName, "web"), c.host)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	remotePath := fmt.Sprintf("%s:/srv/www/%s/shared/uploads/", sshTarget.String(), siteName)
+	localPath = localPath + string(filepath.Separator)
+
+	rsh := "ssh"
+	if sshTarget.Port != 0 && sshTarget.Port != 22 {
+		rsh = fmt.Sprintf("ssh -p %d", sshTarget.Port)
+	}
+
+	rsyncArgs := c.rsyncArgs(rsh)
+
+	if source == "development" {
+		rsyncArgs = append(rsyncArgs, localPath, remotePath)
+	} else {
+		rsyncArgs = append(rsyncArgs, remotePath, localPath)
+	}
+
+	var output bytes.Buffer
+	rsync := command.New(
+		c.Trellis.Runner,
+		command.WithTermOutput(),
+		command.WithLogging(c.UI),
+	).Cmd("rsync", rsyncArgs)
+
+	if rsync.Stdout != nil {
+		rsync.Stdout = io.MultiWriter(rsync.Stdout, &output)
+	} else {
+		rsync.Stdout = &output
+	}
+
+	if err := rsync.Run(); err != nil {
+		c.UI.Error(fmt.Sprintf("Error running rsync: %s", err))
+		return 1
+	}
+
+	stats := parseRsyncStats(output.String())
+	summary := fmt.Sprintf("%s files (%s)", stats.Files, stats.Size)
+
+	if c.dryRun {
+		c.UI.Info(color.YellowString(fmt.Sprintf("[DRY RUN] Would transfer %s of %s uploads from %s to %s", summary, siteName, source, target)))
+	} else {
+		c.UI.Info(color.GreenString(fmt.Sprintf("[✓] Transferred %s of %s uploads from %s to %s", summary, siteName, source, target)))
+	}
+
+	return 0
+}
+
+func (c *UploadsSyncCommand) Synopsis() string {
+	if c.direction == "push" {
+		return "Pushes a site's uploads to a remote environment"
+	}
+
+	return "Pulls a site's uploads from a remote environment"
+}
+
+func (c *UploadsSyncCommand) Help() string {
+	helpText := `
+Usage: trellis uploads pull [options] SOURCE TARGET [SITE]
+
+Pulls a site's uploads from the SOURCE environment into the TARGET environment with rsync.
+
+Remote uploads are stored in /srv/www/<site>/shared/uploads. Development uploads
+are stored in the site's local path (eg: ../site/web/app/uploads) which is shared
+with the development VM. One of SOURCE or TARGET must be development.
+
+The remote server is resolved the same way as 'trellis ssh' (connecting as the web user).
+
+Pull production uploads into development:
+
+  $ trellis uploads pull production development
+
+Preview which files would be pulled without transferring anything:
+
+  $ trellis uploads pull --dry-run production development
+
+Only pull images from 2024:
+
+  $ trellis uploads pull --include="2024/***" --exclude="*" production development
+
+Arguments:
+  SOURCE Name of environment to copy uploads from (ie: production)
+  TARGET Name of environment to copy uploads to (ie: development)
+  SITE   Name of the site (ie: example.com); Optional when only single site exist in the environment
+
+Options:
+      --delete   Delete files in TARGET which don't exist in SOURCE
+      --dry-run  Show what would be transferred without making any changes
+      --exclude  Exclude files matching pattern (can be used multiple times)
+      --host     Inventory host to connect to when an environment has multiple servers
+      --include  Include files matching pattern (can be used multiple times)
+  -h, --help     show this help
+`
+
+	if c.direction == "push" {
+		helpText = `
+Usage: trellis uploads push [options] SOURCE TARGET [SITE]
+
+Pushes a site's uploads from the SOURCE environment to the TARGET environment with rsync.
+
+Remote uploads are stored in /srv/www/<site>/shared/uploads. Development uploads
+are stored in the site's local path (eg: ../site/web/app/uploads) which is shared
+with the development VM. One of SOURCE or TARGET must be development.
+
+The remote server is resolved the same way as 'trellis ssh' (connecting as the web user).
+
+Push development uploads to staging:
+
+  $ trellis uploads push development staging
+
+Push development uploads to production and delete remote files which don't exist locally:
+
+  $ trellis uploads push --delete development production
+
+Push everything except PDFs:
+
+  $ trellis uploads push --exclude="*.pdf" development production
+
+Arguments:
+  SOURCE Name of environment to copy uploads from (ie: development)
+  TARGET Name of environment to copy uploads to (ie: production)
+  SITE   Name of the site (ie: example.com); Optional when only single site exist in the environment
+
+Options:
+      --delete   Delete files in TARGET which don't exist in SOURCE
+      --dry-run  Show what would be transferred without making any changes
+      --exclude  Exclude files matching pattern (can be used multiple times)
+      --host     Inventory host to connect to when an environment has multiple servers
+      --include  Include files matching pattern (can be used multiple times)
+  -h, --help     show this help
+`
+	}
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *UploadsSyncCommand) AutocompleteArgs() complete.Predictor {
+	return c.Trellis.PredictEnvironment(c.flags)
+}
+
+func (c *UploadsSyncCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"--delete":  complete.PredictNothing,
+		"--dry-run": complete.PredictNothing,
+		"--exclude": complete.PredictNothing,
+		"--host":    complete.PredictNothing,
+		"--include": complete.PredictNothing,
+	}
+}
+
+func (c *UploadsSyncCommand) rsyncArgs(rsh string) []string {
+	args := []string{"-az", "--human-readable", "--stats", "-e", rsh}
+
+	if c.dryRun {
+		args = append(args, "--dry-run", "--itemize-changes")
+	}
+
+	if c.delete {
+		args = append(args, "--delete")
+	}
+
+	// rsync applies filter rules in order, so includes must come before excludes
+	for _, pattern := range c.include {
+		args = append(args, "--include="+pattern)
+	}
+
+	for _, pattern := range c.exclude {
+		args = append(args, "--exclude="+pattern)
+	}
+
+	return args
+}
+
+// Parses the number of transferred files and their total size from rsync's --stats output.
+func parseRsyncStats(output string) rsyncStats {
+	stats := rsyncStats{Files: "0", Size: "0"}
+
+	if match := rsyncFilesPattern.FindStringSubmatch(output); match != nil {
+		stats.Files = match[1]
+	}
+
+	if match := rsyncSizePattern.FindStringSubmatch(output); match != nil {
+		stats.Size = match[1]
+	}
+
+	return stats
+}
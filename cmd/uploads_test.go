@@ -0,0 +1,150 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func TestUploadsSyncRunValidations(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+
+	cases := []struct {
+		name            string
+		projectDetected bool
+		args            []string
+		out             string
+		code            int
+	}{
+		{
+			"no_project",
+			false,
+			nil,
+			"No Trellis project detected",
+			1,
+		},
+		{
+			"no_args",
+			true,
+			[]string{"production"},
+			"Error: missing arguments (expected between 2 and 3, got 1)",
+			1,
+		},
+		{
+			"invalid_env",
+			true,
+			[]string{"foo", "development"},
+			"Error: foo is not a valid environment",
+			1,
+		},
+		{
+			"same_environment",
+			true,
+			[]string{"development", "development"},
+			"Error: SOURCE and TARGET environments must be different",
+			1,
+		},
+		{
+			"no_development",
+			true,
+			[]string{"production", "valet-link"},
+			"Error: one of SOURCE or TARGET must be the development environment",
+			1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			trellis := trellis.NewMockTrellis(tc.projectDetected)
+			uploadsCommand := NewUploadsPullCommand(ui, trellis)
+
+			code := uploadsCommand.Run(tc.args)
+
+			if code != tc.code {
+				t.Errorf("expected code %d to be %d", code, tc.code)
+			}
+
+			combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+
+			if !strings.Contains(combined, tc.out) {
+				t.Errorf("expected output %q to contain %q", combined, tc.out)
+			}
+		})
+	}
+}
+
+func TestUploadsSyncRun(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+	project := trellis.NewTrellis()
+
+	if err := os.MkdirAll(filepath.Join("..", "site", "web", "app", "uploads"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	cases := []struct {
+		name       string
+		newCommand func(cli.Ui, *trellis.Trellis) *UploadsSyncCommand
+		args       []string
+		out        string
+	}{
+		{
+			"pull",
+			NewUploadsPullCommand,
+			[]string{"--dry-run", "--include=2024/***", "--exclude=*", "production", "development"},
+			"rsync -az --human-readable --stats -e ssh --dry-run --itemize-changes --include=2024/*** --exclude=* web@1.2.3.4:/srv/www/example.com/shared/uploads/",
+		},
+		{
+			"push",
+			NewUploadsPushCommand,
+			[]string{"--delete", "development", "production"},
+			"web/app/uploads/ web@1.2.3.4:/srv/www/example.com/shared/uploads/",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			MockUiExec(ui, project)
+
+			uploadsCommand := tc.newCommand(ui, project)
+			code := uploadsCommand.Run(tc.args)
+
+			if code != 0 {
+				t.Errorf("expected code %d to be 0", code)
+			}
+
+			combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+
+			if !strings.Contains(combined, tc.out) {
+				t.Errorf("expected output %q to contain %q", combined, tc.out)
+			}
+		})
+	}
+}
+
+func TestParseRsyncStats(t *testing.T) {
+	output := `
+Number of files: 1,204 (reg: 1,150, dir: 54)
+Number of created files: 12 (reg: 12)
+Number of regular files transferred: 12
+Total file size: 412.53M bytes
+Total transferred file size: 3.41M bytes
+`
+
+	stats := parseRsyncStats(output)
+
+	if stats.Files != "12" || stats.Size != "3.41M bytes" {
+		t.Errorf("expected stats {12 3.41M bytes}, got %+v", stats)
+	}
+
+	stats = parseRsyncStats("")
+
+	if stats.Files != "0" || stats.Size != "0" {
+		t.Errorf("expected empty stats, got %+v", stats)
+	}
+}
@@ -41,6 +41,11 @@ func (c *ValetLinkCommand) Run(args []string) int {
 		return 1
 	}
 
+	if err := c.Trellis.ApplyEnvironmentConfig(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
 	config, _ := c.Trellis.Environments[environment]
 
 	c.UI.Info(fmt.Sprintf("Linking environment %s...", environment))
@@ -58,7 +63,7 @@ func (c *ValetLinkCommand) Run(args []string) int {
 		}
 		valetArgs = append(valetArgs, app)
 
-		valetLink := command.WithOptions(command.WithTermOutput(), command.WithLogging(c.UI)).Cmd("valet", valetArgs)
+		valetLink := command.New(c.Trellis.Runner, command.WithTermOutput(), command.WithLogging(c.UI)).Cmd("valet", valetArgs)
 		valetLink.Dir = site.LocalPath
 
 		err := valetLink.Run()
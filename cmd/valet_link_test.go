@@ -172,7 +172,7 @@ func TestValetLinkRun(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			ui := cli.NewMockUi()
-			defer MockUiExec(t, ui)()
+			MockUiExec(ui, trellis)
 
 			valetLinkCommand := ValetLinkCommand{ui, trellis}
 			code := valetLinkCommand.Run([]string{"valet-link"})
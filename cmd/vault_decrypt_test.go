@@ -103,7 +103,7 @@ func TestVaultDecryptRun(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			ui := cli.NewMockUi()
-			defer MockUiExec(t, ui)()
+			MockUiExec(ui, trellisProject)
 
 			vaultDecryptCommand := NewVaultDecryptCommand(ui, trellisProject)
 			code := vaultDecryptCommand.Run(tc.args)
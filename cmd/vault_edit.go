@@ -82,7 +82,7 @@ func (c *VaultEditCommand) Run(args []string) int {
 	vaultArgs := []string{"edit"}
 	vaultArgs = append(vaultArgs, c.files...)
 
-	vaultEdit := command.WithOptions(command.WithTermOutput(), command.WithLogging(c.UI)).Cmd("ansible-vault", vaultArgs)
+	vaultEdit := command.New(c.Trellis.Runner, command.WithTermOutput(), command.WithLogging(c.UI)).Cmd("ansible-vault", vaultArgs)
 	err := vaultEdit.Run()
 
 	if err != nil {
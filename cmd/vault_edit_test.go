@@ -75,7 +75,7 @@ func TestVaultEditRun(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			ui := cli.NewMockUi()
-			defer MockUiExec(t, ui)()
+			MockUiExec(ui, trellis)
 
 			vaultEditCommand := NewVaultEditCommand(ui, trellis)
 			code := vaultEditCommand.Run(tc.args)
@@ -67,6 +67,11 @@ func (c *VaultEncryptCommand) Run(args []string) int {
 			return 1
 		}
 
+		if err := c.Trellis.ApplyEnvironmentConfig(environment); err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+
 		if len(c.files) > 0 {
 			c.UI.Error("Error: the file option can't be used together with the ENVIRONMENT argument\n")
 			c.UI.Output(c.Help())
@@ -113,7 +118,8 @@ func (c *VaultEncryptCommand) Run(args []string) int {
 	vaultArgs = append(vaultArgs, filesToEncrypt...)
 
 	mockUi := cli.NewMockUi()
-	vaultEncrypt := command.WithOptions(
+	vaultEncrypt := command.New(
+		c.Trellis.Runner,
 		command.WithUiOutput(mockUi),
 		command.WithLogging(c.UI),
 	).Cmd("ansible-vault", vaultArgs)
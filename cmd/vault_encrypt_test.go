@@ -109,7 +109,7 @@ func TestVaultEncryptRun(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			ui := cli.NewMockUi()
-			defer MockUiExec(t, ui)()
+			MockUiExec(ui, trellisProject)
 
 			vaultEncryptCommand := NewVaultEncryptCommand(ui, trellisProject)
 			code := vaultEncryptCommand.Run(tc.args)
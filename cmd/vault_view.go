@@ -67,6 +67,11 @@ func (c *VaultViewCommand) Run(args []string) int {
 			return 1
 		}
 
+		if err := c.Trellis.ApplyEnvironmentConfig(environment); err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+
 		if len(c.files) > 0 {
 			c.UI.Error("Error: the file option can't be used together with the ENVIRONMENT argument\n")
 			c.UI.Output(c.Help())
@@ -104,7 +109,7 @@ func (c *VaultViewCommand) Run(args []string) int {
 	}
 
 	vaultArgs = append(vaultArgs, c.files...)
-	vaultView := command.WithOptions(command.WithTermOutput(), command.WithLogging(c.UI)).Cmd("ansible-vault", vaultArgs)
+	vaultView := command.New(c.Trellis.Runner, command.WithTermOutput(), command.WithLogging(c.UI)).Cmd("ansible-vault", vaultArgs)
 	_ = vaultView.Run()
 
 	return 0
@@ -93,7 +93,7 @@ func TestVaultViewRun(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			ui := cli.NewMockUi()
-			defer MockUiExec(t, ui)()
+			MockUiExec(ui, trellis)
 
 			vaultViewCommand := NewVaultViewCommand(ui, trellis)
 			code := vaultViewCommand.Run(tc.args)
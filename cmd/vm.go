@@ -7,6 +7,7 @@ import (
 	"runtime"
 
 	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/command"
 	"github.com/roots/trellis-cli/pkg/lima"
 	"github.com/roots/trellis-cli/pkg/vm"
 	"github.com/roots/trellis-cli/trellis"
@@ -32,12 +33,19 @@ func newVmManager(trellis *trellis.Trellis, ui cli.Ui) (manager vm.Manager, err
 	return nil, fmt.Errorf("VM manager not found")
 }
 
+// Returns the name of the development VM, which is named after the main development site.
+func vmInstanceName(trellis *trellis.Trellis) (string, error) {
+	siteName, _, err := trellis.MainSiteFromEnvironment("development")
+	return siteName, err
+}
+
 func findDevInventory(trellis *trellis.Trellis, ui cli.Ui) string {
 	manager, managerErr := newVmManager(trellis, ui)
 
 	if managerErr == nil {
 		_, vmInventoryErr := os.Stat(manager.InventoryPath())
-		if vmInventoryErr == nil {
+		// A dry run of `vm start` only plans writing the inventory
+		if vmInventoryErr == nil || command.IsDryRun(trellis.Runner) {
 			return manager.InventoryPath()
 		}
 	}
@@ -6,6 +6,7 @@ import (
 	"strings"
 
 	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/pkg/events"
 	"github.com/roots/trellis-cli/pkg/vm"
 	"github.com/roots/trellis-cli/trellis"
 )
@@ -61,7 +62,26 @@ func (c *VmStartCommand) Run(args []string) int {
 		return 1
 	}
 
-	err = manager.StartInstance(siteName)
+	if err := emitEvent(c.UI, c.Trellis, events.Event{Name: events.PreVmStart, Environment: "development", Site: siteName}); err != nil {
+		c.UI.Error(err.Error())
+		c.UI.Error("Aborting VM start since a pre-vm-start hook failed.")
+		return 1
+	}
+
+	code := c.start(manager, siteName)
+
+	status := events.StatusSuccess
+	if code != 0 {
+		status = events.StatusFailure
+	}
+
+	emitEvent(c.UI, c.Trellis, events.Event{Name: events.PostVmStart, Environment: "development", Site: siteName, Status: status})
+
+	return code
+}
+
+func (c *VmStartCommand) start(manager vm.Manager, siteName string) int {
+	err := manager.StartInstance(siteName)
 	if err == nil {
 		c.printInstanceInfo()
 		return 0
@@ -124,6 +144,7 @@ func (c *VmStartCommand) printInstanceInfo() {
 Your Trellis VM is ready to use!
 
 * Composer and WP-CLI commands need to be run on the virtual machine for any post-provision modifications.
+  Use 'trellis composer -- <args>' and 'trellis wp development -- <args>' to run them from your machine.
 * You can SSH into the machine with 'trellis vm shell'
 * Then navigate to your WordPress sites at '/srv/www'`)
 }
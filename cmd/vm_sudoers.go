@@ -30,7 +30,7 @@ func (c *VmSudoersCommand) Run(args []string) int {
 		return 1
 	}
 
-	hostResolver := vm.NewHostsFileResolver([]string{})
+	hostResolver := vm.NewHostsFileResolver([]string{}, c.Trellis.Runner)
 	cmd := hostResolver.SudoersCommand()
 
 	c.UI.Info(fmt.Sprintf("%%staff ALL=(root:wheel) NOPASSWD:NOSETENV: %s", strings.Join(cmd, " ")))
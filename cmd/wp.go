@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/fatih/color"
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func NewWpCommand(ui cli.Ui, trellis *trellis.Trellis) *WpCommand {
+	c := &WpCommand{UI: ui, Trellis: trellis}
+	c.init()
+	return c
+}
+
+type WpCommand struct {
+	UI       cli.Ui
+	Trellis  *trellis.Trellis
+	flags    *flag.FlagSet
+	allSites bool
+	host     string
+	site     string
+}
+
+func (c *WpCommand) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Usage = func() { c.UI.Info(c.Help()) }
+	c.flags.BoolVar(&c.allSites, "all-sites", false, "Run the command for every site in the environment")
+	c.flags.StringVar(&c.host, "host", "", "Inventory host to connect to when an environment has multiple servers")
+	c.flags.StringVar(&c.site, "site", "", "Name of the site (ie: example.com); Optional when only single site exist in the environment")
+}
+
+func (c *WpCommand) Run(args []string) int {
+	if err := c.Trellis.LoadProject(); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	args, wpArgs, err := parseSiteCommandArgs(c.flags, args)
+	if err != nil {
+		return 1
+	}
+
+	commandArgumentValidator := &CommandArgumentValidator{required: 1, optional: 0}
+	if err := commandArgumentValidator.validate(args); err != nil {
+		c.UI.Error(err.Error())
+		c.UI.Output(c.Help())
+		return 1
+	}
+
+	environment := args[0]
+
+	if err := c.Trellis.ValidateEnvironment(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if err := c.Trellis.ApplyEnvironmentConfig(environment); err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	if c.allSites && c.site != "" {
+		c.UI.Error("Error: --all-sites and --site can't be used together")
+		return 1
+	}
+
+	siteNames := c.Trellis.SiteNamesFromEnvironment(environment)
+
+	if !c.allSites {
+		siteName, err := c.Trellis.FindSiteNameFromEnvironment(environment, c.site)
+		if err != nil {
+			c.UI.Error(err.Error())
+			return 1
+		}
+
+		siteNames = []string{siteName}
+	}
+
+	code := 0
+
+	for _, siteName := range siteNames {
+		if c.allSites {
+			c.UI.Info(color.New(color.Bold).Sprintf("==> %s", siteName))
+		}
+
+		dir := fmt.Sprintf("/srv/www/%s/current", siteName)
+		wp := append([]string{"wp", fmt.Sprintf("--path=%s/web/wp", dir)}, wpArgs...)
+
+		err := runSiteCommand(c.Trellis, c.UI, environment, siteName, c.host, dir, wp, !c.allSites && isInteractiveTerminal())
+
+		if siteCode := siteCommandExitCode(c.UI, "wp", err); siteCode != 0 {
+			code = siteCode
+		}
+	}
+
+	return code
+}
+
+func (c *WpCommand) Synopsis() string {
+	return "Runs WP-CLI commands for a site"
+}
+
+func (c *WpCommand) Help() string {
+	helpText := `
+Usage: trellis wp [options] ENVIRONMENT [-- WP_ARGS]
+
+Runs a WP-CLI command for a site in the specified environment.
+
+The command runs in the site's current release directory (/srv/www/<site>/current)
+with WP-CLI's --path set to its WordPress install (web/wp).
+Development runs WP-CLI inside the VM. Other environments run it over SSH (as the web user).
+
+WP-CLI's exit code is passed through.
+
+Any arguments after the -- separator are passed to WP-CLI:
+
+  $ trellis wp production -- plugin list
+
+Run a command for a specific site:
+
+  $ trellis wp development --site example.com -- user list
+
+Flush the cache of every site in production:
+
+  $ trellis wp production --all-sites -- cache flush
+
+Arguments:
+  ENVIRONMENT Name of environment (ie: production)
+  WP_ARGS     Arguments passed to WP-CLI
+
+Options:
+      --all-sites  Run the command for every site in the environment
+      --host       Inventory host to connect to when an environment has multiple servers
+      --site       Name of the site (ie: example.com); Optional when only single site exist in the environment
+  -h, --help       show this help
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *WpCommand) AutocompleteArgs() complete.Predictor {
+	return c.Trellis.PredictEnvironment(c.flags)
+}
+
+func (c *WpCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{
+		"--all-sites": complete.PredictNothing,
+		"--host":      complete.PredictNothing,
+		"--site":      complete.PredictNothing,
+	}
+}
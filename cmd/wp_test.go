@@ -0,0 +1,153 @@
+package cmd
+
+import (
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/trellis"
+)
+
+func TestWpRunValidations(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+
+	cases := []struct {
+		name            string
+		projectDetected bool
+		args            []string
+		out             string
+		code            int
+	}{
+		{
+			"no_project",
+			false,
+			nil,
+			"No Trellis project detected",
+			1,
+		},
+		{
+			"no_args",
+			true,
+			nil,
+			"Error: missing arguments (expected exactly 1, got 0)",
+			1,
+		},
+		{
+			"too_many_args",
+			true,
+			[]string{"production", "foo", "--", "plugin", "list"},
+			"Error: too many arguments",
+			1,
+		},
+		{
+			"invalid_env",
+			true,
+			[]string{"foo"},
+			"Error: foo is not a valid environment",
+			1,
+		},
+		{
+			"invalid_site",
+			true,
+			[]string{"production", "--site", "nosite"},
+			"Error: nosite is not a valid site",
+			1,
+		},
+		{
+			"all_sites_and_site",
+			true,
+			[]string{"production", "--all-sites", "--site", "example.com"},
+			"Error: --all-sites and --site can't be used together",
+			1,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+			trellis := trellis.NewMockTrellis(tc.projectDetected)
+			wpCommand := NewWpCommand(ui, trellis)
+
+			code := wpCommand.Run(tc.args)
+
+			if code != tc.code {
+				t.Errorf("expected code %d to be %d", code, tc.code)
+			}
+
+			combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+
+			if !strings.Contains(combined, tc.out) {
+				t.Errorf("expected output %q to contain %q", combined, tc.out)
+			}
+		})
+	}
+}
+
+func TestWpRun(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+
+	cases := []struct {
+		name string
+		args []string
+		out  string
+	}{
+		{
+			"default",
+			[]string{"production", "--", "plugin", "list"},
+			"ssh web@1.2.3.4 cd '/srv/www/example.com/current' && 'wp' '--path=/srv/www/example.com/current/web/wp' 'plugin' 'list'",
+		},
+		{
+			"site_after_separator_is_passed_through",
+			[]string{"production", "--site=example.com", "--", "option", "get", "--site", "home"},
+			"'wp' '--path=/srv/www/example.com/current/web/wp' 'option' 'get' '--site' 'home'",
+		},
+		{
+			"all_sites",
+			[]string{"--all-sites", "production", "--", "cache", "flush"},
+			"==> example.com",
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			ui := cli.NewMockUi()
+
+			project := trellis.NewTrellis()
+			MockUiExec(ui, project)
+			wpCommand := NewWpCommand(ui, project)
+			code := wpCommand.Run(tc.args)
+
+			if code != 0 {
+				t.Errorf("expected code %d to be 0", code)
+			}
+
+			combined := ui.OutputWriter.String() + ui.ErrorWriter.String()
+
+			if !strings.Contains(combined, tc.out) {
+				t.Errorf("expected output %q to contain %q", combined, tc.out)
+			}
+		})
+	}
+}
+
+func TestWpRunDevelopmentVmMultisite(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+
+	ui := cli.NewMockUi()
+	project := trellis.NewTrellis()
+	runner := mockLimaProject(t, ui, project)
+
+	wpCommand := NewWpCommand(ui, project)
+	code := wpCommand.Run([]string{"--site", "example2.com", "development", "--", "plugin", "list"})
+
+	if code != 0 {
+		t.Fatalf("expected code %d to be 0: %s", code, ui.ErrorWriter.String())
+	}
+
+	expected := "limactl shell --workdir /srv/www/example2.com/current example.com wp --path=/srv/www/example2.com/current/web/wp plugin list"
+
+	if !slices.Contains(runner.Commands(), expected) {
+		t.Errorf("expected commands %q to contain %q", runner.Commands(), expected)
+	}
+}
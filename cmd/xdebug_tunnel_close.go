@@ -52,7 +52,11 @@ func (c *XdebugTunnelCloseCommand) Run(args []string) int {
 		return 1
 	}
 
-	host := args[0]
+	host, err := resolveXdebugTunnelHost(c.Trellis, args[0])
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
 
 	playbook := ansible.Playbook{
 		Name:    "xdebug-tunnel.yml",
@@ -63,7 +67,7 @@ func (c *XdebugTunnelCloseCommand) Run(args []string) int {
 		},
 	}
 
-	xdebugClose := command.WithOptions(command.WithTermOutput(), command.WithLogging(c.UI)).Cmd("ansible-playbook", playbook.CmdArgs())
+	xdebugClose := command.New(c.Trellis.Runner, command.WithTermOutput(), command.WithLogging(c.UI)).Cmd("ansible-playbook", playbook.CmdArgs())
 
 	if err := xdebugClose.Run(); err != nil {
 		c.UI.Error(err.Error())
@@ -89,8 +93,12 @@ Close Xdebug tunnel on host 1.2.3.4:
 
   $ trellis xdebug-tunnel close 1.2.3.4
 
+Close Xdebug tunnel on the production server (resolved from 'hosts/production'):
+
+  $ trellis xdebug-tunnel close production
+
 Arguments:
-  HOST Host (IP or name) to close the xdebug tunnel on
+  HOST Host (IP or name) or environment to close the xdebug tunnel on
 
 Options:
       --verbose Enable Ansible's verbose mode
@@ -89,7 +89,7 @@ func TestXdebugTunnelCloseRun(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			ui := cli.NewMockUi()
-			defer MockUiExec(t, ui)()
+			MockUiExec(ui, trellis)
 
 			tunnelCloseCommand := NewXdebugTunnelCloseCommand(ui, trellis)
 
@@ -2,12 +2,14 @@ package cmd
 
 import (
 	"flag"
+	"fmt"
 	"strings"
 
 	"github.com/mitchellh/cli"
 	"github.com/posener/complete"
 	"github.com/roots/trellis-cli/command"
 	"github.com/roots/trellis-cli/pkg/ansible"
+	"github.com/roots/trellis-cli/pkg/inventory"
 	"github.com/roots/trellis-cli/trellis"
 )
 
@@ -52,7 +54,11 @@ func (c *XdebugTunnelOpenCommand) Run(args []string) int {
 		return 1
 	}
 
-	host := args[0]
+	host, err := resolveXdebugTunnelHost(c.Trellis, args[0])
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
 
 	playbook := ansible.Playbook{
 		Name:    "xdebug-tunnel.yml",
@@ -64,7 +70,7 @@ func (c *XdebugTunnelOpenCommand) Run(args []string) int {
 		},
 	}
 
-	xdebugOpen := command.WithOptions(command.WithTermOutput(), command.WithLogging(c.UI)).Cmd("ansible-playbook", playbook.CmdArgs())
+	xdebugOpen := command.New(c.Trellis.Runner, command.WithTermOutput(), command.WithLogging(c.UI)).Cmd("ansible-playbook", playbook.CmdArgs())
 
 	if err := xdebugOpen.Run(); err != nil {
 		c.UI.Error(err.Error())
@@ -90,8 +96,12 @@ Open Xdebug tunnel on host 1.2.3.4:
 
   $ trellis xdebug-tunnel open 1.2.3.4
 
+Open Xdebug tunnel on the production server (resolved from 'hosts/production'):
+
+  $ trellis xdebug-tunnel open production
+
 Arguments:
-  HOST Host (IP or name) to open the xdebug tunnel on
+  HOST Host (IP or name) or environment to open the xdebug tunnel on
 
 Options:
       --verbose Enable Ansible's verbose mode
@@ -106,3 +116,37 @@ func (c *XdebugTunnelOpenCommand) AutocompleteFlags() complete.Flags {
 		"--verbose": complete.PredictNothing,
 	}
 }
+
+/*
+Resolves the inventory host for an Xdebug tunnel. A non-development environment
+name is resolved to its web server from the inventory (eg: 'hosts/production');
+any other value is used as is.
+*/
+func resolveXdebugTunnelHost(t *trellis.Trellis, host string) (string, error) {
+	if _, ok := t.Environments[host]; !ok || host == "development" {
+		return host, nil
+	}
+
+	inv, err := t.LoadInventory(host)
+	if err != nil {
+		return "", fmt.Errorf("Error loading inventory for %s: %v", host, err)
+	}
+
+	targets := []inventory.Connection{}
+	for _, name := range inv.Intersect("web", host) {
+		if name != trellis.PlaceholderHost {
+			targets = append(targets, inv.Connection(name))
+		}
+	}
+
+	if len(targets) == 0 {
+		return "", fmt.Errorf("Error: no web hosts found in hosts/%s", host)
+	}
+
+	target, err := selectSshTarget(targets, "")
+	if err != nil {
+		return "", err
+	}
+
+	return target.Name, nil
+}
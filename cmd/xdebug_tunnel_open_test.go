@@ -78,6 +78,12 @@ func TestXdebugTunnelOpenRun(t *testing.T) {
 			"ansible-playbook xdebug-tunnel.yml -e sshd_allow_tcp_forwarding=yes -e xdebug_remote_enable=1 -e xdebug_tunnel_inventory_host=1.2.3.4",
 			0,
 		},
+		{
+			"environment",
+			[]string{"production"},
+			"ansible-playbook xdebug-tunnel.yml -e sshd_allow_tcp_forwarding=yes -e xdebug_remote_enable=1 -e xdebug_tunnel_inventory_host=1.2.3.4",
+			0,
+		},
 		{
 			"with_verbose",
 			[]string{"--verbose", "1.2.3.4"},
@@ -89,7 +95,7 @@ func TestXdebugTunnelOpenRun(t *testing.T) {
 	for _, tc := range cases {
 		t.Run(tc.name, func(t *testing.T) {
 			ui := cli.NewMockUi()
-			defer MockUiExec(t, ui)()
+			MockUiExec(ui, trellis)
 
 			tunnelOpenCommand := NewXdebugTunnelOpenCommand(ui, trellis)
 
@@ -0,0 +1,125 @@
+package command
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strings"
+)
+
+const maskedValue = "****"
+
+// Flags whose value is a secret (eg: `gh secret set NAME --body <private key>`).
+var secretFlags = []string{"--body", "--passphrase", "--password", "--secret", "--token"}
+
+var (
+	secretAssignmentPattern = regexp.MustCompile(`(?i)([\w.-]*(?:password|passwd|passphrase|secret|token|api_?key|private_?key)[\w.-]*"?\s*[=:]\s*)("[^"]*"|'[^']*'|[^\s,}]+)`)
+	urlPasswordPattern      = regexp.MustCompile(`(://[^/:@\s]+:)[^@/\s]+@`)
+)
+
+/*
+A Runner which prints commands instead of running them (see the global
+--dry-run flag). Commands succeed without any output.
+Secrets in command lines are masked (see MaskArgs).
+*/
+type DryRunner struct {
+	Out io.Writer
+}
+
+func NewDryRunner(out io.Writer) *DryRunner {
+	return &DryRunner{Out: out}
+}
+
+func (r *DryRunner) Start(ctx context.Context, cmd *Cmd) (Process, error) {
+	line := strings.Join(append([]string{cmd.Name}, MaskArgs(cmd.Args)...), " ")
+
+	if cmd.Dir != "" {
+		line = fmt.Sprintf("%s (in %s)", line, cmd.Dir)
+	}
+
+	fmt.Fprintf(r.Out, "[dry-run] %s\n", line)
+
+	return &fakeProcess{}, nil
+}
+
+// Prints a file write instead of writing it.
+func (r *DryRunner) WriteFile(path string, data []byte, perm os.FileMode) error {
+	fmt.Fprintf(r.Out, "[dry-run] write %s\n", path)
+	return nil
+}
+
+// Prints a file move instead of moving it.
+func (r *DryRunner) Rename(oldpath string, newpath string) error {
+	fmt.Fprintf(r.Out, "[dry-run] move %s to %s\n", oldpath, newpath)
+	return nil
+}
+
+func IsDryRun(runner Runner) bool {
+	_, ok := runner.(*DryRunner)
+	return ok
+}
+
+/*
+Writes a file like os.WriteFile. Files are written as part of running commands
+(eg: a hosts file copied into place with sudo) so a DryRunner only prints them.
+*/
+func WriteFile(runner Runner, path string, data []byte, perm os.FileMode) error {
+	if dryRunner, ok := runner.(*DryRunner); ok {
+		return dryRunner.WriteFile(path, data, perm)
+	}
+
+	return os.WriteFile(path, data, perm)
+}
+
+// Moves a file like os.Rename (see WriteFile).
+func Rename(runner Runner, oldpath string, newpath string) error {
+	if dryRunner, ok := runner.(*DryRunner); ok {
+		return dryRunner.Rename(oldpath, newpath)
+	}
+
+	return os.Rename(oldpath, newpath)
+}
+
+/*
+Returns a copy of args with secrets masked:
+  - values of secret flags (eg: --body <value> or --password=<value>)
+  - secret key=value pairs (eg: vault_password=<value> in --extra-vars)
+  - passwords in URLs (eg: mysql://user:<password>@127.0.0.1)
+*/
+func MaskArgs(args []string) []string {
+	masked := make([]string, len(args))
+
+	for i, arg := range args {
+		if i > 0 && isSecretFlag(args[i-1]) {
+			masked[i] = maskedValue
+			continue
+		}
+
+		if flag, _, ok := strings.Cut(arg, "="); ok && isSecretFlag(flag) {
+			masked[i] = flag + "=" + maskedValue
+			continue
+		}
+
+		masked[i] = MaskSecrets(arg)
+	}
+
+	return masked
+}
+
+// Masks secret key=value (or key: value) pairs and URL passwords in a string.
+func MaskSecrets(value string) string {
+	value = secretAssignmentPattern.ReplaceAllString(value, "${1}"+maskedValue)
+	return urlPasswordPattern.ReplaceAllString(value, "${1}"+maskedValue+"@")
+}
+
+func isSecretFlag(arg string) bool {
+	for _, flag := range secretFlags {
+		if arg == flag {
+			return true
+		}
+	}
+
+	return false
+}
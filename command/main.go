@@ -1,14 +1,20 @@
 package command
 
 import (
+	"bytes"
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
-	"os/exec"
 	"strings"
+	"time"
 
 	"github.com/mitchellh/cli"
 )
 
+var TimeoutErr = errors.New("command timed out")
+
 type UiErrorWriter struct {
 	Ui cli.Ui
 }
@@ -23,79 +29,270 @@ func (w *UiErrorWriter) Write(p []byte) (n int, err error) {
 	return n, nil
 }
 
-type CommandOption func(*exec.Cmd)
-type CommandFunc func(command string, args []string) *exec.Cmd
-type ApplyOptionFunc func(option CommandOption, cmd *exec.Cmd)
-
-var ExecCommand CommandFunc = execCommand
-var OptionApplier ApplyOptionFunc = applyOption
+type CommandOption func(*Cmd)
 
+/*
+Builds commands which are run by a Runner. Options are applied to every
+command built (eg: to send output to the terminal).
+*/
 type Command struct {
+	ctx     context.Context
+	runner  Runner
 	options []CommandOption
 }
 
-func WithOptions(options ...CommandOption) *Command {
-	return &Command{options: options}
+func New(runner Runner, options ...CommandOption) *Command {
+	return &Command{ctx: context.Background(), runner: runner, options: options}
 }
 
-func Cmd(command string, args []string) *exec.Cmd {
-	cmd := ExecCommand(command, args)
-	cmd.Stdin = os.Stdin
-
-	return cmd
+// Commands built are stopped when ctx is done.
+func (c *Command) WithContext(ctx context.Context) *Command {
+	return &Command{ctx: ctx, runner: c.runner, options: c.options}
 }
 
-func (c *Command) Cmd(command string, args []string) *exec.Cmd {
-	cmd := Cmd(command, args)
+func (c *Command) Cmd(command string, args []string) *Cmd {
+	cmd := &Cmd{
+		Name:   command,
+		Args:   args,
+		Stdin:  os.Stdin,
+		ctx:    c.ctx,
+		runner: c.runner,
+	}
 
 	for _, option := range c.options {
-		OptionApplier(option, cmd)
+		option(cmd)
 	}
 
 	return cmd
 }
 
 /*
-  Enables mocking of the underlying ExecCommand command (defaults to exec.Command) and no-ops the OptionApplier function so they have no effect.
+An external command. Like exec.Cmd, nil Stdout/Stderr discard output and the
+command can only be run once.
 */
-func Mock(f CommandFunc) {
-	OptionApplier = func(option CommandOption, cmd *exec.Cmd) {}
-	ExecCommand = f
+type Cmd struct {
+	Name    string
+	Args    []string
+	Env     []string
+	Dir     string
+	Stdin   io.Reader
+	Stdout  io.Writer
+	Stderr  io.Writer
+	Timeout time.Duration
+
+	ctx     context.Context
+	runCtx  context.Context
+	cancel  context.CancelFunc
+	runner  Runner
+	process Process
+	exited  bool
+	code    int
 }
 
-/*
-  Restores the default ExecCommand and OptionApplier.
-  Should be used via `defer` after `Mock` is called.
-*/
-func Restore() {
-	OptionApplier = applyOption
-	ExecCommand = execCommand
+// Returns the command line (eg: "ansible-playbook deploy.yml -e env=production").
+func (c *Cmd) String() string {
+	return strings.Join(append([]string{c.Name}, c.Args...), " ")
+}
+
+func (c *Cmd) Run() error {
+	if err := c.Start(); err != nil {
+		return err
+	}
+
+	return c.Wait()
+}
+
+func (c *Cmd) Start() error {
+	if c.process != nil {
+		return fmt.Errorf("%s: already started", c.Name)
+	}
+
+	ctx := c.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	if c.Timeout > 0 {
+		ctx, c.cancel = context.WithTimeout(ctx, c.Timeout)
+	}
+
+	c.runCtx = ctx
+
+	process, err := c.runner.Start(ctx, c)
+	if err != nil {
+		c.release()
+		return err
+	}
+
+	c.process = process
+
+	return nil
+}
+
+// Waits for a started command to exit. Its exit code is available after.
+func (c *Cmd) Wait() error {
+	if c.process == nil {
+		return fmt.Errorf("%s: not started", c.Name)
+	}
+
+	err := c.process.Wait()
+	c.exited = true
+
+	var exitErr *ExitError
+	switch {
+	case err == nil:
+		c.code = 0
+	case errors.As(err, &exitErr):
+		c.code = exitErr.Code
+	default:
+		c.code = -1
+	}
+
+	if err != nil && c.Timeout > 0 && errors.Is(c.runCtx.Err(), context.DeadlineExceeded) {
+		err = fmt.Errorf("%w after %s: %s", TimeoutErr, c.Timeout, c.String())
+	}
+
+	c.release()
+
+	return err
+}
+
+func (c *Cmd) Output() ([]byte, error) {
+	if c.Stdout != nil {
+		return nil, fmt.Errorf("%s: Stdout already set", c.Name)
+	}
+
+	var stdout, stderr bytes.Buffer
+	c.Stdout = &stdout
+
+	captureStderr := c.Stderr == nil
+	if captureStderr {
+		c.Stderr = &stderr
+	}
+
+	err := c.Run()
+
+	var exitErr *ExitError
+	if captureStderr && errors.As(err, &exitErr) {
+		exitErr.Stderr = stderr.Bytes()
+	}
+
+	return stdout.Bytes(), err
+}
+
+func (c *Cmd) CombinedOutput() ([]byte, error) {
+	if c.Stdout != nil || c.Stderr != nil {
+		return nil, fmt.Errorf("%s: Stdout or Stderr already set", c.Name)
+	}
+
+	var output bytes.Buffer
+	c.Stdout = &output
+	c.Stderr = &output
+
+	err := c.Run()
+
+	return output.Bytes(), err
+}
+
+// Returns the exit code of an exited command or -1 if it hasn't exited (or was killed).
+func (c *Cmd) ExitCode() int {
+	if !c.exited {
+		return -1
+	}
+
+	return c.code
+}
+
+// Stops a started command. It's a no-op if it hasn't started or already exited.
+func (c *Cmd) Kill() error {
+	if c.process == nil || c.exited {
+		return nil
+	}
+
+	if err := c.process.Kill(); err != nil && !errors.Is(err, os.ErrProcessDone) {
+		return err
+	}
+
+	return nil
+}
+
+func (c *Cmd) release() {
+	if c.cancel != nil {
+		c.cancel()
+	}
 }
 
 func WithUiOutput(ui cli.Ui) CommandOption {
-	return func(cmd *exec.Cmd) {
+	return func(cmd *Cmd) {
 		cmd.Stdout = &cli.UiWriter{Ui: ui}
 		cmd.Stderr = &UiErrorWriter{ui}
 	}
 }
 
 func WithLogging(ui cli.Ui) CommandOption {
-	return func(cmd *exec.Cmd) {
-		ui.Info(fmt.Sprintf("Running command => %s", strings.Join(cmd.Args, " ")))
+	return func(cmd *Cmd) {
+		// A DryRunner prints the command (with secrets masked) instead
+		if IsDryRun(cmd.runner) {
+			return
+		}
+
+		ui.Info(fmt.Sprintf("Running command => %s", cmd.String()))
 	}
 }
 
 func WithTermOutput() CommandOption {
-	return func(cmd *exec.Cmd) {
+	return func(cmd *Cmd) {
 		cmd.Stdout = os.Stdout
 		cmd.Stderr = os.Stderr
 	}
 }
 
-func applyOption(option CommandOption, cmd *exec.Cmd) {
-	option(cmd)
+// Stops the command (and fails with TimeoutErr) if it runs longer than timeout.
+func WithTimeout(timeout time.Duration) CommandOption {
+	return func(cmd *Cmd) {
+		cmd.Timeout = timeout
+	}
+}
+
+/*
+Returned when a command exits with a non-zero code. Err is the underlying
+error (ie: *exec.ExitError for commands run by ExecRunner).
+*/
+type ExitError struct {
+	Code   int
+	Stderr []byte
+	Err    error
+}
+
+func (e *ExitError) Error() string {
+	if e.Err != nil {
+		return e.Err.Error()
+	}
+
+	return fmt.Sprintf("exit status %d", e.Code)
 }
 
-func execCommand(command string, args []string) *exec.Cmd {
-	return exec.Command(command, args...)
+func (e *ExitError) Unwrap() error {
+	return e.Err
+}
+
+func (e *ExitError) ExitCode() int {
+	return e.Code
+}
+
+/*
+Returns the exit code for a command's error: 0 for nil, the command's code
+when it exited unsuccessfully, or 1 for other errors (eg: it couldn't start).
+*/
+func ExitCode(err error) int {
+	if err == nil {
+		return 0
+	}
+
+	var exitErr *ExitError
+	if errors.As(err, &exitErr) && exitErr.Code > 0 {
+		return exitErr.Code
+	}
+
+	return 1
 }
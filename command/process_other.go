@@ -0,0 +1,33 @@
+//go:build !windows
+
+package command
+
+import (
+	"os"
+	"os/exec"
+	"syscall"
+)
+
+func setProcessGroup(cmd *exec.Cmd, group bool) {
+	if group {
+		cmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
+	}
+}
+
+// Signals a process, or its whole process group so its children get the signal too.
+func signalProcess(process *os.Process, sig os.Signal, group bool) error {
+	s, ok := sig.(syscall.Signal)
+	if !group || !ok {
+		return process.Signal(sig)
+	}
+
+	if err := syscall.Kill(-process.Pid, s); err != nil {
+		if err == syscall.ESRCH {
+			return os.ErrProcessDone
+		}
+
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,19 @@
+package command
+
+import (
+	"os"
+	"os/exec"
+)
+
+// Process groups aren't supported on Windows.
+func setProcessGroup(cmd *exec.Cmd, group bool) {}
+
+// Windows can't send signals to other processes so they're killed instead.
+// Ctrl-C in a console already interrupts every process attached to it.
+func signalProcess(process *os.Process, sig os.Signal, group bool) error {
+	if sig == os.Interrupt {
+		return nil
+	}
+
+	return process.Kill()
+}
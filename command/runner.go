@@ -0,0 +1,138 @@
+package command
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+/*
+Starts commands. Commands share a Runner (see trellis.Trellis.Runner) so tests
+can use a FakeRunner instead of running anything.
+*/
+type Runner interface {
+	Start(ctx context.Context, cmd *Cmd) (Process, error)
+}
+
+type Process interface {
+	// Waits for the process to exit. Non-zero exit codes are returned as *ExitError.
+	Wait() error
+	Kill() error
+}
+
+/*
+Runs commands as OS processes.
+
+Interrupt and terminate signals received by trellis-cli are forwarded to the
+command (and its child processes) and trellis-cli waits for it to exit so its
+exit code can be returned. Non-interactive commands run in their own process
+group. Interactive commands (attached to a terminal) stay in the terminal's
+process group so they can read from it; the terminal interrupts them directly.
+
+When a command's context is done (ie: it timed out), it's sent SIGTERM and
+killed if it hasn't exited after KillDelay.
+*/
+type ExecRunner struct {
+	KillDelay time.Duration
+}
+
+func NewExecRunner() *ExecRunner {
+	return &ExecRunner{KillDelay: 10 * time.Second}
+}
+
+func (r *ExecRunner) Start(ctx context.Context, cmd *Cmd) (Process, error) {
+	execCmd := exec.CommandContext(ctx, cmd.Name, cmd.Args...)
+	execCmd.Env = cmd.Env
+	execCmd.Dir = cmd.Dir
+	execCmd.Stdin = cmd.Stdin
+	execCmd.Stdout = cmd.Stdout
+	execCmd.Stderr = cmd.Stderr
+
+	group := !isTerminal(cmd.Stdin) && !isTerminal(cmd.Stdout)
+	setProcessGroup(execCmd, group)
+
+	process := &execProcess{cmd: execCmd, group: group, signals: make(chan os.Signal, 1)}
+
+	execCmd.Cancel = func() error {
+		return process.signal(syscall.SIGTERM)
+	}
+
+	// Only cancellable commands are killed after a delay; otherwise Wait
+	// would also stop waiting for output from processes the command left behind.
+	if ctx.Done() != nil {
+		execCmd.WaitDelay = r.KillDelay
+	}
+
+	signal.Notify(process.signals, os.Interrupt, syscall.SIGTERM)
+
+	if err := execCmd.Start(); err != nil {
+		signal.Stop(process.signals)
+		return nil, err
+	}
+
+	go process.forwardSignals()
+
+	return process, nil
+}
+
+type execProcess struct {
+	cmd     *exec.Cmd
+	group   bool
+	signals chan os.Signal
+}
+
+func (p *execProcess) Wait() error {
+	err := p.cmd.Wait()
+
+	signal.Stop(p.signals)
+	close(p.signals)
+
+	var exitErr *exec.ExitError
+
+	switch {
+	case errors.Is(err, exec.ErrWaitDelay):
+		// The command succeeded but left processes behind holding its output open
+		return nil
+	case errors.As(err, &exitErr):
+		return &ExitError{Code: exitErr.ExitCode(), Stderr: exitErr.Stderr, Err: exitErr}
+	}
+
+	return err
+}
+
+func (p *execProcess) Kill() error {
+	return p.signal(os.Kill)
+}
+
+func (p *execProcess) forwardSignals() {
+	for sig := range p.signals {
+		// A terminal already interrupts every process in its foreground process group
+		if sig == os.Interrupt && !p.group {
+			continue
+		}
+
+		p.signal(sig)
+	}
+}
+
+func (p *execProcess) signal(sig os.Signal) error {
+	return signalProcess(p.cmd.Process, sig, p.group)
+}
+
+func isTerminal(stream interface{}) bool {
+	file, ok := stream.(*os.File)
+	if !ok {
+		return false
+	}
+
+	info, err := file.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -1,15 +1,14 @@
 package command
 
 import (
-	"encoding/json"
+	"context"
 	"fmt"
 	"io"
-	"os"
-	"os/exec"
-	"strings"
-	"testing"
+	"reflect"
+	"sync"
 )
 
+// A stubbed command for FakeRunner. Its Args must match exactly (nil Args match any args).
 type MockCommand struct {
 	Command  string   `json:"command"`
 	Args     []string `json:"args"`
@@ -17,76 +16,90 @@ type MockCommand struct {
 	ExitCode int      `json:"exit_code"`
 }
 
-func MockExecCommand(stdout io.Writer, stderr io.Writer) func(command string, args []string) *exec.Cmd {
-	return func(command string, args []string) *exec.Cmd {
-		cs := []string{"-test.run=TestHelperProcess", "--", command}
-		cs = append(cs, args...)
-		cmd := exec.Command(os.Args[0], cs...)
-
-		cmd.Stdout = stdout
-		cmd.Stderr = stderr
-		cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
-		return cmd
-	}
+/*
+A Runner for tests which records commands instead of running them.
+Stubbed commands write their Output to stdout and exit with their ExitCode.
+Other commands succeed without output, or fail to start when Strict is set.
+*/
+type FakeRunner struct {
+	Stubs  []MockCommand
+	Strict bool
+	// When set, each command line is written to it (eg: a MockUi's OutputWriter)
+	Log io.Writer
+
+	mu   sync.Mutex
+	cmds []*Cmd
 }
 
-func MockExecCommands(t *testing.T, commands []MockCommand) func() {
-	t.Helper()
+func NewFakeRunner(stubs ...MockCommand) *FakeRunner {
+	return &FakeRunner{Stubs: stubs}
+}
 
-	tmpDir := t.TempDir()
+func (r *FakeRunner) Start(ctx context.Context, cmd *Cmd) (Process, error) {
+	r.mu.Lock()
+	r.cmds = append(r.cmds, cmd)
+	r.mu.Unlock()
 
-	serializedCommands, err := json.Marshal(commands)
-	if err != nil {
-		t.Fatalf("error serializing commands: %s", err)
+	if r.Log != nil {
+		fmt.Fprintln(r.Log, cmd.String())
 	}
 
-	mockExecCommand := func(command string, args []string) *exec.Cmd {
-		cs := []string{"-test.run=TestCommandHelperProcess", "--", command}
-		cs = append(cs, args...)
-		cmd := exec.Command(os.Args[0], cs...)
-		cmd.Env = []string{
-			"GO_WANT_HELPER_PROCESS=1", fmt.Sprintf("GO_TEST_HELPER_TMP_PATH=%s", tmpDir),
-			"GO_TEST_HELPER_COMMANDS=" + string(serializedCommands),
-		}
-		return cmd
+	stub, ok := r.stub(cmd)
+	if !ok && r.Strict {
+		return nil, fmt.Errorf("unexpected command: %s", cmd.String())
 	}
 
-	Mock(mockExecCommand)
+	return &fakeProcess{code: stub.ExitCode, output: stub.Output, stdout: cmd.Stdout}, nil
+}
 
-	return func() {
-		Restore()
-	}
+// Returns the commands started so far.
+func (r *FakeRunner) Cmds() []*Cmd {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	return append([]*Cmd(nil), r.cmds...)
 }
 
-func CommandHelperProcess(t *testing.T) {
-	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
-		return
+// Returns the command lines of the commands started so far.
+func (r *FakeRunner) Commands() []string {
+	commands := []string{}
+
+	for _, cmd := range r.Cmds() {
+		commands = append(commands, cmd.String())
 	}
 
-	commands := []MockCommand{}
-	err := json.Unmarshal([]byte(os.Getenv("GO_TEST_HELPER_COMMANDS")), &commands)
-	if err != nil {
-		t.Fatalf("error unmarshaling commands: %s", err)
+	return commands
+}
+
+func (r *FakeRunner) stub(cmd *Cmd) (MockCommand, bool) {
+	for _, stub := range r.Stubs {
+		if stub.Command == cmd.Name && (stub.Args == nil || reflect.DeepEqual(stub.Args, cmd.Args) || len(stub.Args)+len(cmd.Args) == 0) {
+			return stub, true
+		}
 	}
 
-	command := strings.Join(os.Args[3:len(os.Args)], " ")
+	return MockCommand{}, false
+}
 
-	commandExecuted := MockCommand{}
-	commandFound := false
+type fakeProcess struct {
+	code   int
+	output string
+	stdout io.Writer
+}
 
-	for _, cmd := range commands {
-		execCmd := exec.Command(cmd.Command, cmd.Args...)
-		if execCmd.String() == command {
-			commandExecuted = cmd
-			commandFound = true
-			break
-		}
+// Writes the stub's output (like a running process would) before exiting.
+func (p *fakeProcess) Wait() error {
+	if p.output != "" && p.stdout != nil {
+		io.WriteString(p.stdout, p.output)
 	}
 
-	if !commandFound {
-		t.Fatalf("command not found: %s\nmocked commands: %v", command, commands)
+	if p.code != 0 {
+		return &ExitError{Code: p.code}
 	}
 
-	fmt.Fprintf(os.Stdout, commandExecuted.Output)
-	os.Exit(commandExecuted.ExitCode)
+	return nil
+}
+
+func (p *fakeProcess) Kill() error {
+	return nil
 }
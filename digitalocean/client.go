@@ -4,10 +4,13 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"io"
 	"net/http"
+	"net/url"
 	"os/user"
 	"sort"
 	"strings"
+	"time"
 
 	"github.com/digitalocean/godo"
 	"github.com/roots/trellis-cli/dns"
@@ -15,28 +18,103 @@ import (
 	"golang.org/x/oauth2"
 )
 
-const baseTag = "trellis"
+const (
+	baseTag                   = "trellis"
+	defaultActionPollInterval = 5 * time.Second
+	listPageSize              = 100
+)
+
+var firewallInboundPorts = []string{"22", "80", "443"}
 
 var (
 	ErrNotFound = errors.New("Not found")
 )
 
 type Client struct {
-	Client *godo.Client
+	Client             *godo.Client
+	ActionPollInterval time.Duration
+	dryRun             bool
+}
+
+type ClientOptions struct {
+	BaseURL            string
+	RetryPolicy        RetryPolicy
+	ActionPollInterval time.Duration
+	// When set, requests which would make changes are printed to it instead of being sent
+	DryRun io.Writer
+}
+
+type ClientOption func(*ClientOptions)
+
+func WithBaseURL(baseURL string) ClientOption {
+	return func(o *ClientOptions) {
+		o.BaseURL = baseURL
+	}
+}
+
+func WithRetryPolicy(policy RetryPolicy) ClientOption {
+	return func(o *ClientOptions) {
+		o.RetryPolicy = policy
+	}
+}
+
+func WithActionPollInterval(interval time.Duration) ClientOption {
+	return func(o *ClientOptions) {
+		o.ActionPollInterval = interval
+	}
+}
+
+func WithDryRun(out io.Writer) ClientOption {
+	return func(o *ClientOptions) {
+		o.DryRun = out
+	}
 }
 
-func NewClient(accessToken string) *Client {
+/*
+Creates a DigitalOcean API client.
+Requests which are rate limited (429) are automatically retried according to the
+client's RetryPolicy, as are idempotent requests (not POST) which fail with a
+server error (5xx).
+During a dry run (see WithDryRun), only read-only requests are sent.
+*/
+func NewClient(accessToken string, opts ...ClientOption) *Client {
+	options := &ClientOptions{
+		RetryPolicy:        DefaultRetryPolicy,
+		ActionPollInterval: defaultActionPollInterval,
+	}
+
+	for _, opt := range opts {
+		opt(options)
+	}
+
+	var transport http.RoundTripper = newRetryTransport(http.DefaultTransport, options.RetryPolicy)
+
+	if options.DryRun != nil {
+		transport = newDryRunTransport(transport, options.DryRun)
+	}
+
+	baseClient := &http.Client{Transport: transport}
+	ctx := context.WithValue(context.Background(), oauth2.HTTPClient, baseClient)
+
 	token := &oauth2.Token{AccessToken: accessToken}
 	t := oauth2.StaticTokenSource(token)
-	oauthClient := oauth2.NewClient(context.Background(), t)
+	oauthClient := oauth2.NewClient(ctx, t)
 	client := godo.NewClient(oauthClient)
 
-	return &Client{client}
+	if options.BaseURL != "" {
+		client.BaseURL, _ = url.Parse(options.BaseURL)
+	}
+
+	return &Client{Client: client, ActionPollInterval: options.ActionPollInterval, dryRun: options.DryRun != nil}
+}
+
+// Whether requests which would make changes are only printed (see WithDryRun).
+func (do *Client) DryRun() bool {
+	return do.dryRun
 }
 
-func (do *Client) CreateDomain(name string) (domain *godo.Domain, err error) {
+func (do *Client) CreateDomain(ctx context.Context, name string) (domain *godo.Domain, err error) {
 	createRequest := &godo.DomainCreateRequest{Name: name}
-	ctx := context.TODO()
 	domain, _, err = do.Client.Domains.Create(ctx, createRequest)
 
 	if err != nil {
@@ -46,7 +124,7 @@ func (do *Client) CreateDomain(name string) (domain *godo.Domain, err error) {
 	return domain, nil
 }
 
-func (do *Client) CreateDomainRecord(domain string, hostName string, ip string) (domainRecord *godo.DomainRecord, err error) {
+func (do *Client) CreateDomainRecord(ctx context.Context, domain string, hostName string, ip string) (domainRecord *godo.DomainRecord, err error) {
 	createRequest := &godo.DomainRecordEditRequest{
 		Type: "A",
 		Name: hostName,
@@ -54,8 +132,6 @@ func (do *Client) CreateDomainRecord(domain string, hostName string, ip string)
 		TTL:  300,
 	}
 
-	ctx := context.TODO()
-
 	domainRecord, _, err = do.Client.Domains.CreateRecord(ctx, domain, createRequest)
 
 	if err != nil {
@@ -65,22 +141,24 @@ func (do *Client) CreateDomainRecord(domain string, hostName string, ip string)
 	return domainRecord, nil
 }
 
-func (do *Client) CreateDroplet(region string, size string, image string, publicKey ssh.PublicKey, name string, env string) (newDroplet *godo.Droplet, monitorUri string, err error) {
+func (do *Client) CreateDroplet(ctx context.Context, options DropletOptions) (newDroplet *godo.Droplet, monitorUri string, err error) {
 	createRequest := &godo.DropletCreateRequest{
-		Name:   name,
-		Region: region,
-		Size:   size,
+		Name:   options.Name,
+		Region: options.Region,
+		Size:   options.Size,
 		Image: godo.DropletCreateImage{
-			Slug: image,
+			Slug: options.Image,
 		},
 		SSHKeys: []godo.DropletCreateSSHKey{
-			{Fingerprint: ssh.FingerprintLegacyMD5(publicKey)},
+			{Fingerprint: ssh.FingerprintLegacyMD5(options.PublicKey)},
 		},
-		Tags: []string{baseTag, env},
+		Tags:       []string{baseTag, options.Env},
+		Backups:    options.Backups,
+		Monitoring: options.Monitoring,
+		VPCUUID:    options.VpcUUID,
+		UserData:   options.UserData,
 	}
 
-	ctx := context.TODO()
-
 	newDroplet, response, err := do.Client.Droplets.Create(ctx, createRequest)
 	if err != nil {
 		return nil, "", err
@@ -91,10 +169,78 @@ func (do *Client) CreateDroplet(region string, size string, image string, public
 	return newDroplet, monitorUri, err
 }
 
-func (do *Client) CreateSSHKey(key string) error {
-	var name string
+// Creates a Cloud Firewall for the droplet which only allows inbound SSH,
+// HTTP and HTTPS traffic. All outbound traffic is allowed.
+func (do *Client) CreateFirewall(ctx context.Context, name string, droplet *godo.Droplet) (firewall *godo.Firewall, err error) {
+	allAddresses := []string{"0.0.0.0/0", "::/0"}
+
+	inboundRules := []godo.InboundRule{}
+	for _, port := range firewallInboundPorts {
+		inboundRules = append(inboundRules, godo.InboundRule{
+			Protocol:  "tcp",
+			PortRange: port,
+			Sources:   &godo.Sources{Addresses: allAddresses},
+		})
+	}
 
-	ctx := context.TODO()
+	outboundRules := []godo.OutboundRule{}
+	for _, protocol := range []string{"tcp", "udp"} {
+		outboundRules = append(outboundRules, godo.OutboundRule{
+			Protocol:     protocol,
+			PortRange:    "all",
+			Destinations: &godo.Destinations{Addresses: allAddresses},
+		})
+	}
+	outboundRules = append(outboundRules, godo.OutboundRule{
+		Protocol:     "icmp",
+		Destinations: &godo.Destinations{Addresses: allAddresses},
+	})
+
+	createRequest := &godo.FirewallRequest{
+		Name:          name,
+		InboundRules:  inboundRules,
+		OutboundRules: outboundRules,
+		DropletIDs:    []int{droplet.ID},
+	}
+
+	firewall, _, err = do.Client.Firewalls.Create(ctx, createRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return firewall, nil
+}
+
+// Creates a reserved IP and assigns it to the droplet.
+// The returned monitorUri is empty if the assignment completed immediately.
+func (do *Client) CreateReservedIP(ctx context.Context, droplet *godo.Droplet) (reservedIP *godo.ReservedIP, monitorUri string, err error) {
+	createRequest := &godo.ReservedIPCreateRequest{
+		DropletID: droplet.ID,
+	}
+
+	reservedIP, response, err := do.Client.ReservedIPs.Create(ctx, createRequest)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if response.Links != nil && len(response.Links.Actions) > 0 {
+		monitorUri = response.Links.Actions[0].HREF
+	}
+
+	return reservedIP, monitorUri, nil
+}
+
+func (do *Client) CreateSnapshot(ctx context.Context, droplet *godo.Droplet, name string) (action *godo.Action, monitorUri string, err error) {
+	action, _, err = do.Client.DropletActions.Snapshot(ctx, droplet.ID, name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return action, do.actionUri(action), nil
+}
+
+func (do *Client) CreateSSHKey(ctx context.Context, key string) error {
+	var name string
 
 	u, err := user.Current()
 	if err != nil {
@@ -117,16 +263,20 @@ func (do *Client) CreateSSHKey(key string) error {
 	return nil
 }
 
-func (do *Client) DeleteDomainRecord(record godo.DomainRecord, domain string) (err error) {
-	ctx := context.TODO()
+func (do *Client) DeleteDroplet(ctx context.Context, droplet *godo.Droplet) error {
+	_, err := do.Client.Droplets.Delete(ctx, droplet.ID)
+	return err
+}
 
+func (do *Client) DeleteDomainRecord(ctx context.Context, record godo.DomainRecord, domain string) (err error) {
 	_, err = do.Client.Domains.DeleteRecord(ctx, domain, record.ID)
 	return err
 }
 
-func (do *Client) GetAvailableRegions() ([]godo.Region, error) {
-	ctx := context.TODO()
-	regions, _, err := do.Client.Regions.List(ctx, &godo.ListOptions{})
+func (do *Client) GetAvailableRegions(ctx context.Context) ([]godo.Region, error) {
+	regions, err := listAll(func(opt *godo.ListOptions) ([]godo.Region, *godo.Response, error) {
+		return do.Client.Regions.List(ctx, opt)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -146,9 +296,10 @@ func (do *Client) GetAvailableRegions() ([]godo.Region, error) {
 	return availableRegions, nil
 }
 
-func (do *Client) GetSizesByRegion(region *godo.Region) ([]godo.Size, error) {
-	ctx := context.TODO()
-	sizes, _, err := do.Client.Sizes.List(ctx, &godo.ListOptions{})
+func (do *Client) GetSizesByRegion(ctx context.Context, region *godo.Region) ([]godo.Size, error) {
+	sizes, err := listAll(func(opt *godo.ListOptions) ([]godo.Size, *godo.Response, error) {
+		return do.Client.Sizes.List(ctx, opt)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -178,16 +329,14 @@ func sizeInRegion(region *godo.Region, sizeSlug string) bool {
 	return false
 }
 
-func (do *Client) GetSSHKey(publicKey ssh.PublicKey) (*godo.Response, error) {
-	ctx := context.TODO()
+func (do *Client) GetSSHKey(ctx context.Context, publicKey ssh.PublicKey) (*godo.Response, error) {
 	fingerprint := ssh.FingerprintLegacyMD5(publicKey)
 
 	_, response, err := do.Client.Keys.GetByFingerprint(ctx, fingerprint)
 	return response, err
 }
 
-func (do *Client) GetDroplet(droplet *godo.Droplet) (*godo.Droplet, string, error) {
-	ctx := context.TODO()
+func (do *Client) GetDroplet(ctx context.Context, droplet *godo.Droplet) (*godo.Droplet, string, error) {
 	droplet, _, err := do.Client.Droplets.Get(ctx, droplet.ID)
 
 	if err != nil {
@@ -203,9 +352,22 @@ func (do *Client) GetDroplet(droplet *godo.Droplet) (*godo.Droplet, string, erro
 	return droplet, ip, err
 }
 
-func (do *Client) GetDroplets() (droplets []godo.Droplet, err error) {
-	ctx := context.TODO()
-	droplets, _, err = do.Client.Droplets.List(ctx, &godo.ListOptions{Page: 1, PerPage: 100})
+func (do *Client) GetDroplets(ctx context.Context) (droplets []godo.Droplet, err error) {
+	droplets, err = listAll(func(opt *godo.ListOptions) ([]godo.Droplet, *godo.Response, error) {
+		return do.Client.Droplets.List(ctx, opt)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return droplets, err
+}
+
+func (do *Client) GetTrellisDroplets(ctx context.Context) (droplets []godo.Droplet, err error) {
+	droplets, err = listAll(func(opt *godo.ListOptions) ([]godo.Droplet, *godo.Response, error) {
+		return do.Client.Droplets.ListByTag(ctx, baseTag, opt)
+	})
 
 	if err != nil {
 		return nil, err
@@ -214,8 +376,12 @@ func (do *Client) GetDroplets() (droplets []godo.Droplet, err error) {
 	return droplets, err
 }
 
-func (do *Client) GetDropletByIP(ip string) (droplet *godo.Droplet, err error) {
-	droplets, err := do.GetDroplets()
+/*
+Returns the droplet with the IP as its public IP or assigned reserved IP
+(see `droplet create --reserved-ip`), or nil if no droplet has the IP.
+*/
+func (do *Client) GetDropletByIP(ctx context.Context, ip string) (droplet *godo.Droplet, err error) {
+	droplets, err := do.GetDroplets(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -227,19 +393,40 @@ func (do *Client) GetDropletByIP(ip string) (droplet *godo.Droplet, err error) {
 		}
 
 		if ip == dropletIP {
-			droplet = &d
-			break
+			return &d, nil
 		}
 	}
 
-	return droplet, err
+	reservedIP, resp, err := do.Client.ReservedIPs.Get(ctx, ip)
+
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	return reservedIP.Droplet, nil
+}
+
+func (do *Client) GetDropletSnapshots(ctx context.Context, droplet *godo.Droplet) (snapshots []godo.Image, err error) {
+	snapshots, err = listAll(func(opt *godo.ListOptions) ([]godo.Image, *godo.Response, error) {
+		return do.Client.Droplets.Snapshots(ctx, droplet.ID, opt)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return snapshots, err
 }
 
-func (do *Client) GetHostRecords(hostsMap map[string][]dns.Host) []Host {
+func (do *Client) GetHostRecords(ctx context.Context, hostsMap map[string][]dns.Host) []Host {
 	allHosts := []Host{}
 
 	for domain, hosts := range hostsMap {
-		existingRecords, err := do.ListDomainRecords(domain)
+		existingRecords, err := do.ListDomainRecords(ctx, domain)
 
 		for _, host := range hosts {
 			var hostRecord *godo.DomainRecord
@@ -271,11 +458,16 @@ func (do *Client) GetHostRecords(hostsMap map[string][]dns.Host) []Host {
 	return allHosts
 }
 
-func (do *Client) ListDomainRecords(domain string) (records []godo.DomainRecord, err error) {
-	ctx := context.TODO()
-	records, resp, err := do.Client.Domains.Records(ctx, domain, &godo.ListOptions{Page: 1, PerPage: 100})
+func (do *Client) ListDomainRecords(ctx context.Context, domain string) (records []godo.DomainRecord, err error) {
+	var resp *godo.Response
+
+	records, err = listAll(func(opt *godo.ListOptions) ([]godo.DomainRecord, *godo.Response, error) {
+		var page []godo.DomainRecord
+		page, resp, err = do.Client.Domains.Records(ctx, domain, opt)
+		return page, resp, err
+	})
 
-	if resp.StatusCode == http.StatusNotFound {
+	if resp != nil && resp.StatusCode == http.StatusNotFound {
 		return nil, fmt.Errorf("%w: %v", ErrNotFound, err)
 	}
 
@@ -285,3 +477,106 @@ func (do *Client) ListDomainRecords(domain string) (records []godo.DomainRecord,
 
 	return records, err
 }
+
+// Returns the reserved IPs in the account with the droplets they're assigned to.
+func (do *Client) GetReservedIPs(ctx context.Context) ([]godo.ReservedIP, error) {
+	return listAll(func(opt *godo.ListOptions) ([]godo.ReservedIP, *godo.Response, error) {
+		return do.Client.ReservedIPs.List(ctx, opt)
+	})
+}
+
+func (do *Client) GetDropletFirewalls(ctx context.Context, droplet *godo.Droplet) ([]godo.Firewall, error) {
+	return listAll(func(opt *godo.ListOptions) ([]godo.Firewall, *godo.Response, error) {
+		return do.Client.Firewalls.ListByDroplet(ctx, droplet.ID, opt)
+	})
+}
+
+func (do *Client) DeleteFirewall(ctx context.Context, firewall godo.Firewall) error {
+	_, err := do.Client.Firewalls.Delete(ctx, firewall.ID)
+	return err
+}
+
+func (do *Client) DeleteReservedIP(ctx context.Context, reservedIP godo.ReservedIP) error {
+	_, err := do.Client.ReservedIPs.Delete(ctx, reservedIP.IP)
+	return err
+}
+
+/*
+Calls list for each page of results (following the response's links) until
+the last page and returns all items. The API returns at most listPageSize items
+per page.
+*/
+func listAll[T any](list func(opt *godo.ListOptions) ([]T, *godo.Response, error)) ([]T, error) {
+	all := []T{}
+	opt := &godo.ListOptions{Page: 1, PerPage: listPageSize}
+
+	for {
+		items, resp, err := list(opt)
+		if err != nil {
+			return nil, err
+		}
+
+		all = append(all, items...)
+
+		if resp == nil || resp.Links == nil || resp.Links.IsLastPage() {
+			return all, nil
+		}
+
+		page, err := resp.Links.CurrentPage()
+		if err != nil {
+			return nil, err
+		}
+
+		opt.Page = page + 1
+	}
+}
+
+type ActionProgressFunc func(action *godo.Action, elapsed time.Duration)
+
+/*
+Polls an action (eg: droplet create, snapshot, reserved IP assignment) until it
+completes, fails, or the context is cancelled.
+The optional progress func is called after each poll.
+*/
+func (do *Client) WaitForAction(ctx context.Context, monitorUri string, progress ActionProgressFunc) error {
+	// Actions are never started during a dry run
+	if do.dryRun {
+		return nil
+	}
+
+	if monitorUri == "" {
+		return errors.New("Action has no monitor URI")
+	}
+
+	start := time.Now()
+
+	for {
+		action, _, err := do.Client.DropletActions.GetByURI(ctx, monitorUri)
+		if err != nil {
+			return err
+		}
+
+		if progress != nil {
+			progress(action, time.Since(start))
+		}
+
+		switch action.Status {
+		case godo.ActionCompleted:
+			return nil
+		case godo.ActionInProgress:
+		default:
+			return fmt.Errorf("Action %s (%d) did not complete: %s", action.Type, action.ID, action.Status)
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(do.ActionPollInterval):
+		}
+	}
+}
+
+func (do *Client) actionUri(action *godo.Action) string {
+	path := &url.URL{Path: fmt.Sprintf("v2/actions/%d", action.ID)}
+	return do.Client.BaseURL.ResolveReference(path).String()
+}
@@ -0,0 +1,380 @@
+package digitalocean
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/digitalocean/godo"
+)
+
+var testRetryPolicy = RetryPolicy{
+	MaxRetries:       3,
+	MinBackoff:       time.Millisecond,
+	MaxBackoff:       10 * time.Millisecond,
+	MaxRateLimitWait: time.Second,
+}
+
+func newTestClient(t *testing.T, handler http.HandlerFunc) *Client {
+	t.Helper()
+
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+
+	return NewClient(
+		"token",
+		WithBaseURL(server.URL+"/"),
+		WithRetryPolicy(testRetryPolicy),
+		WithActionPollInterval(time.Millisecond),
+	)
+}
+
+func TestRetriesRateLimitedRequests(t *testing.T) {
+	var requests int32
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) <= 2 {
+			w.Header().Set("Retry-After", "0")
+			w.WriteHeader(http.StatusTooManyRequests)
+			fmt.Fprint(w, `{"id":"too_many_requests","message":"API Rate limit exceeded."}`)
+			return
+		}
+
+		fmt.Fprint(w, `{"droplets":[{"id":1,"name":"example.com"}]}`)
+	})
+
+	droplets, err := client.GetDroplets(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if requests != 3 {
+		t.Errorf("expected 3 requests, got %d", requests)
+	}
+
+	if len(droplets) != 1 || droplets[0].Name != "example.com" {
+		t.Errorf("expected droplet example.com, got %v", droplets)
+	}
+}
+
+func TestDoesNotRetryServerErrorsForPost(t *testing.T) {
+	var requests int32
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"domain":{"name":"example.com"}}`)
+	})
+
+	_, err := client.CreateDomain(context.Background(), "example.com")
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+}
+
+func TestRetriesRateLimitedRequestsWithBody(t *testing.T) {
+	var requests int32
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if atomic.AddInt32(&requests, 1) == 1 {
+			w.WriteHeader(http.StatusTooManyRequests)
+			return
+		}
+
+		body, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(body), "example.com") {
+			t.Errorf("expected retried request to include the body, got %q", body)
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		fmt.Fprint(w, `{"domain":{"name":"example.com"}}`)
+	})
+
+	domain, err := client.CreateDomain(context.Background(), "example.com")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if requests != 2 {
+		t.Errorf("expected 2 requests, got %d", requests)
+	}
+
+	if domain.Name != "example.com" {
+		t.Errorf("expected domain example.com, got %s", domain.Name)
+	}
+}
+
+func TestRetriesGiveUpAfterMaxRetries(t *testing.T) {
+	var requests int32
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"id":"too_many_requests","message":"API Rate limit exceeded."}`)
+	})
+
+	_, err := client.GetDroplets(context.Background())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if requests != int32(testRetryPolicy.MaxRetries+1) {
+		t.Errorf("expected %d requests, got %d", testRetryPolicy.MaxRetries+1, requests)
+	}
+}
+
+func TestRetriesDoNotWaitBeyondRateLimitReset(t *testing.T) {
+	var requests int32
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		reset := time.Now().Add(time.Hour).Unix()
+		w.Header().Set("RateLimit-Reset", strconv.FormatInt(reset, 10))
+		w.WriteHeader(http.StatusTooManyRequests)
+		fmt.Fprint(w, `{"id":"too_many_requests","message":"API Rate limit exceeded."}`)
+	})
+
+	_, err := client.GetDroplets(context.Background())
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+
+	if requests != 1 {
+		t.Errorf("expected 1 request, got %d", requests)
+	}
+}
+
+func TestRetriesStopWhenContextCancelled(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Retry-After", "1")
+		w.WriteHeader(http.StatusTooManyRequests)
+	})
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := client.GetDroplets(ctx)
+
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("expected context deadline error, got %v", err)
+	}
+
+	if time.Since(start) > 500*time.Millisecond {
+		t.Errorf("expected request to be cancelled promptly")
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	now := time.Unix(1000, 0)
+	transport := &retryTransport{policy: DefaultRetryPolicy, now: func() time.Time { return now }}
+
+	cases := []struct {
+		name    string
+		status  int
+		headers map[string]string
+		attempt int
+		wait    time.Duration
+		ok      bool
+	}{
+		{"exponential", 500, nil, 0, time.Second, true},
+		{"exponential_second_attempt", 500, nil, 2, 4 * time.Second, true},
+		{"exponential_capped", 500, nil, 10, 30 * time.Second, true},
+		{"retry_after", 429, map[string]string{"Retry-After": "7"}, 0, 7 * time.Second, true},
+		{"rate_limit_reset", 429, map[string]string{"RateLimit-Reset": "1010"}, 0, 10 * time.Second, true},
+		{"rate_limit_reset_past", 429, map[string]string{"RateLimit-Reset": "900"}, 0, 0, true},
+		{"rate_limit_reset_too_long", 429, map[string]string{"RateLimit-Reset": "9999"}, 0, 8999 * time.Second, false},
+		{"rate_limit_reset_ignored_for_5xx", 503, map[string]string{"RateLimit-Reset": "1010"}, 0, time.Second, true},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			resp := &http.Response{StatusCode: tc.status, Header: http.Header{}}
+			for k, v := range tc.headers {
+				resp.Header.Set(k, v)
+			}
+
+			wait, ok := transport.backoff(tc.attempt, resp)
+
+			if wait != tc.wait || ok != tc.ok {
+				t.Errorf("expected (%s, %t), got (%s, %t)", tc.wait, tc.ok, wait, ok)
+			}
+		})
+	}
+}
+
+func TestWaitForAction(t *testing.T) {
+	var requests int32
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		status := godo.ActionInProgress
+		if atomic.AddInt32(&requests, 1) >= 3 {
+			status = godo.ActionCompleted
+		}
+
+		fmt.Fprintf(w, `{"action":{"id":1,"type":"snapshot","status":"%s"}}`, status)
+	})
+
+	statuses := []string{}
+	err := client.WaitForAction(context.Background(), client.Client.BaseURL.String()+"v2/actions/1", func(action *godo.Action, elapsed time.Duration) {
+		statuses = append(statuses, action.Status)
+	})
+
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	expected := []string{"in-progress", "in-progress", "completed"}
+	if fmt.Sprint(statuses) != fmt.Sprint(expected) {
+		t.Errorf("expected progress statuses %v, got %v", expected, statuses)
+	}
+}
+
+func TestWaitForActionErrored(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		fmt.Fprint(w, `{"action":{"id":1,"type":"snapshot","status":"errored"}}`)
+	})
+
+	err := client.WaitForAction(context.Background(), client.Client.BaseURL.String()+"v2/actions/1", nil)
+
+	if err == nil || err.Error() != "Action snapshot (1) did not complete: errored" {
+		t.Errorf("expected errored action error, got %v", err)
+	}
+}
+
+func TestDryRunOnlySendsReadOnlyRequests(t *testing.T) {
+	var out strings.Builder
+	methods := []string{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		methods = append(methods, r.Method)
+		fmt.Fprint(w, `{"droplets":[{"id":1,"name":"example.com","networks":{"v4":[{"ip_address":"192.168.1.1","type":"public"}]}}]}`)
+	}))
+	t.Cleanup(server.Close)
+
+	client := NewClient("token", WithBaseURL(server.URL+"/"), WithRetryPolicy(testRetryPolicy), WithDryRun(&out))
+	ctx := context.Background()
+
+	droplet, err := client.GetDropletByIP(ctx, "192.168.1.1")
+	if err != nil || droplet == nil {
+		t.Fatalf("expected droplet to be found, got %v (error: %v)", droplet, err)
+	}
+
+	if _, err := client.CreateDomainRecord(ctx, "example.com", "www", "192.168.1.1"); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := client.DeleteDroplet(ctx, droplet); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	_, monitorUri, err := client.CreateSnapshot(ctx, droplet, "backup")
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if err := client.WaitForAction(ctx, monitorUri, nil); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if fmt.Sprint(methods) != "[GET]" {
+		t.Errorf("expected only the GET request to be sent, got %v", methods)
+	}
+
+	expected := fmt.Sprintf(`[dry-run] POST %[1]s/v2/domains/example.com/records {"type":"A","name":"www","data":"192.168.1.1","priority":0,"port":0,"ttl":300,"weight":0,"flags":0}
+[dry-run] DELETE %[1]s/v2/droplets/1
+[dry-run] POST %[1]s/v2/droplets/1/actions {"name":"backup","type":"snapshot"}
+`, server.URL)
+
+	if out.String() != expected {
+		t.Errorf("expected output:\n%s\ngot:\n%s", expected, out.String())
+	}
+}
+
+func TestGetDropletByIP(t *testing.T) {
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/v2/droplets":
+			fmt.Fprint(w, `{"droplets":[{"id":1,"name":"example.com","networks":{"v4":[{"ip_address":"192.168.1.1","type":"public"}]}}]}`)
+		case "/v2/reserved_ips/192.168.1.2":
+			fmt.Fprint(w, `{"reserved_ip":{"ip":"192.168.1.2","droplet":{"id":1,"name":"example.com"}}}`)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"id":"not_found","message":"The resource you were accessing could not be found."}`)
+		}
+	})
+
+	cases := []struct {
+		name     string
+		ip       string
+		expected int
+	}{
+		{"public_ip", "192.168.1.1", 1},
+		{"reserved_ip", "192.168.1.2", 1},
+		{"no_droplet", "192.168.1.3", 0},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			droplet, err := client.GetDropletByIP(context.Background(), tc.ip)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			id := 0
+			if droplet != nil {
+				id = droplet.ID
+			}
+
+			if id != tc.expected {
+				t.Errorf("expected droplet %d, got %d", tc.expected, id)
+			}
+		})
+	}
+}
+
+func TestGetTrellisDropletsFetchesAllPages(t *testing.T) {
+	var requests []string
+
+	client := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		requests = append(requests, r.URL.RawQuery)
+
+		switch r.URL.Query().Get("page") {
+		case "1":
+			fmt.Fprintf(w, `{"droplets":[{"id":1}],"links":{"pages":{"next":"http://%s/v2/droplets?page=2&per_page=100&tag_name=trellis","last":"http://%s/v2/droplets?page=2&per_page=100&tag_name=trellis"}}}`, r.Host, r.Host)
+		case "2":
+			fmt.Fprintf(w, `{"droplets":[{"id":2}],"links":{"pages":{"prev":"http://%s/v2/droplets?page=1&per_page=100&tag_name=trellis","first":"http://%s/v2/droplets?page=1&per_page=100&tag_name=trellis"}}}`, r.Host, r.Host)
+		default:
+			t.Errorf("unexpected request for page %q", r.URL.Query().Get("page"))
+		}
+	})
+
+	droplets, err := client.GetTrellisDroplets(context.Background())
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	if len(droplets) != 2 || droplets[0].ID != 1 || droplets[1].ID != 2 {
+		t.Errorf("expected droplets 1 and 2, got %v", droplets)
+	}
+
+	if len(requests) != 2 {
+		t.Errorf("expected 2 requests, got %d: %v", len(requests), requests)
+	}
+}
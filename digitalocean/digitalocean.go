@@ -21,6 +21,19 @@ type Domain struct {
 	Exists bool
 }
 
+type DropletOptions struct {
+	Name       string
+	Env        string
+	Region     string
+	Size       string
+	Image      string
+	PublicKey  ssh.PublicKey
+	Backups    bool
+	Monitoring bool
+	VpcUUID    string
+	UserData   string
+}
+
 type Host struct {
 	Domain Domain
 	Name   string
@@ -48,6 +61,17 @@ func CheckSSH(host string, ctx context.Context) (err error) {
 	}
 }
 
+// Returns the Trellis environment a droplet was created for based on its tags.
+func DropletEnvironment(droplet godo.Droplet) string {
+	for _, tag := range droplet.Tags {
+		if tag != baseTag {
+			return tag
+		}
+	}
+
+	return ""
+}
+
 func GetAccessToken(ui cli.Ui) (accessToken string, err error) {
 	accessToken = os.Getenv(accessTokenEnvVar)
 
@@ -0,0 +1,80 @@
+package digitalocean
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/roots/trellis-cli/command"
+)
+
+/*
+A response to requests which aren't sent during a dry run. It has a root for
+each resource the client creates (eg: "droplet" or "domain_record") and a
+completed action so callers can carry on as if the request succeeded.
+*/
+const dryRunResponseBody = `{
+  "action": {"status": "completed", "type": "dry-run"},
+  "domain": {},
+  "domain_record": {},
+  "droplet": {},
+  "firewall": {},
+  "reserved_ip": {},
+  "ssh_key": {},
+  "links": {"actions": [{"rel": "dry-run"}]}
+}`
+
+// dryRunTransport prints requests which would make changes (anything but GET
+// and HEAD) instead of sending them. Read-only requests are sent as usual.
+type dryRunTransport struct {
+	base http.RoundTripper
+	out  io.Writer
+}
+
+func newDryRunTransport(base http.RoundTripper, out io.Writer) *dryRunTransport {
+	return &dryRunTransport{base: base, out: out}
+}
+
+func (t *dryRunTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if req.Method == http.MethodGet || req.Method == http.MethodHead {
+		return t.base.RoundTrip(req)
+	}
+
+	line := fmt.Sprintf("[dry-run] %s %s", req.Method, req.URL)
+
+	if req.Body != nil {
+		body, err := io.ReadAll(req.Body)
+		req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+
+		if body := strings.TrimSpace(string(body)); body != "" {
+			line += " " + command.MaskSecrets(body)
+		}
+	}
+
+	fmt.Fprintln(t.out, line)
+
+	status := http.StatusOK
+	body := dryRunResponseBody
+
+	if req.Method == http.MethodDelete {
+		status = http.StatusNoContent
+		body = ""
+	}
+
+	return &http.Response{
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		StatusCode:    status,
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{"application/json"}},
+		Body:          io.NopCloser(bytes.NewBufferString(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}, nil
+}
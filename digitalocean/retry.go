@@ -0,0 +1,156 @@
+package digitalocean
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+)
+
+const (
+	headerRetryAfter     = "Retry-After"
+	headerRateLimitReset = "RateLimit-Reset"
+)
+
+type RetryPolicy struct {
+	MaxRetries int
+	MinBackoff time.Duration
+	MaxBackoff time.Duration
+	// Maximum time to wait when the API asks us to back off (via rate-limit headers).
+	// Responses requiring a longer wait are returned as is.
+	MaxRateLimitWait time.Duration
+}
+
+var DefaultRetryPolicy = RetryPolicy{
+	MaxRetries:       5,
+	MinBackoff:       1 * time.Second,
+	MaxBackoff:       30 * time.Second,
+	MaxRateLimitWait: 5 * time.Minute,
+}
+
+// retryTransport retries requests which failed due to rate limiting (429) or
+// server errors (5xx) with exponential backoff. Server errors are only retried
+// for idempotent methods since a failed POST may still have created something
+// (eg: a droplet).
+type retryTransport struct {
+	base   http.RoundTripper
+	policy RetryPolicy
+	now    func() time.Time
+}
+
+func newRetryTransport(base http.RoundTripper, policy RetryPolicy) *retryTransport {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+
+	return &retryTransport{base: base, policy: policy, now: time.Now}
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	ctx := req.Context()
+
+	for attempt := 0; ; attempt++ {
+		attemptReq := req
+
+		if attempt > 0 {
+			attemptReq = req.Clone(ctx)
+
+			if req.GetBody != nil {
+				body, err := req.GetBody()
+				if err != nil {
+					return nil, err
+				}
+
+				attemptReq.Body = body
+			}
+		}
+
+		resp, err := t.base.RoundTrip(attemptReq)
+		if err != nil {
+			return nil, err
+		}
+
+		if !shouldRetry(req.Method, resp.StatusCode) || attempt >= t.policy.MaxRetries {
+			return resp, nil
+		}
+
+		wait, ok := t.backoff(attempt, resp)
+		if !ok {
+			return resp, nil
+		}
+
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(wait):
+		}
+	}
+}
+
+// Returns how long to wait before the next attempt. Rate-limit headers sent
+// by the API take precedence over exponential backoff.
+func (t *retryTransport) backoff(attempt int, resp *http.Response) (wait time.Duration, ok bool) {
+	if wait, found := t.rateLimitWait(resp); found {
+		return wait, wait <= t.policy.MaxRateLimitWait
+	}
+
+	wait = t.policy.MinBackoff << attempt
+
+	if wait <= 0 || wait > t.policy.MaxBackoff {
+		wait = t.policy.MaxBackoff
+	}
+
+	return wait, true
+}
+
+func (t *retryTransport) rateLimitWait(resp *http.Response) (wait time.Duration, found bool) {
+	if retryAfter := resp.Header.Get(headerRetryAfter); retryAfter != "" {
+		if seconds, err := strconv.Atoi(retryAfter); err == nil {
+			return time.Duration(seconds) * time.Second, true
+		}
+
+		if date, err := http.ParseTime(retryAfter); err == nil {
+			return nonNegative(date.Sub(t.now())), true
+		}
+	}
+
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	if reset := resp.Header.Get(headerRateLimitReset); reset != "" {
+		if unix, err := strconv.ParseInt(reset, 10, 64); err == nil {
+			return nonNegative(time.Unix(unix, 0).Sub(t.now())), true
+		}
+	}
+
+	return 0, false
+}
+
+func shouldRetry(method string, statusCode int) bool {
+	if statusCode == http.StatusTooManyRequests {
+		return true
+	}
+
+	return statusCode >= http.StatusInternalServerError && isIdempotent(method)
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	default:
+		return false
+	}
+}
+
+func nonNegative(d time.Duration) time.Duration {
+	if d < 0 {
+		return 0
+	}
+
+	return d
+}
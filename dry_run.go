@@ -0,0 +1,20 @@
+package main
+
+const dryRunFlag = "--dry-run"
+
+/*
+Removes the global --dry-run flag from args. Like --version and --help, it
+must come before the command (ie: `trellis --dry-run deploy production`) so
+it's never confused with a flag meant for the command itself (eg: `trellis wp
+production -- search-replace a b --dry-run`).
+*/
+func parseDryRun(args []string) ([]string, bool) {
+	dryRun := false
+
+	for len(args) > 0 && args[0] == dryRunFlag {
+		args = args[1:]
+		dryRun = true
+	}
+
+	return args, dryRun
+}
@@ -0,0 +1,32 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseDryRun(t *testing.T) {
+	cases := []struct {
+		args     []string
+		expected []string
+		dryRun   bool
+	}{
+		{[]string{}, []string{}, false},
+		{[]string{"deploy", "production"}, []string{"deploy", "production"}, false},
+		{[]string{"--dry-run", "deploy", "production"}, []string{"deploy", "production"}, true},
+		{[]string{"--dry-run", "--dry-run", "vm", "start"}, []string{"vm", "start"}, true},
+		{[]string{"wp", "production", "--", "search-replace", "a", "b", "--dry-run"}, []string{"wp", "production", "--", "search-replace", "a", "b", "--dry-run"}, false},
+	}
+
+	for _, tc := range cases {
+		args, dryRun := parseDryRun(tc.args)
+
+		if !reflect.DeepEqual(args, tc.expected) {
+			t.Errorf("%q: expected args %q, got %q", tc.args, tc.expected, args)
+		}
+
+		if dryRun != tc.dryRun {
+			t.Errorf("%q: expected dry run %v, got %v", tc.args, tc.dryRun, dryRun)
+		}
+	}
+}
@@ -19,9 +19,15 @@ var (
 )
 
 type Release struct {
-	Version string `json:"tag_name"`
-	ZipUrl  string `json:"zipball_url"`
-	URL     string `json:"html_url"`
+	Version string  `json:"tag_name"`
+	ZipUrl  string  `json:"zipball_url"`
+	URL     string  `json:"html_url"`
+	Assets  []Asset `json:"assets"`
+}
+
+type Asset struct {
+	Name string `json:"name"`
+	URL  string `json:"browser_download_url"`
 }
 
 func NewReleaseFromVersion(repo string, version string) *Release {
@@ -77,7 +83,15 @@ func DownloadRelease(repo string, version string, path string, dest string) (rel
 }
 
 func FetchLatestRelease(repo string, client *http.Client) (*Release, error) {
-	url := fmt.Sprintf("%s/repos/%s/releases/latest", BaseURL, repo)
+	return fetchRelease(fmt.Sprintf("%s/repos/%s/releases/latest", BaseURL, repo), client)
+}
+
+// Fetches the release for a tag (eg: v1.0.0).
+func FetchRelease(repo string, tag string, client *http.Client) (*Release, error) {
+	return fetchRelease(fmt.Sprintf("%s/repos/%s/releases/tags/%s", BaseURL, repo, tag), client)
+}
+
+func fetchRelease(url string, client *http.Client) (*Release, error) {
 	resp, err := client.Get(url)
 
 	if err != nil {
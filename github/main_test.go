@@ -152,3 +152,44 @@ func createZipFile(files []string, writer io.Writer) error {
 
 	return nil
 }
+
+func TestFetchRelease(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		if req.URL.Path != "/repos/roots/trellis-foo/releases/tags/v1.2.0" {
+			http.Error(rw, "not found", 404)
+			return
+		}
+
+		rw.Write([]byte(`{
+  "tag_name": "v1.2.0",
+  "html_url": "https://github.com/roots/trellis-foo/releases/tag/v1.2.0",
+  "assets": [
+    {"name": "checksums.txt", "browser_download_url": "https://github.com/roots/trellis-foo/releases/download/v1.2.0/checksums.txt"}
+  ]
+}`))
+	}))
+	defer server.Close()
+
+	BaseURL = server.URL
+
+	release, err := FetchRelease("roots/trellis-foo", "v1.2.0", server.Client())
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := &Release{
+		Version: "v1.2.0",
+		URL:     "https://github.com/roots/trellis-foo/releases/tag/v1.2.0",
+		Assets: []Asset{
+			{Name: "checksums.txt", URL: "https://github.com/roots/trellis-foo/releases/download/v1.2.0/checksums.txt"},
+		},
+	}
+
+	if !cmp.Equal(expected, release) {
+		t.Errorf("expected release %v but got %v", expected, release)
+	}
+
+	if _, err := FetchRelease("roots/trellis-foo", "v9.9.9", server.Client()); err == nil {
+		t.Error("expected error for missing release")
+	}
+}
@@ -48,3 +48,16 @@ func deprecatedCommandHelpFunc(commandNames []string, f cli.HelpFunc) cli.HelpFu
 		return f(filteredCommands) + buf.String()
 	}
 }
+
+// Adds global options (which come before the command) to the main help.
+func globalOptionsHelpFunc(f cli.HelpFunc) cli.HelpFunc {
+	return func(commands map[string]cli.CommandFactory) string {
+		help := strings.Replace(f(commands), "[--help]", "[--help] [--dry-run]", 1)
+
+		return strings.TrimRight(help, "\n") + `
+
+Global options:
+    --dry-run    Print the commands, file writes and DigitalOcean API calls which would be run (with secrets masked) without running them
+`
+	}
+}
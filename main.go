@@ -7,6 +7,7 @@ import (
 
 	"github.com/roots/trellis-cli/app_paths"
 	"github.com/roots/trellis-cli/cmd"
+	"github.com/roots/trellis-cli/command"
 	"github.com/roots/trellis-cli/github"
 	"github.com/roots/trellis-cli/plugin"
 	"github.com/roots/trellis-cli/trellis"
@@ -26,7 +27,8 @@ var deprecatedCommands = []string{
 
 func main() {
 	c := cli.NewCLI("trellis", version)
-	c.Args = os.Args[1:]
+	args, dryRun := parseDryRun(os.Args[1:])
+	c.Args = args
 
 	ui := &cli.ColoredUi{
 		ErrorColor: cli.UiColorRed,
@@ -40,6 +42,10 @@ func main() {
 
 	trellis := trellis.NewTrellis()
 
+	if dryRun {
+		trellis.Runner = command.NewDryRunner(os.Stdout)
+	}
+
 	if err := trellis.LoadGlobalCliConfig(); err != nil {
 		ui.Error(err.Error())
 		os.Exit(1)
@@ -66,15 +72,57 @@ func main() {
 		"check": func() (cli.Command, error) {
 			return &cmd.CheckCommand{UI: ui, Trellis: trellis}, nil
 		},
+		"composer": func() (cli.Command, error) {
+			return cmd.NewComposerCommand(ui, trellis), nil
+		},
+		"config": func() (cli.Command, error) {
+			return &cmd.NamespaceCommand{
+				HelpText:     "Usage: trellis config <subcommand> [<args>]",
+				SynopsisText: "Commands for managing trellis-cli's config",
+			}, nil
+		},
+		"config get": func() (cli.Command, error) {
+			return cmd.NewConfigGetCommand(ui, trellis), nil
+		},
+		"config list": func() (cli.Command, error) {
+			return cmd.NewConfigListCommand(ui, trellis), nil
+		},
+		"config schema": func() (cli.Command, error) {
+			return cmd.NewConfigSchemaCommand(ui), nil
+		},
+		"config set": func() (cli.Command, error) {
+			return cmd.NewConfigSetCommand(ui, trellis), nil
+		},
 		"db": func() (cli.Command, error) {
 			return &cmd.NamespaceCommand{
 				HelpText:     "Usage: trellis db <subcommand> [<args>]",
 				SynopsisText: "Commands for database management",
 			}, nil
 		},
+		"db export": func() (cli.Command, error) {
+			return cmd.NewDBExportCommand(ui, trellis), nil
+		},
+		"db import": func() (cli.Command, error) {
+			return cmd.NewDBImportCommand(ui, trellis), nil
+		},
 		"db open": func() (cli.Command, error) {
 			return cmd.NewDBOpenCommand(ui, trellis), nil
 		},
+		"db pull": func() (cli.Command, error) {
+			return cmd.NewDBPullCommand(ui, trellis), nil
+		},
+		"db push": func() (cli.Command, error) {
+			return cmd.NewDBPushCommand(ui, trellis), nil
+		},
+		"db shell": func() (cli.Command, error) {
+			return cmd.NewDBShellCommand(ui, trellis), nil
+		},
+		"db snapshots": func() (cli.Command, error) {
+			return cmd.NewDBSnapshotsCommand(ui, trellis), nil
+		},
+		"db tunnel": func() (cli.Command, error) {
+			return cmd.NewDBTunnelCommand(ui, trellis), nil
+		},
 		"deploy": func() (cli.Command, error) {
 			return cmd.NewDeployCommand(ui, trellis), nil
 		},
@@ -93,9 +141,21 @@ func main() {
 		"droplet create": func() (cli.Command, error) {
 			return cmd.NewDropletCreateCommand(ui, trellis), nil
 		},
+		"droplet destroy": func() (cli.Command, error) {
+			return cmd.NewDropletDestroyCommand(ui, trellis), nil
+		},
 		"droplet dns": func() (cli.Command, error) {
 			return cmd.NewDropletDnsCommand(ui, trellis), nil
 		},
+		"droplet list": func() (cli.Command, error) {
+			return cmd.NewDropletListCommand(ui, trellis), nil
+		},
+		"droplet snapshot": func() (cli.Command, error) {
+			return cmd.NewDropletSnapshotCommand(ui, trellis), nil
+		},
+		"droplet snapshots": func() (cli.Command, error) {
+			return cmd.NewDropletSnapshotsCommand(ui, trellis), nil
+		},
 		"exec": func() (cli.Command, error) {
 			return &cmd.ExecCommand{UI: ui, Trellis: trellis}, nil
 		},
@@ -144,6 +204,18 @@ func main() {
 		"ssh": func() (cli.Command, error) {
 			return cmd.NewSshCommand(ui, trellis), nil
 		},
+		"uploads": func() (cli.Command, error) {
+			return &cmd.NamespaceCommand{
+				HelpText:     "Usage: trellis uploads <subcommand> [<args>]",
+				SynopsisText: "Commands for syncing WordPress uploads",
+			}, nil
+		},
+		"uploads pull": func() (cli.Command, error) {
+			return cmd.NewUploadsPullCommand(ui, trellis), nil
+		},
+		"uploads push": func() (cli.Command, error) {
+			return cmd.NewUploadsPushCommand(ui, trellis), nil
+		},
 		"up": func() (cli.Command, error) {
 			return cmd.NewUpCommand(ui, trellis), nil
 		},
@@ -198,6 +270,9 @@ func main() {
 		"vm sudoers": func() (cli.Command, error) {
 			return &cmd.VmSudoersCommand{UI: ui, Trellis: trellis}, nil
 		},
+		"wp": func() (cli.Command, error) {
+			return cmd.NewWpCommand(ui, trellis), nil
+		},
 		"xdebug-tunnel": func() (cli.Command, error) {
 			return &cmd.NamespaceCommand{
 				HelpText:     "Usage: trellis xdebug-tunnel <subcommand> [<args>]",
@@ -213,12 +288,25 @@ func main() {
 	}
 
 	c.HiddenCommands = []string{"venv", "venv hook"}
-	c.HelpFunc = deprecatedCommandHelpFunc(deprecatedCommands, cli.BasicHelpFunc("trellis"))
+	c.HelpFunc = globalOptionsHelpFunc(deprecatedCommandHelpFunc(deprecatedCommands, cli.BasicHelpFunc("trellis")))
+
+	pluginPaths := filepath.SplitList(os.Getenv("PATH"))
+	plugin.RegisterCommands(c, ui, trellis.Runner, pluginPaths, []string{"trellis"})
 
 	if trellis.CliConfig.LoadPlugins {
-		pluginPaths := filepath.SplitList(os.Getenv("PATH"))
-		plugin.Register(c, pluginPaths, []string{"trellis"})
+		plugin.Register(c, ui, trellis, pluginPaths, []string{"trellis"})
+	}
+
+	// Registered last so aliases can't shadow core or plugin commands
+	aliases := registerAliases(c, ui, trellis.Runner, trellis.Aliases())
+	args, err := expandAlias(c.Args, aliases)
+	if err != nil {
+		ui.Error(err.Error())
+		os.Exit(1)
 	}
+	c.Args = args
+
+	c.HelpFunc = aliasHelpFunc(aliases, c.HelpFunc)
 
 	exitStatus, err := c.Run()
 
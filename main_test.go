@@ -36,7 +36,7 @@ func TestIntegrationForceNoPlugin(t *testing.T) {
 
 	mockUi := cli.NewMockUi()
 
-	trellisCommand := command.WithOptions(command.WithUiOutput(mockUi)).Cmd(bin, []string{"--help"})
+	trellisCommand := command.New(command.NewExecRunner(), command.WithUiOutput(mockUi)).Cmd(bin, []string{"--help"})
 	trellisCommand.Env = []string{"PATH=" + tempDir + ":$PATH", "TRELLIS_LOAD_PLUGINS=false"}
 
 	trellisCommand.Run()
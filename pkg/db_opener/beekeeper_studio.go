@@ -0,0 +1,39 @@
+package db_opener
+
+import (
+	"fmt"
+	"runtime"
+
+	"github.com/roots/trellis-cli/command"
+)
+
+type BeekeeperStudio struct {
+	runner command.Runner
+}
+
+func (o *BeekeeperStudio) Open(c DBCredentials) (err error) {
+	return openWithTunnel(o.runner, c, "Beekeeper Studio", func(c DBCredentials) error {
+		if err := launchApp(o.runner, o.launcher(), o.argsFor(c)); err != nil {
+			return fmt.Errorf("Error opening database with Beekeeper Studio: %s", err)
+		}
+
+		return nil
+	})
+}
+
+func (o *BeekeeperStudio) launcher() string {
+	if runtime.GOOS == "darwin" {
+		return "open"
+	}
+
+	return "beekeeper-studio"
+}
+
+// Beekeeper Studio accepts a connection URL as its argument.
+func (o *BeekeeperStudio) argsFor(c DBCredentials) []string {
+	if runtime.GOOS == "darwin" {
+		return []string{"-a", "Beekeeper Studio", "--args", c.DSN()}
+	}
+
+	return []string{c.DSN()}
+}
@@ -0,0 +1,57 @@
+package db_opener
+
+import (
+	"fmt"
+	"runtime"
+	"strconv"
+	"strings"
+
+	"github.com/roots/trellis-cli/command"
+)
+
+type DBeaver struct {
+	runner command.Runner
+}
+
+func (o *DBeaver) Open(c DBCredentials) (err error) {
+	return openWithTunnel(o.runner, c, "DBeaver", func(c DBCredentials) error {
+		if err := launchApp(o.runner, o.launcher(), o.argsFor(c)); err != nil {
+			return fmt.Errorf("Error opening database with DBeaver: %s", err)
+		}
+
+		return nil
+	})
+}
+
+func (o *DBeaver) launcher() string {
+	if runtime.GOOS == "darwin" {
+		return "open"
+	}
+
+	return "dbeaver"
+}
+
+/*
+DBeaver's -con option takes pipe separated connection properties.
+The password is left out so it doesn't show up in the process list;
+DBeaver prompts for it (or uses its saved credentials).
+*/
+func (o *DBeaver) argsFor(c DBCredentials) []string {
+	properties := []string{
+		"driver=mysql",
+		"host=" + c.DBHost,
+		"port=" + strconv.Itoa(c.DBPort),
+		"database=" + c.DBName,
+		"user=" + c.DBUser,
+		fmt.Sprintf("name=%s (%s)", c.DBName, c.WPEnv),
+		"connect=true",
+	}
+
+	args := []string{"-con", strings.Join(properties, "|")}
+
+	if runtime.GOOS == "darwin" {
+		return append([]string{"-a", "DBeaver", "--args"}, args...)
+	}
+
+	return args
+}
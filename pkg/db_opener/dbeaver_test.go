@@ -0,0 +1,29 @@
+package db_opener
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestDBeaverArgsFor(t *testing.T) {
+	dbCredentials := DBCredentials{
+		DBUser:     "db-user",
+		DBPassword: "db-password",
+		DBHost:     "127.0.0.1",
+		DBPort:     3307,
+		DBName:     "db-name",
+		WPEnv:      "production",
+	}
+
+	dbeaver := &DBeaver{}
+	args := strings.Join(dbeaver.argsFor(dbCredentials), " ")
+
+	expected := "-con driver=mysql|host=127.0.0.1|port=3307|database=db-name|user=db-user|name=db-name (production)|connect=true"
+	if !strings.HasSuffix(args, expected) {
+		t.Errorf("expected args %q to end with %q", args, expected)
+	}
+
+	if strings.Contains(args, "db-password") {
+		t.Errorf("expected args %q not to contain the password", args)
+	}
+}
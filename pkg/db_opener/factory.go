@@ -3,9 +3,13 @@ package db_opener
 import (
 	"fmt"
 	"time"
+
+	"github.com/roots/trellis-cli/command"
 )
 
-type Factory struct{}
+type Factory struct {
+	Runner command.Runner
+}
 
 type Opener interface {
 	Open(c DBCredentials) (err error)
@@ -14,9 +18,15 @@ type Opener interface {
 func (f *Factory) Make(app string) (o Opener, err error) {
 	switch app {
 	case "tableplus":
-		return &Tableplus{}, nil
+		return &Tableplus{runner: f.Runner}, nil
 	case "sequel-ace":
-		return &SequelAce{spfDeleteDelay: 3 * time.Second, spfFile: nil}, nil
+		return &SequelAce{runner: f.Runner, spfDeleteDelay: 3 * time.Second, spfFile: nil}, nil
+	case "dbeaver":
+		return &DBeaver{runner: f.Runner}, nil
+	case "beekeeper-studio":
+		return &BeekeeperStudio{runner: f.Runner}, nil
+	case "mysql":
+		return &Mysql{runner: f.Runner}, nil
 	case "sequel-pro":
 		return nil, fmt.Errorf("Sequel Pro is replaced by Sequel Ace. Check the docs for more info: https://roots.io/trellis/docs/database-access/")
 	}
@@ -28,5 +38,8 @@ func (f *Factory) GetSupportedApps() []string {
 	return []string{
 		"tableplus",
 		"sequel-ace",
+		"dbeaver",
+		"beekeeper-studio",
+		"mysql",
 	}
 }
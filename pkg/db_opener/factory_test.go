@@ -34,6 +34,18 @@ func TestMake(t *testing.T) {
 			"tableplus",
 			&Tableplus{},
 		},
+		{
+			"dbeaver",
+			&DBeaver{},
+		},
+		{
+			"beekeeper-studio",
+			&BeekeeperStudio{},
+		},
+		{
+			"mysql",
+			&Mysql{},
+		},
 	}
 
 	for _, tc := range cases {
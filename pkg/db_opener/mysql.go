@@ -0,0 +1,50 @@
+package db_opener
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/roots/trellis-cli/command"
+)
+
+// Opens the `mysql` command line client through an SSH tunnel.
+type Mysql struct {
+	runner command.Runner
+}
+
+func (o *Mysql) Open(c DBCredentials) (err error) {
+	tunnel, err := OpenTunnel(o.runner, c, 0)
+	if err != nil {
+		return err
+	}
+	defer tunnel.Close()
+
+	c = tunnel.Credentials(c)
+
+	// Credentials are passed with a private option file so they don't show up in the process list.
+	optionFile, err := WriteOptionFile(c)
+	if err != nil {
+		return err
+	}
+	defer os.Remove(optionFile)
+
+	mysql := command.New(o.runner).Cmd("mysql", o.argsFor(c, optionFile))
+	mysql.Stdout = os.Stdout
+	mysql.Stderr = os.Stderr
+
+	if err := mysql.Run(); err != nil {
+		return fmt.Errorf("Error running mysql: %s", err)
+	}
+
+	return nil
+}
+
+func (o *Mysql) argsFor(c DBCredentials, optionFile string) []string {
+	return []string{
+		"--defaults-extra-file=" + optionFile,
+		"--protocol=TCP",
+		"--port=" + strconv.Itoa(c.DBPort),
+		c.DBName,
+	}
+}
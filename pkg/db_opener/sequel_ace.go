@@ -11,6 +11,7 @@ import (
 )
 
 type SequelAce struct {
+	runner         command.Runner
 	spfDeleteDelay time.Duration
 	spfFile        *os.File
 }
@@ -46,7 +47,7 @@ func (o *SequelAce) Open(c DBCredentials) (err error) {
 		return fmt.Errorf("Error writing SequelAce SPF: %s", err)
 	}
 
-	open := command.Cmd("open", []string{o.spfFile.Name()})
+	open := command.New(o.runner).Cmd("open", []string{o.spfFile.Name()})
 
 	output, err := open.CombinedOutput()
 
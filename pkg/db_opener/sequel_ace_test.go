@@ -37,16 +37,13 @@ func TestOpen(t *testing.T) {
 		},
 	}
 
-	defer command.MockExecCommands(t, commands)()
+	runner := command.NewFakeRunner(commands...)
+	runner.Strict = true
 
-	sequelAce := &SequelAce{spfDeleteDelay: 0 * time.Second, spfFile: spfFile}
+	sequelAce := &SequelAce{runner: runner, spfDeleteDelay: 0 * time.Second, spfFile: spfFile}
 	err := sequelAce.Open(dbCredentials)
 
 	if err != nil {
 		t.Errorf("Expected no error, got %v", err)
 	}
 }
-
-func TestCommandHelperProcess(t *testing.T) {
-	command.CommandHelperProcess(t)
-}
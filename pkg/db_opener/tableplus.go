@@ -2,14 +2,18 @@ package db_opener
 
 import (
 	"fmt"
-	"os/exec"
+
+	"github.com/roots/trellis-cli/command"
 )
 
-type Tableplus struct{}
+type Tableplus struct {
+	runner command.Runner
+}
 
 func (o *Tableplus) Open(c DBCredentials) (err error) {
 	uri := o.uriFor(c)
-	open := exec.Command("open", uri)
+	open := command.New(o.runner).Cmd("open", []string{uri})
+	open.Stdin = nil
 
 	// Intentionally omitting `logCmd` to prevent printing db credentials.
 	if err := open.Run(); err != nil {
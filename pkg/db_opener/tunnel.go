@@ -0,0 +1,197 @@
+package db_opener
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"os/signal"
+	"runtime"
+	"strconv"
+	"time"
+
+	"github.com/roots/trellis-cli/command"
+)
+
+const (
+	defaultDBPort      = 3306
+	tunnelReadyTimeout = 15 * time.Second
+)
+
+/*
+An SSH local port forward from 127.0.0.1:LocalPort to the database server
+(DBHost:DBPort as seen from the remote host).
+*/
+type Tunnel struct {
+	LocalPort int
+	cmd       *command.Cmd
+	done      chan error
+}
+
+/*
+Opens an SSH tunnel to the database described by the credentials.
+If localPort is 0, a free port is chosen.
+The tunnel is ready to accept connections once this returns.
+*/
+func OpenTunnel(runner command.Runner, c DBCredentials, localPort int) (*Tunnel, error) {
+	if localPort == 0 {
+		port, err := freePort()
+		if err != nil {
+			return nil, fmt.Errorf("Error finding a free local port: %v", err)
+		}
+
+		localPort = port
+	}
+
+	tunnel := &Tunnel{LocalPort: localPort, done: make(chan error, 1)}
+	tunnel.cmd = command.New(runner).Cmd("ssh", tunnelArgs(c, localPort))
+	tunnel.cmd.Stdin = nil
+
+	if err := tunnel.cmd.Start(); err != nil {
+		return nil, fmt.Errorf("Error starting SSH tunnel: %v", err)
+	}
+
+	// A dry run never opens the port
+	if command.IsDryRun(runner) {
+		return tunnel, nil
+	}
+
+	go func() {
+		tunnel.done <- tunnel.cmd.Wait()
+	}()
+
+	if err := tunnel.waitUntilReady(); err != nil {
+		tunnel.Close()
+		return nil, err
+	}
+
+	return tunnel, nil
+}
+
+// Returns the credentials to connect to the database through the tunnel.
+func (t *Tunnel) Credentials(c DBCredentials) DBCredentials {
+	c.DBHost = "127.0.0.1"
+	c.DBPort = t.LocalPort
+
+	return c
+}
+
+// Returns a channel which receives the SSH process' exit error if the tunnel closes unexpectedly.
+func (t *Tunnel) Done() <-chan error {
+	return t.done
+}
+
+func (t *Tunnel) Close() error {
+	return t.cmd.Kill()
+}
+
+func (t *Tunnel) waitUntilReady() error {
+	address := net.JoinHostPort("127.0.0.1", strconv.Itoa(t.LocalPort))
+	deadline := time.Now().Add(tunnelReadyTimeout)
+
+	for time.Now().Before(deadline) {
+		select {
+		case err := <-t.done:
+			t.done <- err
+			return fmt.Errorf("Error: SSH tunnel closed unexpectedly: %v", err)
+		default:
+		}
+
+		if conn, err := net.DialTimeout("tcp", address, time.Second); err == nil {
+			conn.Close()
+			return nil
+		}
+
+		time.Sleep(250 * time.Millisecond)
+	}
+
+	return fmt.Errorf("Error: SSH tunnel was not ready after %s", tunnelReadyTimeout)
+}
+
+func tunnelArgs(c DBCredentials, localPort int) []string {
+	dbPort := c.DBPort
+	if dbPort == 0 {
+		dbPort = defaultDBPort
+	}
+
+	args := []string{
+		"-N",
+		"-o", "ExitOnForwardFailure=yes",
+		"-L", fmt.Sprintf("127.0.0.1:%d:%s:%d", localPort, c.DBHost, dbPort),
+	}
+
+	if c.SSHPort != 0 && c.SSHPort != 22 {
+		args = append(args, "-p", strconv.Itoa(c.SSHPort))
+	}
+
+	return append(args, fmt.Sprintf("%s@%s", c.SSHUser, c.SSHHost))
+}
+
+func freePort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, err
+	}
+	defer listener.Close()
+
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+/*
+Opens a tunnel and calls open with credentials pointing through it.
+The tunnel is kept open until the user interrupts it (Ctrl-C) since GUI apps
+return as soon as they're launched.
+*/
+func openWithTunnel(runner command.Runner, c DBCredentials, app string, open func(DBCredentials) error) error {
+	tunnel, err := OpenTunnel(runner, c, 0)
+	if err != nil {
+		return err
+	}
+	defer tunnel.Close()
+
+	if err := open(tunnel.Credentials(c)); err != nil {
+		return err
+	}
+
+	if command.IsDryRun(runner) {
+		return nil
+	}
+
+	fmt.Printf("Opened %s through SSH tunnel on 127.0.0.1:%d. Press Ctrl-C to close the tunnel when done.\n", app, tunnel.LocalPort)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	select {
+	case <-ctx.Done():
+		return nil
+	case err := <-tunnel.Done():
+		return fmt.Errorf("Error: SSH tunnel closed unexpectedly: %v", err)
+	}
+}
+
+/*
+Launches a GUI app. On macOS, `open` returns as soon as the app is launched
+but Linux launchers run until the app quits, so they're started without waiting.
+*/
+func launchApp(runner command.Runner, launcher string, args []string) error {
+	// Intentionally omitting `logCmd` to prevent printing db credentials.
+	open := command.New(runner).Cmd(launcher, args)
+	open.Stdin = nil
+
+	if runtime.GOOS != "darwin" {
+		if err := open.Start(); err != nil {
+			return err
+		}
+
+		go open.Wait()
+		return nil
+	}
+
+	if output, err := open.CombinedOutput(); err != nil {
+		fmt.Println(string(output))
+		return err
+	}
+
+	return nil
+}
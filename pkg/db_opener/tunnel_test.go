@@ -0,0 +1,76 @@
+package db_opener
+
+import (
+	"reflect"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/roots/trellis-cli/command"
+)
+
+func TestTunnelArgs(t *testing.T) {
+	cases := []struct {
+		name        string
+		credentials DBCredentials
+		expected    []string
+	}{
+		{
+			"default_ports",
+			DBCredentials{SSHUser: "web", SSHHost: "1.2.3.4", DBHost: "localhost"},
+			[]string{"-N", "-o", "ExitOnForwardFailure=yes", "-L", "127.0.0.1:13306:localhost:3306", "web@1.2.3.4"},
+		},
+		{
+			"custom_ports",
+			DBCredentials{SSHUser: "web", SSHHost: "1.2.3.4", SSHPort: 2222, DBHost: "db.internal", DBPort: 3307},
+			[]string{"-N", "-o", "ExitOnForwardFailure=yes", "-L", "127.0.0.1:13306:db.internal:3307", "-p", "2222", "web@1.2.3.4"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			actual := tunnelArgs(tc.credentials, 13306)
+
+			if !reflect.DeepEqual(actual, tc.expected) {
+				t.Errorf("expected args %q to be %q", actual, tc.expected)
+			}
+		})
+	}
+}
+
+func TestTunnelCredentials(t *testing.T) {
+	tunnel := &Tunnel{LocalPort: 13306}
+
+	actual := tunnel.Credentials(DBCredentials{DBHost: "localhost", DBPort: 3306, DBName: "example"})
+
+	if actual.DBHost != "127.0.0.1" || actual.DBPort != 13306 || actual.DBName != "example" {
+		t.Errorf("expected credentials to point through the tunnel, got %+v", actual)
+	}
+}
+
+func TestMysqlArgs(t *testing.T) {
+	mysql := &Mysql{}
+
+	actual := mysql.argsFor(DBCredentials{DBHost: "127.0.0.1", DBPort: 13306, DBName: "example"}, "/tmp/trellis.cnf")
+	expected := []string{"--defaults-extra-file=/tmp/trellis.cnf", "--protocol=TCP", "--port=13306", "example"}
+
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected args %q to be %q", actual, expected)
+	}
+}
+
+func TestLaunchAppDoesNotWait(t *testing.T) {
+	if runtime.GOOS == "darwin" || runtime.GOOS == "windows" {
+		t.Skip("launchers only run in the background on Linux")
+	}
+
+	start := time.Now()
+
+	if err := launchApp(command.NewExecRunner(), "sleep", []string{"5"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("expected launchApp to return without waiting for the app, took %s", elapsed)
+	}
+}
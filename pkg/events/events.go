@@ -0,0 +1,95 @@
+package events
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Core events which plugins can subscribe to.
+const (
+	PreDeploy     = "pre-deploy"
+	PostDeploy    = "post-deploy"
+	PreProvision  = "pre-provision"
+	PostProvision = "post-provision"
+	PreVmStart    = "pre-vm-start"
+	PostVmStart   = "post-vm-start"
+)
+
+var Names = []string{PreDeploy, PostDeploy, PreProvision, PostProvision, PreVmStart, PostVmStart}
+
+const (
+	StatusSuccess = "success"
+	StatusFailure = "failure"
+)
+
+// The JSON payload sent to event handlers.
+type Event struct {
+	Name        string `json:"event"`
+	ProjectPath string `json:"project_path"`
+	Environment string `json:"environment,omitempty"`
+	Site        string `json:"site,omitempty"`
+	// Only set for "post-" events
+	Status string `json:"status,omitempty"`
+}
+
+// "pre-" events are emitted before an action and can abort it.
+func (e Event) IsPre() bool {
+	return strings.HasPrefix(e.Name, "pre-")
+}
+
+type Handler func(event Event, payload []byte) error
+
+type Dispatcher struct {
+	handlers map[string][]Handler
+}
+
+func NewDispatcher() *Dispatcher {
+	return &Dispatcher{handlers: make(map[string][]Handler)}
+}
+
+func Valid(name string) bool {
+	for _, n := range Names {
+		if n == name {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (d *Dispatcher) Subscribe(name string, handler Handler) {
+	d.handlers[name] = append(d.handlers[name], handler)
+}
+
+/*
+Runs an event's handlers in the order they subscribed.
+For "pre-" events, the first failing handler stops the others and its error is
+returned so the action can be aborted. For other events, all handlers are run
+and their errors are joined.
+*/
+func (d *Dispatcher) Emit(event Event) error {
+	handlers := d.handlers[event.Name]
+	if len(handlers) == 0 {
+		return nil
+	}
+
+	payload, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+
+	var errs []error
+
+	for _, handler := range handlers {
+		if err := handler(event, payload); err != nil {
+			if event.IsPre() {
+				return err
+			}
+
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
@@ -0,0 +1,71 @@
+package events
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+)
+
+func TestEmit(t *testing.T) {
+	dispatcher := NewDispatcher()
+	payloads := []string{}
+
+	dispatcher.Subscribe(PostDeploy, func(event Event, payload []byte) error {
+		payloads = append(payloads, string(payload))
+		return nil
+	})
+
+	if err := dispatcher.Emit(Event{Name: PreDeploy, ProjectPath: "/trellis"}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	if err := dispatcher.Emit(Event{Name: PostDeploy, ProjectPath: "/trellis", Environment: "production", Site: "example.com", Status: StatusSuccess}); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	expected := []string{`{"event":"post-deploy","project_path":"/trellis","environment":"production","site":"example.com","status":"success"}`}
+
+	if !reflect.DeepEqual(payloads, expected) {
+		t.Errorf("expected payloads %v to be %v", payloads, expected)
+	}
+}
+
+func TestEmitPreEventStopsAtFirstError(t *testing.T) {
+	dispatcher := NewDispatcher()
+	calls := 0
+
+	for i := 0; i < 2; i++ {
+		dispatcher.Subscribe(PreProvision, func(event Event, payload []byte) error {
+			calls++
+			return errors.New("hook failed")
+		})
+	}
+
+	if err := dispatcher.Emit(Event{Name: PreProvision}); err == nil || err.Error() != "hook failed" {
+		t.Errorf("expected hook failed error, got %v", err)
+	}
+
+	if calls != 1 {
+		t.Errorf("expected 1 handler to be called, got %d", calls)
+	}
+}
+
+func TestEmitPostEventRunsAllHandlers(t *testing.T) {
+	dispatcher := NewDispatcher()
+	calls := 0
+
+	for i := 0; i < 2; i++ {
+		dispatcher.Subscribe(PostProvision, func(event Event, payload []byte) error {
+			calls++
+			return errors.New("hook failed")
+		})
+	}
+
+	if err := dispatcher.Emit(Event{Name: PostProvision}); err == nil {
+		t.Error("expected error")
+	}
+
+	if calls != 2 {
+		t.Errorf("expected 2 handlers to be called, got %d", calls)
+	}
+}
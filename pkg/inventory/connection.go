@@ -0,0 +1,35 @@
+package inventory
+
+import (
+	"fmt"
+	"strconv"
+)
+
+// The SSH connection details of an inventory host.
+type Connection struct {
+	// Name of the host in the inventory
+	Name string
+	// Address to connect to (ansible_host or the inventory name)
+	Host string
+	// ansible_port (0 when not set)
+	Port int
+	// ansible_user (empty when not set)
+	User string
+}
+
+func (c Connection) String() string {
+	if c.User == "" {
+		return c.Host
+	}
+
+	return fmt.Sprintf("%s@%s", c.User, c.Host)
+}
+
+// Returns the arguments needed by 'ssh' to connect to the host.
+func (c Connection) SshArgs() []string {
+	if c.Port == 0 || c.Port == 22 {
+		return []string{c.String()}
+	}
+
+	return []string{"-p", strconv.Itoa(c.Port), c.String()}
+}
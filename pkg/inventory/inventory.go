@@ -0,0 +1,565 @@
+package inventory
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	AllGroup       = "all"
+	UngroupedGroup = "ungrouped"
+)
+
+type Host struct {
+	Name     string
+	Vars     map[string]string
+	FileVars map[string]string
+}
+
+type Group struct {
+	Name     string
+	Hosts    []string
+	Children []string
+	Vars     map[string]string
+	FileVars map[string]string
+	parents  []string
+}
+
+/*
+An Ansible inventory parsed from the INI format (eg: Trellis' 'hosts/production').
+
+Supports groups, '[group:children]', '[group:vars]', inline host variables,
+numeric host ranges (eg: 'web[01:03].example.com'), and variables from
+'group_vars' and 'host_vars' directories via LoadVarsDir.
+*/
+type Inventory struct {
+	Groups    map[string]*Group
+	Hosts     map[string]*Host
+	hostNames []string
+}
+
+func New() *Inventory {
+	inventory := &Inventory{
+		Groups: make(map[string]*Group),
+		Hosts:  make(map[string]*Host),
+	}
+
+	inventory.group(AllGroup)
+	inventory.group(UngroupedGroup)
+
+	return inventory
+}
+
+func Load(path string) (*Inventory, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	inventory, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("Error parsing inventory %s: %w", path, err)
+	}
+
+	return inventory, nil
+}
+
+func Parse(r io.Reader) (*Inventory, error) {
+	inventory := New()
+	scanner := bufio.NewScanner(r)
+
+	section := UngroupedGroup
+	sectionType := "hosts"
+	lineNumber := 0
+
+	for scanner.Scan() {
+		lineNumber++
+		line := strings.TrimSpace(scanner.Text())
+
+		if line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, ";") {
+			continue
+		}
+
+		if strings.HasPrefix(line, "[") {
+			if !strings.HasSuffix(line, "]") {
+				return nil, fmt.Errorf("line %d: invalid section %q", lineNumber, line)
+			}
+
+			section, sectionType = parseSection(line[1 : len(line)-1])
+			inventory.group(section)
+			continue
+		}
+
+		tokens, err := tokenize(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+		}
+
+		if len(tokens) == 0 {
+			continue
+		}
+
+		switch sectionType {
+		case "vars":
+			key, value, ok := parseVar(line)
+			if !ok {
+				return nil, fmt.Errorf("line %d: expected key=value in [%s:vars]", lineNumber, section)
+			}
+
+			inventory.group(section).Vars[key] = value
+		case "children":
+			child := inventory.group(tokens[0])
+			child.parents = appendUnique(child.parents, section)
+			group := inventory.group(section)
+			group.Children = appendUnique(group.Children, tokens[0])
+		default:
+			if err := inventory.addHosts(section, tokens); err != nil {
+				return nil, fmt.Errorf("line %d: %w", lineNumber, err)
+			}
+		}
+	}
+
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return inventory, nil
+}
+
+/*
+Loads variables from the 'group_vars' and 'host_vars' directories in dir.
+Both '<name>.yml' files and '<name>/*.yml' directories are supported.
+Files which can't be parsed as a YAML map (eg: vault encrypted files) are skipped.
+Only scalar values are loaded.
+*/
+func (i *Inventory) LoadVarsDir(dir string) error {
+	for name, group := range i.Groups {
+		vars, err := loadVarsFiles(filepath.Join(dir, "group_vars", name))
+		if err != nil {
+			return err
+		}
+
+		group.FileVars = vars
+	}
+
+	for name, host := range i.Hosts {
+		vars, err := loadVarsFiles(filepath.Join(dir, "host_vars", name))
+		if err != nil {
+			return err
+		}
+
+		host.FileVars = vars
+	}
+
+	return nil
+}
+
+// Returns the names of all hosts in a group, including hosts in its child groups.
+func (i *Inventory) GroupHosts(name string) []string {
+	if name == AllGroup {
+		return append([]string{}, i.hostNames...)
+	}
+
+	hosts := []string{}
+	seen := map[string]bool{}
+	i.collectHosts(name, &hosts, seen, map[string]bool{})
+
+	return hosts
+}
+
+// Returns the names of hosts which are members of every group (eg: Ansible's 'web:&production').
+func (i *Inventory) Intersect(groups ...string) []string {
+	if len(groups) == 0 {
+		return []string{}
+	}
+
+	hosts := i.GroupHosts(groups[0])
+
+	for _, group := range groups[1:] {
+		members := map[string]bool{}
+		for _, host := range i.GroupHosts(group) {
+			members[host] = true
+		}
+
+		filtered := []string{}
+		for _, host := range hosts {
+			if members[host] {
+				filtered = append(filtered, host)
+			}
+		}
+
+		hosts = filtered
+	}
+
+	return hosts
+}
+
+// Returns the names of all groups the host belongs to (directly or through child groups).
+func (i *Inventory) HostGroups(name string) []string {
+	groups := []string{}
+
+	for groupName := range i.Groups {
+		for _, host := range i.GroupHosts(groupName) {
+			if host == name {
+				groups = append(groups, groupName)
+				break
+			}
+		}
+	}
+
+	sort.Slice(groups, func(a, b int) bool {
+		depthA, depthB := i.depth(groups[a]), i.depth(groups[b])
+		if depthA != depthB {
+			return depthA < depthB
+		}
+
+		return groups[a] < groups[b]
+	})
+
+	return groups
+}
+
+/*
+Returns a host's variables merged in Ansible's order of precedence (lowest first):
+
+ 1. inventory group vars ('[group:vars]'), 'all' first then by group depth
+ 2. 'group_vars/all'
+ 3. 'group_vars/<group>', by group depth
+ 4. inventory host vars
+ 5. 'host_vars/<host>'
+*/
+func (i *Inventory) HostVars(name string) map[string]string {
+	vars := map[string]string{}
+	host, ok := i.Hosts[name]
+	if !ok {
+		return vars
+	}
+
+	groups := i.HostGroups(name)
+
+	for _, group := range groups {
+		mergeVars(vars, i.Groups[group].Vars)
+	}
+
+	for _, group := range groups {
+		mergeVars(vars, i.Groups[group].FileVars)
+	}
+
+	mergeVars(vars, host.Vars)
+	mergeVars(vars, host.FileVars)
+
+	return vars
+}
+
+func (i *Inventory) Connection(name string) Connection {
+	vars := i.HostVars(name)
+	connection := Connection{Name: name, Host: name}
+
+	if host := resolvedVar(vars, "ansible_host"); host != "" {
+		connection.Host = host
+	}
+
+	if user := resolvedVar(vars, "ansible_user"); user != "" {
+		connection.User = user
+	}
+
+	if port, err := strconv.Atoi(resolvedVar(vars, "ansible_port")); err == nil {
+		connection.Port = port
+	}
+
+	return connection
+}
+
+func (i *Inventory) addHosts(section string, tokens []string) error {
+	name := tokens[0]
+	vars := map[string]string{}
+
+	if host, port, ok := splitHostPort(name); ok {
+		name = host
+		vars["ansible_port"] = port
+	}
+
+	for _, token := range tokens[1:] {
+		key, value, ok := strings.Cut(token, "=")
+		if !ok {
+			return fmt.Errorf("expected key=value host variable, got %q", token)
+		}
+
+		vars[key] = value
+	}
+
+	names, err := expandHostRange(name)
+	if err != nil {
+		return err
+	}
+
+	group := i.group(section)
+
+	for _, name := range names {
+		host, ok := i.Hosts[name]
+		if !ok {
+			host = &Host{Name: name, Vars: map[string]string{}, FileVars: map[string]string{}}
+			i.Hosts[name] = host
+			i.hostNames = append(i.hostNames, name)
+		}
+
+		mergeVars(host.Vars, vars)
+		group.Hosts = appendUnique(group.Hosts, name)
+	}
+
+	return nil
+}
+
+func (i *Inventory) collectHosts(name string, hosts *[]string, seen map[string]bool, visited map[string]bool) {
+	group, ok := i.Groups[name]
+	if !ok || visited[name] {
+		return
+	}
+
+	visited[name] = true
+
+	for _, host := range group.Hosts {
+		if !seen[host] {
+			seen[host] = true
+			*hosts = append(*hosts, host)
+		}
+	}
+
+	for _, child := range group.Children {
+		i.collectHosts(child, hosts, seen, visited)
+	}
+}
+
+// Returns the distance of a group from 'all'. Deeper groups have higher precedence.
+func (i *Inventory) depth(name string) int {
+	return i.depthVisited(name, map[string]bool{})
+}
+
+func (i *Inventory) depthVisited(name string, visited map[string]bool) int {
+	group, ok := i.Groups[name]
+	if name == AllGroup || !ok || visited[name] {
+		return 0
+	}
+
+	visited[name] = true
+	depth := 1
+
+	for _, parent := range group.parents {
+		if d := i.depthVisited(parent, visited) + 1; d > depth {
+			depth = d
+		}
+	}
+
+	return depth
+}
+
+func (i *Inventory) group(name string) *Group {
+	group, ok := i.Groups[name]
+	if !ok {
+		group = &Group{Name: name, Vars: map[string]string{}, FileVars: map[string]string{}}
+		i.Groups[name] = group
+	}
+
+	return group
+}
+
+func parseSection(name string) (section string, sectionType string) {
+	section, sectionType, found := strings.Cut(strings.TrimSpace(name), ":")
+
+	if !found || (sectionType != "vars" && sectionType != "children") {
+		return strings.TrimSpace(name), "hosts"
+	}
+
+	return section, sectionType
+}
+
+func parseVar(line string) (key string, value string, ok bool) {
+	key, value, ok = strings.Cut(line, "=")
+	if !ok {
+		return "", "", false
+	}
+
+	tokens, err := tokenize(value)
+	if err != nil {
+		return "", "", false
+	}
+
+	return strings.TrimSpace(key), strings.Join(tokens, " "), true
+}
+
+// Splits a line into whitespace separated tokens, respecting quotes and stripping comments.
+func tokenize(line string) ([]string, error) {
+	tokens := []string{}
+	var current strings.Builder
+	var quote rune
+	inToken := false
+
+	for _, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			} else {
+				current.WriteRune(r)
+			}
+		case r == '"' || r == '\'':
+			quote = r
+			inToken = true
+		case r == '#' && !inToken:
+			return tokens, nil
+		case r == ' ' || r == '\t':
+			if inToken {
+				tokens = append(tokens, current.String())
+				current.Reset()
+				inToken = false
+			}
+		default:
+			current.WriteRune(r)
+			inToken = true
+		}
+	}
+
+	if quote != 0 {
+		return nil, fmt.Errorf("unterminated quote in %q", line)
+	}
+
+	if inToken {
+		tokens = append(tokens, current.String())
+	}
+
+	return tokens, nil
+}
+
+// Splits 'host:port' entries. IPv6 addresses (multiple colons) are not split.
+func splitHostPort(name string) (host string, port string, ok bool) {
+	if strings.Count(name, ":") != 1 || strings.Contains(name, "[") {
+		return "", "", false
+	}
+
+	host, port, _ = strings.Cut(name, ":")
+	if _, err := strconv.Atoi(port); err != nil {
+		return "", "", false
+	}
+
+	return host, port, true
+}
+
+// Expands numeric host ranges such as 'web[01:03].example.com'.
+func expandHostRange(name string) ([]string, error) {
+	start := strings.Index(name, "[")
+	end := strings.Index(name, "]")
+
+	if start == -1 || end < start {
+		return []string{name}, nil
+	}
+
+	bounds := strings.Split(name[start+1:end], ":")
+	if len(bounds) != 2 {
+		return nil, fmt.Errorf("invalid host range %q", name)
+	}
+
+	low, lowErr := strconv.Atoi(bounds[0])
+	high, highErr := strconv.Atoi(bounds[1])
+	if lowErr != nil || highErr != nil || low > high {
+		return nil, fmt.Errorf("invalid host range %q", name)
+	}
+
+	width := 0
+	if strings.HasPrefix(bounds[0], "0") {
+		width = len(bounds[0])
+	}
+
+	names := []string{}
+	for n := low; n <= high; n++ {
+		expanded, err := expandHostRange(fmt.Sprintf("%s%0*d%s", name[:start], width, n, name[end+1:]))
+		if err != nil {
+			return nil, err
+		}
+
+		names = append(names, expanded...)
+	}
+
+	return names, nil
+}
+
+func loadVarsFiles(path string) (map[string]string, error) {
+	vars := map[string]string{}
+	files := []string{}
+
+	for _, ext := range []string{"", ".yml", ".yaml"} {
+		if info, err := os.Stat(path + ext); err == nil && !info.IsDir() {
+			files = append(files, path+ext)
+		}
+	}
+
+	if info, err := os.Stat(path); err == nil && info.IsDir() {
+		entries, err := os.ReadDir(path)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, entry := range entries {
+			ext := filepath.Ext(entry.Name())
+			if !entry.IsDir() && (ext == ".yml" || ext == ".yaml") {
+				files = append(files, filepath.Join(path, entry.Name()))
+			}
+		}
+	}
+
+	for _, file := range files {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+
+		data := map[string]interface{}{}
+		if err := yaml.Unmarshal(content, &data); err != nil {
+			continue
+		}
+
+		for key, value := range data {
+			switch v := value.(type) {
+			case string, bool, int, float64:
+				vars[key] = fmt.Sprint(v)
+			}
+		}
+	}
+
+	return vars, nil
+}
+
+// Returns a variable's value unless it's a Jinja template which can't be resolved outside of Ansible.
+func resolvedVar(vars map[string]string, key string) string {
+	value := vars[key]
+
+	if strings.Contains(value, "{{") {
+		return ""
+	}
+
+	return value
+}
+
+func mergeVars(dest map[string]string, src map[string]string) {
+	for key, value := range src {
+		dest[key] = value
+	}
+}
+
+func appendUnique(items []string, item string) []string {
+	for _, existing := range items {
+		if existing == item {
+			return items
+		}
+	}
+
+	return append(items, item)
+}
@@ -0,0 +1,233 @@
+package inventory
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const multiServerInventory = `
+# Production servers
+[production]
+web1 ansible_host=10.0.0.1
+web2 ansible_host=10.0.0.2 ansible_port=2222 # secondary
+db1.example.com:2200
+
+[web]
+web[1:2]
+
+[db]
+db1.example.com
+
+[servers:children]
+web
+db
+
+[all:vars]
+ansible_user=root
+
+[web:vars]
+ansible_user = admin
+ansible_python_interpreter="/usr/bin/python3"
+`
+
+func parseString(t *testing.T, content string) *Inventory {
+	t.Helper()
+
+	inventory, err := Parse(strings.NewReader(content))
+	if err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	return inventory
+}
+
+func TestParseGroups(t *testing.T) {
+	inventory := parseString(t, multiServerInventory)
+
+	cases := []struct {
+		group string
+		hosts []string
+	}{
+		{"production", []string{"web1", "web2", "db1.example.com"}},
+		{"web", []string{"web1", "web2"}},
+		{"db", []string{"db1.example.com"}},
+		{"servers", []string{"web1", "web2", "db1.example.com"}},
+		{"all", []string{"web1", "web2", "db1.example.com"}},
+		{"missing", []string{}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.group, func(t *testing.T) {
+			hosts := inventory.GroupHosts(tc.group)
+
+			if !reflect.DeepEqual(hosts, tc.hosts) {
+				t.Errorf("expected hosts %q to equal %q", hosts, tc.hosts)
+			}
+		})
+	}
+}
+
+func TestIntersect(t *testing.T) {
+	inventory := parseString(t, multiServerInventory)
+
+	hosts := inventory.Intersect("web", "production")
+	expected := []string{"web1", "web2"}
+
+	if !reflect.DeepEqual(hosts, expected) {
+		t.Errorf("expected hosts %q to equal %q", hosts, expected)
+	}
+}
+
+func TestConnection(t *testing.T) {
+	inventory := parseString(t, multiServerInventory)
+
+	cases := []struct {
+		host       string
+		connection Connection
+		sshArgs    []string
+	}{
+		{
+			"web1",
+			Connection{Name: "web1", Host: "10.0.0.1", User: "admin"},
+			[]string{"admin@10.0.0.1"},
+		},
+		{
+			"web2",
+			Connection{Name: "web2", Host: "10.0.0.2", Port: 2222, User: "admin"},
+			[]string{"-p", "2222", "admin@10.0.0.2"},
+		},
+		{
+			"db1.example.com",
+			Connection{Name: "db1.example.com", Host: "db1.example.com", Port: 2200, User: "root"},
+			[]string{"-p", "2200", "root@db1.example.com"},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.host, func(t *testing.T) {
+			connection := inventory.Connection(tc.host)
+
+			if connection != tc.connection {
+				t.Errorf("expected connection %+v to equal %+v", connection, tc.connection)
+			}
+
+			if !reflect.DeepEqual(connection.SshArgs(), tc.sshArgs) {
+				t.Errorf("expected ssh args %q to equal %q", connection.SshArgs(), tc.sshArgs)
+			}
+		})
+	}
+}
+
+func TestHostVarsQuotedValues(t *testing.T) {
+	inventory := parseString(t, multiServerInventory)
+
+	value := inventory.HostVars("web1")["ansible_python_interpreter"]
+
+	if value != "/usr/bin/python3" {
+		t.Errorf("expected /usr/bin/python3, got %q", value)
+	}
+}
+
+func TestUngroupedHosts(t *testing.T) {
+	inventory := parseString(t, "1.2.3.4\n\n[production]\n5.6.7.8\n")
+
+	expected := []string{"1.2.3.4"}
+	if hosts := inventory.GroupHosts(UngroupedGroup); !reflect.DeepEqual(hosts, expected) {
+		t.Errorf("expected hosts %q to equal %q", hosts, expected)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	cases := []struct {
+		name    string
+		content string
+		err     string
+	}{
+		{"invalid_section", "[production\n1.2.3.4", "line 1: invalid section"},
+		{"invalid_host_var", "[production]\n1.2.3.4 ansible_port", "line 2: expected key=value host variable"},
+		{"invalid_group_var", "[production:vars]\nfoo", "line 2: expected key=value in [production:vars]"},
+		{"unterminated_quote", "[production]\n1.2.3.4 ansible_user=\"admin", "line 2: unterminated quote"},
+		{"invalid_range", "[production]\nweb[3:1]", "line 2: invalid host range"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := Parse(strings.NewReader(tc.content))
+
+			if err == nil || !strings.Contains(err.Error(), tc.err) {
+				t.Errorf("expected error to contain %q, got %v", tc.err, err)
+			}
+		})
+	}
+}
+
+func TestExpandHostRange(t *testing.T) {
+	hosts, err := expandHostRange("web[08:10].example.com")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"web08.example.com", "web09.example.com", "web10.example.com"}
+
+	if !reflect.DeepEqual(hosts, expected) {
+		t.Errorf("expected hosts %q to equal %q", hosts, expected)
+	}
+}
+
+func TestLoadVarsDir(t *testing.T) {
+	dir := t.TempDir()
+
+	files := map[string]string{
+		"group_vars/all/main.yml":           "ansible_user: root\nadmin_user: admin\n",
+		"group_vars/all/vault.yml":          "$ANSIBLE_VAULT;1.1;AES256\n6162636465\n",
+		"group_vars/production/main.yml":    "ansible_port: 2222\nwordpress_sites:\n  example.com: {}\n",
+		"group_vars/web.yml":                "ansible_user: \"{{ admin_user }}\"\n",
+		"host_vars/web2/main.yml":           "ansible_port: 22\n",
+		"group_vars/production/ignored.txt": "ansible_port: 1\n",
+	}
+
+	for path, content := range files {
+		path = filepath.Join(dir, path)
+
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+
+		if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	inventory := parseString(t, "[production]\nweb1\nweb2 ansible_port=2200\n\n[production:vars]\nansible_port=2000\n\n[web]\nweb1\nweb2\n")
+
+	if err := inventory.LoadVarsDir(dir); err != nil {
+		t.Fatalf("expected no error, got %v", err)
+	}
+
+	cases := []struct {
+		host       string
+		connection Connection
+	}{
+		// group_vars files override inventory group vars; templated ansible_user is ignored
+		{"web1", Connection{Name: "web1", Host: "web1", Port: 2222}},
+		// host_vars override inventory host vars
+		{"web2", Connection{Name: "web2", Host: "web2", Port: 22}},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.host, func(t *testing.T) {
+			connection := inventory.Connection(tc.host)
+
+			if connection != tc.connection {
+				t.Errorf("expected connection %+v to equal %+v", connection, tc.connection)
+			}
+		})
+	}
+
+	if _, ok := inventory.HostVars("web1")["wordpress_sites"]; ok {
+		t.Error("expected non-scalar vars to be skipped")
+	}
+}
@@ -5,7 +5,6 @@ import (
 	_ "embed"
 	"errors"
 	"fmt"
-	"os"
 	"path/filepath"
 	"regexp"
 	"text/template"
@@ -54,6 +53,7 @@ type Instance struct {
 	SshLocalPort  int    `json:"sshLocalPort,omitempty"`
 	Config        Config `json:"config"`
 	Username      string `json:"username,omitempty"`
+	runner        command.Runner
 }
 
 func (i *Instance) ConfigFile() string {
@@ -78,7 +78,7 @@ func (i *Instance) UpdateConfig() error {
 		return err
 	}
 
-	if err := os.WriteFile(i.ConfigFile(), contents.Bytes(), 0666); err != nil {
+	if err := command.WriteFile(i.runner, i.ConfigFile(), contents.Bytes(), 0666); err != nil {
 		return fmt.Errorf("%v: %w", ConfigErr, err)
 	}
 
@@ -92,16 +92,16 @@ func (i *Instance) CreateInventoryFile() error {
 
 	tpl := template.Must(template.New("lima").Parse(inventoryTemplate))
 
-	file, err := os.Create(i.InventoryFile)
-	if err != nil {
-		return fmt.Errorf("Could not create Ansible inventory file: %v", err)
-	}
+	var contents bytes.Buffer
 
-	err = tpl.Execute(file, i)
-	if err != nil {
+	if err := tpl.Execute(&contents, i); err != nil {
 		return fmt.Errorf("Could not template Ansible inventory file: %v", err)
 	}
 
+	if err := command.WriteFile(i.runner, i.InventoryFile, contents.Bytes(), 0666); err != nil {
+		return fmt.Errorf("Could not create Ansible inventory file: %v", err)
+	}
+
 	return nil
 }
 
@@ -112,7 +112,7 @@ Gets the IP address of the instance using the output of `ip route`:
   192.168.64.1 proto dhcp scope link src 192.168.64.2 metric 100
 */
 func (i *Instance) IP() (ip string, err error) {
-	output, err := command.Cmd(
+	output, err := command.New(i.runner).Cmd(
 		"limactl",
 		[]string{"shell", "--workdir", "/", i.Name, "ip", "route", "show", "dev", "lima0"},
 	).CombinedOutput()
@@ -141,6 +141,6 @@ func (i *Instance) Stopped() bool {
 }
 
 func (i *Instance) getUsername() ([]byte, error) {
-	user, err := command.Cmd("limactl", []string{"shell", i.Name, "whoami"}).Output()
+	user, err := command.New(i.runner).Cmd("limactl", []string{"shell", i.Name, "whoami"}).Output()
 	return user, err
 }
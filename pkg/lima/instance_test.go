@@ -213,7 +213,9 @@ func TestIP(t *testing.T) {
 			Output: mockOutput,
 		},
 	}
-	defer command.MockExecCommands(t, commands)()
+	runner := command.NewFakeRunner(commands...)
+	runner.Strict = true
+	instance.runner = runner
 
 	ip, err := instance.IP()
 	if err != nil {
@@ -226,7 +228,3 @@ func TestIP(t *testing.T) {
 		t.Errorf("expected %s\ngot %s", expected, ip)
 	}
 }
-
-func TestCommandHelperProcess(t *testing.T) {
-	command.CommandHelperProcess(t)
-}
@@ -13,12 +13,12 @@ const (
 	VersionRequired = ">= 0.15.0"
 )
 
-func Installed() error {
+func Installed(runner command.Runner) error {
 	if _, err := exec.LookPath("limactl"); err != nil {
 		return fmt.Errorf("Lima is not installed.")
 	}
 
-	output, err := command.Cmd("limactl", []string{"-v"}).Output()
+	output, err := command.New(runner).Cmd("limactl", []string{"-v"}).Output()
 	if err != nil {
 		return fmt.Errorf("Could get determine the version of Lima.")
 	}
@@ -33,11 +33,15 @@ type Manager struct {
 	HostsResolver vm.HostsResolver
 	ui            cli.Ui
 	trellis       *trellis.Trellis
+	// Instances "created" during a dry run
+	plannedInstances map[string]Instance
 }
 
 func NewManager(trellis *trellis.Trellis, ui cli.Ui) (manager *Manager, err error) {
-	if os.Getenv("TRELLIS_BYPASS_LIMA_REQUIREMENTS") != "1" {
-		if err := ensureRequirements(); err != nil {
+	dryRun := command.IsDryRun(trellis.Runner)
+
+	if os.Getenv("TRELLIS_BYPASS_LIMA_REQUIREMENTS") != "1" && !dryRun {
+		if err := ensureRequirements(trellis.Runner); err != nil {
 			return nil, err
 		}
 	}
@@ -45,7 +49,7 @@ func NewManager(trellis *trellis.Trellis, ui cli.Ui) (manager *Manager, err erro
 	limaConfigPath := filepath.Join(trellis.ConfigPath(), configDir)
 
 	hostNames := trellis.Environments["development"].AllHosts()
-	hostsResolver, err := vm.NewHostsResolver(trellis.CliConfig.Vm.HostsResolver, hostNames)
+	hostsResolver, err := vm.NewHostsResolver(trellis.CliConfig.Vm.HostsResolver, hostNames, trellis.Runner)
 
 	if err != nil {
 		return nil, err
@@ -59,8 +63,10 @@ func NewManager(trellis *trellis.Trellis, ui cli.Ui) (manager *Manager, err erro
 		ui:            ui,
 	}
 
-	if err = manager.createConfigPath(); err != nil {
-		return nil, fmt.Errorf("%w: %v", ConfigPathError, err)
+	if !dryRun {
+		if err = manager.createConfigPath(); err != nil {
+			return nil, fmt.Errorf("%w: %v", ConfigPathError, err)
+		}
 	}
 
 	return manager, nil
@@ -80,7 +86,8 @@ func (m *Manager) GetInstance(name string) (Instance, bool) {
 func (m *Manager) CreateInstance(name string) error {
 	instance := m.newInstance(name)
 
-	cmd := command.WithOptions(
+	cmd := command.New(
+		m.trellis.Runner,
 		command.WithTermOutput(),
 		command.WithLogging(m.ui),
 	).Cmd("limactl", []string{"create", "--tty=false", "--name=" + instance.Name, "-"})
@@ -91,7 +98,15 @@ func (m *Manager) CreateInstance(name string) error {
 	}
 
 	cmd.Stdin = configContents
-	return cmd.Run()
+	if err := cmd.Run(); err != nil {
+		return err
+	}
+
+	if command.IsDryRun(m.trellis.Runner) {
+		m.planInstance(instance)
+	}
+
+	return nil
 }
 
 func (m *Manager) DeleteInstance(name string) error {
@@ -103,7 +118,8 @@ func (m *Manager) DeleteInstance(name string) error {
 	}
 
 	if instance.Stopped() {
-		err := command.WithOptions(
+		err := command.New(
+			m.trellis.Runner,
 			command.WithTermOutput(),
 			command.WithLogging(m.ui),
 		).Cmd("limactl", []string{"delete", instance.Name}).Run()
@@ -122,24 +138,44 @@ func (m *Manager) OpenShell(name string, dir string, commandArgs []string) error
 	instance, ok := m.GetInstance(name)
 
 	if !ok {
-		m.ui.Info("VM does not exist for this project. Run `trellis vm start` to create it.")
-		return nil
+		return fmt.Errorf("%w. Run `trellis vm start` to create it.", vm.VmNotFoundErr)
 	}
 
 	if instance.Stopped() {
-		m.ui.Info("VM is not running. Run `trellis vm start` to start it.")
-		return nil
+		return fmt.Errorf("%w. Run `trellis vm start` to start it.", vm.VmNotRunningErr)
 	}
 
 	args := []string{"shell", "--workdir", dir, instance.Name}
 	args = append(args, commandArgs...)
 
-	return command.WithOptions(
+	return command.New(
+		m.trellis.Runner,
 		command.WithTermOutput(),
 		command.WithLogging(m.ui),
 	).Cmd("limactl", args).Run()
 }
 
+/*
+Returns a command which runs in the instance without attaching it to the
+terminal so its output can be read (eg: to stream log files).
+*/
+func (m *Manager) ShellCmd(name string, dir string, commandArgs []string) (*command.Cmd, error) {
+	instance, ok := m.GetInstance(name)
+
+	if !ok {
+		return nil, vm.VmNotFoundErr
+	}
+
+	if instance.Stopped() {
+		return nil, fmt.Errorf("%w. Run `trellis vm start` to start it.", vm.VmNotRunningErr)
+	}
+
+	args := []string{"shell", "--workdir", dir, instance.Name}
+	args = append(args, commandArgs...)
+
+	return command.New(m.trellis.Runner).Cmd("limactl", args), nil
+}
+
 func (m *Manager) StartInstance(name string) error {
 	instance, ok := m.GetInstance(name)
 
@@ -156,7 +192,8 @@ func (m *Manager) StartInstance(name string) error {
 		return err
 	}
 
-	err := command.WithOptions(
+	err := command.New(
+		m.trellis.Runner,
 		command.WithTermOutput(),
 		command.WithLogging(m.ui),
 	).Cmd("limactl", []string{"start", instance.Name}).Run()
@@ -165,6 +202,10 @@ func (m *Manager) StartInstance(name string) error {
 		return err
 	}
 
+	if command.IsDryRun(m.trellis.Runner) {
+		return m.planHosts(instance)
+	}
+
 	user, err := instance.getUsername()
 	if err != nil {
 		return fmt.Errorf("Could not get username: %v", err)
@@ -198,7 +239,8 @@ func (m *Manager) StopInstance(name string) error {
 		return nil
 	}
 
-	err := command.WithOptions(
+	err := command.New(
+		m.trellis.Runner,
 		command.WithTermOutput(),
 		command.WithLogging(m.ui),
 	).Cmd("limactl", []string{"stop", instance.Name}).Run()
@@ -230,6 +272,7 @@ func (m *Manager) hydrateInstance(instance *Instance) error {
 func (m *Manager) initInstance(instance *Instance) {
 	instance.InventoryFile = m.InventoryPath()
 	instance.Sites = m.Sites
+	instance.runner = m.trellis.Runner
 }
 
 func (m *Manager) newInstance(name string) Instance {
@@ -278,8 +321,17 @@ func (m *Manager) addHosts(instance Instance) error {
 func (m *Manager) instances() (instances map[string]Instance) {
 	instances = make(map[string]Instance)
 
+	// limactl isn't run during a dry run so only planned instances exist
+	if command.IsDryRun(m.trellis.Runner) {
+		for name, instance := range m.plannedInstances {
+			instances[name] = instance
+		}
+
+		return instances
+	}
+
 	// Returns line delimited JSON
-	output, _ := command.Cmd("limactl", []string{"ls", "--format=json"}).Output()
+	output, _ := command.New(m.trellis.Runner).Cmd("limactl", []string{"ls", "--format=json"}).Output()
 
 	for _, line := range bytes.Split(output, []byte("\n")) {
 		instance := &Instance{}
@@ -295,8 +347,41 @@ func (m *Manager) removeHosts(instance Instance) error {
 	return m.HostsResolver.RemoveHosts(instance.Name)
 }
 
-func getMacOSVersion() (string, error) {
-	cmd := command.Cmd("sw_vers", []string{"-productVersion"})
+// Records an instance as created (and stopped) so a dry run can plan starting it.
+func (m *Manager) planInstance(instance Instance) {
+	if m.plannedInstances == nil {
+		m.plannedInstances = make(map[string]Instance)
+	}
+
+	instance.Status = "Stopped"
+	instance.Dir = filepath.Join(limaHome(), instance.Name)
+	m.plannedInstances[instance.Name] = instance
+}
+
+/*
+The VM's SSH port and IP are only known once it's running, so a dry run plans
+the inventory and hosts file writes without their contents being accurate.
+*/
+func (m *Manager) planHosts(instance Instance) error {
+	if err := command.WriteFile(m.trellis.Runner, instance.InventoryFile, nil, 0644); err != nil {
+		return err
+	}
+
+	return m.HostsResolver.AddHosts(instance.Name, "<vm-ip>")
+}
+
+// Lima's data directory where each instance has its own directory (with its lima.yaml config).
+func limaHome() string {
+	if home := os.Getenv("LIMA_HOME"); home != "" {
+		return home
+	}
+
+	home, _ := os.UserHomeDir()
+	return filepath.Join(home, ".lima")
+}
+
+func getMacOSVersion(runner command.Runner) (string, error) {
+	cmd := command.New(runner).Cmd("sw_vers", []string{"-productVersion"})
 	b, err := cmd.Output()
 	if err != nil {
 		return "", err
@@ -307,8 +392,8 @@ func getMacOSVersion() (string, error) {
 	return version, nil
 }
 
-func ensureRequirements() error {
-	macOSVersion, err := getMacOSVersion()
+func ensureRequirements(runner command.Runner) error {
+	macOSVersion, err := getMacOSVersion(runner)
 	if err != nil {
 		return UnsupportedOSError
 	}
@@ -317,7 +402,7 @@ func ensureRequirements() error {
 		return fmt.Errorf("%w", UnsupportedOSError)
 	}
 
-	if err = Installed(); err != nil {
+	if err = Installed(runner); err != nil {
 		return fmt.Errorf(err.Error() + `
 Install or upgrade Lima to continue:
 
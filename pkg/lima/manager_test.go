@@ -1,13 +1,16 @@
 package lima
 
 import (
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 
 	"github.com/mitchellh/cli"
 	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/pkg/vm"
 	"github.com/roots/trellis-cli/trellis"
 )
 
@@ -40,7 +43,9 @@ func TestNewManager(t *testing.T) {
 			Output:  `limactl version 0.15.0`,
 		},
 	}
-	defer command.MockExecCommands(t, commands)()
+	runner := command.NewFakeRunner(commands...)
+	runner.Strict = true
+	trellis.Runner = runner
 
 	_, err := NewManager(trellis, cli.NewMockUi())
 	if err != nil {
@@ -68,7 +73,9 @@ func TestNewManagerUnsupportedOS(t *testing.T) {
 			Output:  `12.0.1`,
 		},
 	}
-	defer command.MockExecCommands(t, commands)()
+	runner := command.NewFakeRunner(commands...)
+	runner.Strict = true
+	trellis.Runner = runner
 
 	_, err := NewManager(trellis, cli.NewMockUi())
 	if err == nil {
@@ -156,7 +163,9 @@ func TestInstances(t *testing.T) {
 		},
 	}
 
-	defer command.MockExecCommands(t, commands)()
+	runner := command.NewFakeRunner(commands...)
+	runner.Strict = true
+	trellis.Runner = runner
 
 	manager, err := NewManager(trellis, cli.NewMockUi())
 	if err != nil {
@@ -229,7 +238,9 @@ func TestCreateInstance(t *testing.T) {
 		},
 	}
 
-	defer command.MockExecCommands(t, commands)()
+	runner := command.NewFakeRunner(commands...)
+	runner.Strict = true
+	trellis.Runner = runner
 
 	if err = manager.CreateInstance(instanceName); err != nil {
 		t.Fatal(err)
@@ -298,7 +309,9 @@ func TestStartInstance(t *testing.T) {
 		},
 	}
 
-	defer command.MockExecCommands(t, commands)()
+	runner := command.NewFakeRunner(commands...)
+	runner.Strict = true
+	trellis.Runner = runner
 
 	if err = manager.CreateInstance(instanceName); err != nil {
 		t.Fatal(err)
@@ -331,6 +344,60 @@ default
 	}
 }
 
+func TestStartInstanceDryRun(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+	trellis := trellis.NewTrellis()
+	if err := trellis.LoadProject(); err != nil {
+		t.Fatal(err)
+	}
+
+	limaHome := t.TempDir()
+	t.Setenv("LIMA_HOME", limaHome)
+
+	var out strings.Builder
+	trellis.Runner = command.NewDryRunner(&out)
+
+	manager, err := NewManager(trellis, cli.NewMockUi())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	hostsStorage := make(map[string]string)
+	manager.HostsResolver = &MockHostsResolver{Hosts: hostsStorage}
+
+	instanceName := "test"
+
+	if _, ok := manager.GetInstance(instanceName); ok {
+		t.Fatalf("expected no instance to exist")
+	}
+
+	if err = manager.CreateInstance(instanceName); err != nil {
+		t.Fatal(err)
+	}
+
+	if err = manager.StartInstance(instanceName); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := fmt.Sprintf(`[dry-run] limactl create --tty=false --name=test -
+[dry-run] write %s
+[dry-run] limactl start test
+[dry-run] write %s
+`, filepath.Join(limaHome, instanceName, "lima.yaml"), manager.InventoryPath())
+
+	if out.String() != expected {
+		t.Errorf("expected output:\n%s\ngot:\n%s", expected, out.String())
+	}
+
+	if _, err := os.Stat(manager.ConfigPath); !os.IsNotExist(err) {
+		t.Errorf("expected %s not to be created", manager.ConfigPath)
+	}
+
+	if hostsStorage[instanceName] != "<vm-ip>" {
+		t.Errorf("expected hosts entry to be planned, got %q", hostsStorage[instanceName])
+	}
+}
+
 func newMockHostsResolver(hosts map[string]string) MockHostsResolver {
 	return MockHostsResolver{Hosts: hosts}
 }
@@ -344,3 +411,52 @@ func (h *MockHostsResolver) RemoveHosts(name string) error {
 	delete(h.Hosts, name)
 	return nil
 }
+
+func TestOpenShellErrors(t *testing.T) {
+	defer trellis.LoadFixtureProject(t)()
+	trellis := trellis.NewTrellis()
+	if err := trellis.LoadProject(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("TRELLIS_BYPASS_LIMA_REQUIREMENTS", "1")
+
+	cases := []struct {
+		name      string
+		instances string
+		err       error
+	}{
+		{
+			"missing",
+			"",
+			vm.VmNotFoundErr,
+		},
+		{
+			"stopped",
+			`{"name":"test","status":"Stopped","dir":"/foo/test","vmType":"vz","arch":"aarch64","cpuType":"","cpus":4,"memory":4294967296,"disk":107374182400,"sshLocalPort":60720}`,
+			vm.VmNotRunningErr,
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			runner := command.NewFakeRunner(command.MockCommand{
+				Command: "limactl",
+				Args:    []string{"ls", "--format=json"},
+				Output:  tc.instances,
+			})
+			runner.Strict = true
+			trellis.Runner = runner
+
+			manager, err := NewManager(trellis, cli.NewMockUi())
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			err = manager.OpenShell("test", "/", []string{"ls"})
+			if !errors.Is(err, tc.err) {
+				t.Errorf("expected error %v, got %v", tc.err, err)
+			}
+		})
+	}
+}
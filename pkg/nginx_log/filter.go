@@ -0,0 +1,98 @@
+package nginx_log
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Selects which log entries are shown or summarized. Zero values match everything.
+type Filter struct {
+	// Status codes (eg: 404) or classes (eg: 5xx)
+	Statuses []string
+	// Request path prefix (the query string is ignored)
+	Path string
+	// Only entries logged at or after this time
+	Since time.Time
+}
+
+/*
+Parses a comma separated list of status codes and classes (eg: "5xx", "404",
+"4xx,5xx") into a filter's Statuses.
+*/
+func ParseStatuses(value string) ([]string, error) {
+	statuses := []string{}
+
+	for _, status := range strings.Split(value, ",") {
+		status = strings.ToLower(strings.TrimSpace(status))
+		if status == "" {
+			continue
+		}
+
+		valid := len(status) == 3 && status[0] >= '1' && status[0] <= '5'
+
+		if valid && status[1:] != "xx" {
+			_, err := strconv.Atoi(status)
+			valid = err == nil
+		}
+
+		if !valid {
+			return nil, fmt.Errorf("invalid status %q (expected a status code like 404 or class like 5xx)", status)
+		}
+
+		statuses = append(statuses, status)
+	}
+
+	return statuses, nil
+}
+
+/*
+Parses a duration relative to now (eg: "30m", "1h", "2d") for a filter's Since.
+Days are supported in addition to time.ParseDuration's units.
+*/
+func ParseSince(value string, now time.Time) (time.Time, error) {
+	if days, found := strings.CutSuffix(value, "d"); found {
+		n, err := strconv.Atoi(days)
+		if err != nil || n < 0 {
+			return time.Time{}, fmt.Errorf("invalid duration %q", value)
+		}
+
+		return now.AddDate(0, 0, -n), nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil || duration < 0 {
+		return time.Time{}, fmt.Errorf("invalid duration %q (eg: 30m, 1h, 2d)", value)
+	}
+
+	return now.Add(-duration), nil
+}
+
+func (f Filter) Empty() bool {
+	return len(f.Statuses) == 0 && f.Path == "" && f.Since.IsZero()
+}
+
+func (f Filter) Match(e Entry) bool {
+	if !f.Since.IsZero() && e.Time.Before(f.Since) {
+		return false
+	}
+
+	if f.Path != "" && !strings.HasPrefix(e.PathWithoutQuery(), f.Path) {
+		return false
+	}
+
+	if len(f.Statuses) == 0 {
+		return true
+	}
+
+	code := strconv.Itoa(e.Status)
+
+	for _, status := range f.Statuses {
+		if status == code || (strings.HasSuffix(status, "xx") && status[0] == code[0]) {
+			return true
+		}
+	}
+
+	return false
+}
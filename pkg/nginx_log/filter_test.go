@@ -0,0 +1,81 @@
+package nginx_log
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestParseStatuses(t *testing.T) {
+	statuses, err := ParseStatuses("5xx, 404,4XX")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []string{"5xx", "404", "4xx"}
+
+	if !reflect.DeepEqual(statuses, expected) {
+		t.Errorf("expected %v to be %v", statuses, expected)
+	}
+
+	for _, invalid := range []string{"6xx", "40", "4x4", "foo"} {
+		if _, err := ParseStatuses(invalid); err == nil {
+			t.Errorf("expected %q to be invalid", invalid)
+		}
+	}
+}
+
+func TestParseSince(t *testing.T) {
+	now := time.Date(2024, 10, 10, 12, 0, 0, 0, time.UTC)
+
+	cases := map[string]time.Time{
+		"1h":  time.Date(2024, 10, 10, 11, 0, 0, 0, time.UTC),
+		"30m": time.Date(2024, 10, 10, 11, 30, 0, 0, time.UTC),
+		"2d":  time.Date(2024, 10, 8, 12, 0, 0, 0, time.UTC),
+	}
+
+	for value, expected := range cases {
+		since, err := ParseSince(value, now)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if !since.Equal(expected) {
+			t.Errorf("expected %s to be %v, got %v", value, expected, since)
+		}
+	}
+
+	for _, invalid := range []string{"", "1y", "-1h", "xd"} {
+		if _, err := ParseSince(invalid, now); err == nil {
+			t.Errorf("expected %q to be invalid", invalid)
+		}
+	}
+}
+
+func TestFilterMatch(t *testing.T) {
+	now := time.Date(2024, 10, 10, 12, 0, 0, 0, time.UTC)
+	entry := Entry{Status: 503, Path: "/wp-json/wp/v2/posts?page=2", Time: now}
+
+	cases := []struct {
+		name     string
+		filter   Filter
+		expected bool
+	}{
+		{"empty", Filter{}, true},
+		{"status_class", Filter{Statuses: []string{"5xx"}}, true},
+		{"status_code", Filter{Statuses: []string{"404", "503"}}, true},
+		{"other_status", Filter{Statuses: []string{"4xx"}}, false},
+		{"path_prefix", Filter{Path: "/wp-json/"}, true},
+		{"path_excludes_query", Filter{Path: "/wp-json/wp/v2/posts?page"}, false},
+		{"since", Filter{Since: now.Add(-time.Hour)}, true},
+		{"too_old", Filter{Since: now.Add(time.Minute)}, false},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			if actual := tc.filter.Match(entry); actual != tc.expected {
+				t.Errorf("expected match to be %v", tc.expected)
+			}
+		})
+	}
+}
@@ -0,0 +1,192 @@
+package nginx_log
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const timeLayout = "02/Jan/2006:15:04:05 -0700"
+
+var (
+	ParseErr = errors.New("line does not match the Nginx log format")
+
+	/*
+	   Nginx's combined log format:
+	     $remote_addr - $remote_user [$time_local] "$request" $status $body_bytes_sent "$http_referer" "$http_user_agent"
+	   Any remaining fields (eg: "$http_x_forwarded_for" $request_time $upstream_response_time) are captured last.
+	*/
+	linePattern = regexp.MustCompile(`^(\S+) \S+ (\S+) \[([^\]]+)\] "((?:[^"\\]|\\.)*)" (\d{3}) (\d+|-) "((?:[^"\\]|\\.)*)" "((?:[^"\\]|\\.)*)"(.*)$`)
+)
+
+// A request parsed from an Nginx access log line.
+type Entry struct {
+	RemoteAddr string    `json:"remote_addr"`
+	RemoteUser string    `json:"remote_user,omitempty"`
+	Time       time.Time `json:"time"`
+	Method     string    `json:"method"`
+	Path       string    `json:"path"`
+	Protocol   string    `json:"protocol"`
+	Status     int       `json:"status"`
+	BodyBytes  int64     `json:"body_bytes_sent"`
+	Referer    string    `json:"referer,omitempty"`
+	UserAgent  string    `json:"user_agent,omitempty"`
+	// Seconds (0 when the log format doesn't include $request_time)
+	RequestTime float64 `json:"request_time,omitempty"`
+	// Seconds (0 when the log format doesn't include $upstream_response_time)
+	UpstreamTime float64 `json:"upstream_time,omitempty"`
+}
+
+/*
+Parses a line in Nginx's combined log format. Trellis' format extends it with
+extra fields which are optional:
+  - quoted values (eg: "$http_x_forwarded_for") are ignored
+  - rt=/request_time= and urt=/upstream_response_time= values are used as is
+  - otherwise the first bare number is $request_time and the second $upstream_response_time
+*/
+func Parse(line string) (Entry, error) {
+	match := linePattern.FindStringSubmatch(strings.TrimRight(line, "\r\n"))
+	if match == nil {
+		return Entry{}, ParseErr
+	}
+
+	t, err := time.Parse(timeLayout, match[3])
+	if err != nil {
+		return Entry{}, ParseErr
+	}
+
+	entry := Entry{
+		RemoteAddr: match[1],
+		Time:       t,
+		Referer:    unescape(match[7]),
+		UserAgent:  unescape(match[8]),
+	}
+
+	if match[2] != "-" {
+		entry.RemoteUser = match[2]
+	}
+
+	request := strings.SplitN(unescape(match[4]), " ", 3)
+	switch len(request) {
+	case 3:
+		entry.Method, entry.Path, entry.Protocol = request[0], request[1], request[2]
+	case 2:
+		entry.Method, entry.Path = request[0], request[1]
+	default:
+		// Invalid requests (eg: TLS handshakes to the HTTP port) are logged as-is
+		entry.Path = request[0]
+	}
+
+	entry.Status, _ = strconv.Atoi(match[5])
+
+	if match[6] != "-" {
+		entry.BodyBytes, _ = strconv.ParseInt(match[6], 10, 64)
+	}
+
+	if entry.Referer == "-" {
+		entry.Referer = ""
+	}
+
+	entry.parseExtraFields(match[9])
+
+	return entry, nil
+}
+
+func (e *Entry) parseExtraFields(fields string) {
+	numbers := []float64{}
+
+	for _, field := range splitFields(fields) {
+		if strings.HasPrefix(field, `"`) {
+			continue
+		}
+
+		key, value, found := strings.Cut(field, "=")
+		if !found {
+			value = key
+			key = ""
+		}
+
+		seconds, ok := parseSeconds(strings.Trim(value, `"`))
+		if !ok {
+			continue
+		}
+
+		switch key {
+		case "rt", "request_time":
+			e.RequestTime = seconds
+		case "urt", "upstream_response_time":
+			e.UpstreamTime = seconds
+		case "":
+			numbers = append(numbers, seconds)
+		}
+	}
+
+	if len(numbers) > 0 && e.RequestTime == 0 {
+		e.RequestTime = numbers[0]
+	}
+
+	if len(numbers) > 1 && e.UpstreamTime == 0 {
+		e.UpstreamTime = numbers[1]
+	}
+}
+
+// Returns the request path without its query string.
+func (e Entry) PathWithoutQuery() string {
+	path, _, _ := strings.Cut(e.Path, "?")
+	return path
+}
+
+/*
+Parses a duration in seconds as logged by Nginx. Multiple upstream times
+(eg: "0.100, 0.200" when retrying upstreams) are added together.
+*/
+func parseSeconds(value string) (float64, bool) {
+	total := 0.0
+	parsed := false
+
+	for _, part := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == ':' }) {
+		seconds, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			continue
+		}
+
+		total += seconds
+		parsed = true
+	}
+
+	return total, parsed
+}
+
+// Splits fields on spaces while keeping quoted values (and "a, b" upstream lists) together.
+func splitFields(fields string) []string {
+	result := []string{}
+	var current strings.Builder
+	quoted := false
+
+	for i, r := range fields {
+		switch {
+		case r == '"' && (i == 0 || fields[i-1] != '\\'):
+			quoted = !quoted
+			current.WriteRune(r)
+		case r == ' ' && !quoted:
+			if current.Len() > 0 && !strings.HasSuffix(current.String(), ",") {
+				result = append(result, current.String())
+				current.Reset()
+			}
+		default:
+			current.WriteRune(r)
+		}
+	}
+
+	if current.Len() > 0 {
+		result = append(result, current.String())
+	}
+
+	return result
+}
+
+func unescape(value string) string {
+	return strings.ReplaceAll(value, `\"`, `"`)
+}
@@ -0,0 +1,102 @@
+package nginx_log
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParse(t *testing.T) {
+	cases := []struct {
+		name     string
+		line     string
+		expected Entry
+	}{
+		{
+			"combined",
+			`1.2.3.4 - - [10/Oct/2024:13:55:36 +0000] "GET /about/?ref=home HTTP/1.1" 200 2326 "https://example.com/" "Mozilla/5.0 (X11)"`,
+			Entry{
+				RemoteAddr: "1.2.3.4",
+				Time:       time.Date(2024, 10, 10, 13, 55, 36, 0, time.UTC),
+				Method:     "GET",
+				Path:       "/about/?ref=home",
+				Protocol:   "HTTP/1.1",
+				Status:     200,
+				BodyBytes:  2326,
+				Referer:    "https://example.com/",
+				UserAgent:  "Mozilla/5.0 (X11)",
+			},
+		},
+		{
+			"forwarded_for_and_times",
+			`1.2.3.4 - admin [10/Oct/2024:13:55:36 +0000] "POST /wp-admin/admin-ajax.php HTTP/2.0" 502 0 "-" "curl/8.0" "5.6.7.8" 1.503 1.500`,
+			Entry{
+				RemoteAddr:   "1.2.3.4",
+				RemoteUser:   "admin",
+				Time:         time.Date(2024, 10, 10, 13, 55, 36, 0, time.UTC),
+				Method:       "POST",
+				Path:         "/wp-admin/admin-ajax.php",
+				Protocol:     "HTTP/2.0",
+				Status:       502,
+				UserAgent:    "curl/8.0",
+				RequestTime:  1.503,
+				UpstreamTime: 1.5,
+			},
+		},
+		{
+			"key_value_times_with_multiple_upstreams",
+			`1.2.3.4 - - [10/Oct/2024:13:55:36 +0000] "GET / HTTP/1.1" 200 12 "-" "-" rt=0.400 urt="0.100, 0.250"`,
+			Entry{
+				RemoteAddr:   "1.2.3.4",
+				Time:         time.Date(2024, 10, 10, 13, 55, 36, 0, time.UTC),
+				Method:       "GET",
+				Path:         "/",
+				Protocol:     "HTTP/1.1",
+				Status:       200,
+				BodyBytes:    12,
+				UserAgent:    "-",
+				RequestTime:  0.4,
+				UpstreamTime: 0.35,
+			},
+		},
+		{
+			"invalid_request",
+			`1.2.3.4 - - [10/Oct/2024:13:55:36 +0000] "\x16\x03\x01" 400 150 "-" "-"`,
+			Entry{
+				RemoteAddr: "1.2.3.4",
+				Time:       time.Date(2024, 10, 10, 13, 55, 36, 0, time.UTC),
+				Path:       `\x16\x03\x01`,
+				Status:     400,
+				BodyBytes:  150,
+				UserAgent:  "-",
+			},
+		},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			entry, err := Parse(tc.line)
+			if err != nil {
+				t.Fatalf("expected no error, got %v", err)
+			}
+
+			if !entry.Time.Equal(tc.expected.Time) {
+				t.Errorf("expected time %v to be %v", entry.Time, tc.expected.Time)
+			}
+
+			entry.Time = tc.expected.Time
+
+			if entry != tc.expected {
+				t.Errorf("expected %+v to be %+v", entry, tc.expected)
+			}
+		})
+	}
+}
+
+func TestParseInvalid(t *testing.T) {
+	for _, line := range []string{"", "foo bar", `1.2.3.4 - - [not a date] "GET / HTTP/1.1" 200 1 "-" "-"`} {
+		if _, err := Parse(line); !errors.Is(err, ParseErr) {
+			t.Errorf("expected %q to return ParseErr, got %v", line, err)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+package nginx_log
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+)
+
+// Requests logged within this window (before the newest entry) count towards the current rate.
+const rateWindow = 10 * time.Second
+
+// Aggregated statistics of log entries.
+type Summary struct {
+	Total    int
+	First    time.Time
+	Last     time.Time
+	Statuses map[string]int
+	Paths    map[string]int
+	IPs      map[string]int
+	// Slowest entries by upstream time (newest first for equal times)
+	Slowest []Entry
+
+	top    int
+	recent []time.Time
+}
+
+// A value and how often it occurred.
+type Count struct {
+	Value string
+	Count int
+}
+
+// Creates a summary which keeps track of the top N paths, IPs and slowest upstream responses.
+func NewSummary(top int) *Summary {
+	return &Summary{
+		Statuses: map[string]int{},
+		Paths:    map[string]int{},
+		IPs:      map[string]int{},
+		top:      top,
+	}
+}
+
+func (s *Summary) Add(e Entry) {
+	s.Total++
+
+	if s.First.IsZero() || e.Time.Before(s.First) {
+		s.First = e.Time
+	}
+
+	if e.Time.After(s.Last) {
+		s.Last = e.Time
+	}
+
+	s.Statuses[fmt.Sprintf("%dxx", e.Status/100)]++
+	s.Paths[e.PathWithoutQuery()]++
+	s.IPs[e.RemoteAddr]++
+
+	s.recent = append(s.recent, e.Time)
+	cutoff := s.Last.Add(-rateWindow)
+	for len(s.recent) > 0 && !s.recent[0].After(cutoff) {
+		s.recent = s.recent[1:]
+	}
+
+	if e.UpstreamTime > 0 {
+		s.addSlowest(e)
+	}
+}
+
+// Requests per second over the last 10 seconds of logged requests.
+func (s *Summary) CurrentRate() float64 {
+	return float64(len(s.recent)) / rateWindow.Seconds()
+}
+
+// Requests per second over the whole time range of logged requests.
+func (s *Summary) AverageRate() float64 {
+	seconds := s.Last.Sub(s.First).Seconds()
+	if seconds < 1 {
+		return float64(s.Total)
+	}
+
+	return float64(s.Total) / seconds
+}
+
+func (s *Summary) TopPaths() []Count {
+	return topCounts(s.Paths, s.top)
+}
+
+func (s *Summary) TopIPs() []Count {
+	return topCounts(s.IPs, s.top)
+}
+
+func (s *Summary) Render(w io.Writer) {
+	if s.Total == 0 {
+		fmt.Fprintln(w, "No requests found.")
+		return
+	}
+
+	fmt.Fprintf(w, "Requests: %d total (%s - %s)\n", s.Total, s.First.Format(time.DateTime), s.Last.Format(time.DateTime))
+	fmt.Fprintf(w, "Rate:     %.2f req/s current, %.2f req/s average\n", s.CurrentRate(), s.AverageRate())
+
+	statuses := []string{}
+	for _, class := range []string{"1xx", "2xx", "3xx", "4xx", "5xx"} {
+		if count := s.Statuses[class]; count > 0 {
+			statuses = append(statuses, fmt.Sprintf("%s %d (%.1f%%)", class, count, float64(count)*100/float64(s.Total)))
+		}
+	}
+	fmt.Fprintf(w, "Status:   %s\n", strings.Join(statuses, "  "))
+
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+
+	fmt.Fprintln(tw, "\nTop paths:")
+	for _, path := range s.TopPaths() {
+		fmt.Fprintf(tw, "  %d\t%s\n", path.Count, path.Value)
+	}
+
+	fmt.Fprintln(tw, "\nTop IPs:")
+	for _, ip := range s.TopIPs() {
+		fmt.Fprintf(tw, "  %d\t%s\n", ip.Count, ip.Value)
+	}
+
+	if len(s.Slowest) > 0 {
+		fmt.Fprintln(tw, "\nSlowest upstream responses:")
+		for _, e := range s.Slowest {
+			fmt.Fprintf(tw, "  %.3fs\t%d\t%s %s\t%s\n", e.UpstreamTime, e.Status, e.Method, e.Path, e.Time.Format(time.DateTime))
+		}
+	}
+
+	tw.Flush()
+}
+
+func (s *Summary) addSlowest(e Entry) {
+	i := sort.Search(len(s.Slowest), func(i int) bool {
+		return s.Slowest[i].UpstreamTime <= e.UpstreamTime
+	})
+
+	if i >= s.top {
+		return
+	}
+
+	s.Slowest = append(s.Slowest, Entry{})
+	copy(s.Slowest[i+1:], s.Slowest[i:])
+	s.Slowest[i] = e
+
+	if len(s.Slowest) > s.top {
+		s.Slowest = s.Slowest[:s.top]
+	}
+}
+
+func topCounts(counts map[string]int, n int) []Count {
+	result := make([]Count, 0, len(counts))
+
+	for value, count := range counts {
+		result = append(result, Count{Value: value, Count: count})
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Count == result[j].Count {
+			return result[i].Value < result[j].Value
+		}
+
+		return result[i].Count > result[j].Count
+	})
+
+	if len(result) > n {
+		result = result[:n]
+	}
+
+	return result
+}
@@ -0,0 +1,75 @@
+package nginx_log
+
+import (
+	"bytes"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSummary(t *testing.T) {
+	start := time.Date(2024, 10, 10, 12, 0, 0, 0, time.UTC)
+	summary := NewSummary(2)
+
+	entries := []Entry{
+		{RemoteAddr: "1.1.1.1", Time: start, Status: 200, Method: "GET", Path: "/", UpstreamTime: 0.1},
+		{RemoteAddr: "1.1.1.1", Time: start.Add(20 * time.Second), Status: 200, Method: "GET", Path: "/?s=foo", UpstreamTime: 0.3},
+		{RemoteAddr: "2.2.2.2", Time: start.Add(25 * time.Second), Status: 404, Method: "GET", Path: "/missing"},
+		{RemoteAddr: "3.3.3.3", Time: start.Add(30 * time.Second), Status: 502, Method: "POST", Path: "/wp-login.php", UpstreamTime: 2},
+	}
+
+	for _, entry := range entries {
+		summary.Add(entry)
+	}
+
+	if summary.Total != 4 {
+		t.Errorf("expected total %d to be 4", summary.Total)
+	}
+
+	expectedStatuses := map[string]int{"2xx": 2, "4xx": 1, "5xx": 1}
+	if !reflect.DeepEqual(summary.Statuses, expectedStatuses) {
+		t.Errorf("expected statuses %v to be %v", summary.Statuses, expectedStatuses)
+	}
+
+	expectedPaths := []Count{{"/", 2}, {"/missing", 1}}
+	if !reflect.DeepEqual(summary.TopPaths(), expectedPaths) {
+		t.Errorf("expected top paths %v to be %v", summary.TopPaths(), expectedPaths)
+	}
+
+	expectedIPs := []Count{{"1.1.1.1", 2}, {"2.2.2.2", 1}}
+	if !reflect.DeepEqual(summary.TopIPs(), expectedIPs) {
+		t.Errorf("expected top IPs %v to be %v", summary.TopIPs(), expectedIPs)
+	}
+
+	if len(summary.Slowest) != 2 || summary.Slowest[0].UpstreamTime != 2 || summary.Slowest[1].UpstreamTime != 0.3 {
+		t.Errorf("expected slowest upstream times to be [2 0.3], got %v", summary.Slowest)
+	}
+
+	// 2 requests within the last 10 seconds (25s and 30s)
+	if rate := summary.CurrentRate(); rate != 0.2 {
+		t.Errorf("expected current rate %v to be 0.2", rate)
+	}
+
+	if rate := summary.AverageRate(); rate != 4.0/30 {
+		t.Errorf("expected average rate %v to be %v", rate, 4.0/30)
+	}
+
+	var output bytes.Buffer
+	summary.Render(&output)
+
+	for _, expected := range []string{"Requests: 4 total", "2xx 2 (50.0%)", "5xx 1 (25.0%)", "/wp-login.php", "2.000s"} {
+		if !strings.Contains(output.String(), expected) {
+			t.Errorf("expected output %q to contain %q", output.String(), expected)
+		}
+	}
+}
+
+func TestSummaryRenderEmpty(t *testing.T) {
+	var output bytes.Buffer
+	NewSummary(10).Render(&output)
+
+	if output.String() != "No requests found.\n" {
+		t.Errorf("unexpected output %q", output.String())
+	}
+}
@@ -26,22 +26,24 @@ type HostsFileResolver struct {
 	Hosts        []string
 	hostsPath    string
 	tmpHostsPath string
+	runner       command.Runner
 }
 
-func NewHostsResolver(resolverType string, hosts []string) (resolver HostsResolver, err error) {
+func NewHostsResolver(resolverType string, hosts []string, runner command.Runner) (resolver HostsResolver, err error) {
 	switch resolverType {
 	case "hosts_file":
-		return NewHostsFileResolver(hosts), nil
+		return NewHostsFileResolver(hosts, runner), nil
 	default:
 		return nil, fmt.Errorf("Unknown hosts resolver type: %s", resolverType)
 	}
 }
 
-func NewHostsFileResolver(hosts []string) *HostsFileResolver {
+func NewHostsFileResolver(hosts []string, runner command.Runner) *HostsFileResolver {
 	return &HostsFileResolver{
 		Hosts:        hosts,
 		hostsPath:    "/etc/hosts",
 		tmpHostsPath: filepath.Join(app_paths.DataDir(), "hosts"),
+		runner:       runner,
 	}
 }
 
@@ -98,17 +100,20 @@ func (h *HostsFileResolver) removeHostsContent(name string) (content []byte, err
 }
 
 func (h *HostsFileResolver) writeHostsFile(content []byte) error {
-	if err := os.MkdirAll(filepath.Dir(h.tmpHostsPath), 0755); err != nil {
-		return err
+	if !command.IsDryRun(h.runner) {
+		if err := os.MkdirAll(filepath.Dir(h.tmpHostsPath), 0755); err != nil {
+			return err
+		}
 	}
 
-	if err := os.WriteFile(h.tmpHostsPath, content, 0644); err != nil {
+	if err := command.WriteFile(h.runner, h.tmpHostsPath, content, 0644); err != nil {
 		return err
 	}
 
 	fmt.Printf("\nUpdating %s file (sudo may be required, see `trellis vm sudoers` for more details)\n", h.hostsPath)
 
-	return command.WithOptions(
+	return command.New(
+		h.runner,
 		command.WithTermOutput(),
 	).Cmd("sudo", h.SudoersCommand()).Run()
 }
@@ -1,9 +1,13 @@
 package vm
 
 import (
+	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
+
+	"github.com/roots/trellis-cli/command"
 )
 
 func TestRemoveHostsContent(t *testing.T) {
@@ -391,3 +395,36 @@ func TestAddHostsContent(t *testing.T) {
 		})
 	}
 }
+
+func TestAddHostsDryRun(t *testing.T) {
+	tempDir := t.TempDir()
+	hostsPath := filepath.Join(tempDir, "hosts")
+	tmpHostsPath := filepath.Join(tempDir, "trellis", "hosts")
+
+	if err := os.WriteFile(hostsPath, []byte("127.0.0.1 localhost\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+
+	h := HostsFileResolver{
+		Hosts:        []string{"example.test"},
+		hostsPath:    hostsPath,
+		tmpHostsPath: tmpHostsPath,
+		runner:       command.NewDryRunner(&out),
+	}
+
+	if err := h.AddHosts("foo-bar", "192.168.2.1"); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := fmt.Sprintf("[dry-run] write %s\n[dry-run] sudo /bin/cp %s %s\n", tmpHostsPath, tmpHostsPath, hostsPath)
+
+	if out.String() != expected {
+		t.Errorf("expected %q, got %q", expected, out.String())
+	}
+
+	if _, err := os.Stat(filepath.Dir(tmpHostsPath)); !os.IsNotExist(err) {
+		t.Errorf("expected %s not to be created", filepath.Dir(tmpHostsPath))
+	}
+}
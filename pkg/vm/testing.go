@@ -2,6 +2,7 @@ package vm
 
 import (
 	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/command"
 	"github.com/roots/trellis-cli/trellis"
 )
 
@@ -42,3 +43,7 @@ func (m *MockVmManager) StopInstance(name string) error {
 func (m *MockVmManager) OpenShell(name string, dir string, commandArgs []string) error {
 	return nil
 }
+
+func (m *MockVmManager) ShellCmd(name string, dir string, commandArgs []string) (*command.Cmd, error) {
+	return command.New(m.trellis.Runner).Cmd("true", nil), nil
+}
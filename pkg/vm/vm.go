@@ -2,10 +2,13 @@ package vm
 
 import (
 	"errors"
+
+	"github.com/roots/trellis-cli/command"
 )
 
 var (
-	VmNotFoundErr = errors.New("vm does not exist")
+	VmNotFoundErr   = errors.New("vm does not exist")
+	VmNotRunningErr = errors.New("vm is not running")
 )
 
 type Manager interface {
@@ -15,4 +18,5 @@ type Manager interface {
 	StartInstance(name string) error
 	StopInstance(name string) error
 	OpenShell(name string, dir string, commandArgs []string) error
+	ShellCmd(name string, dir string, commandArgs []string) (*command.Cmd, error)
 }
@@ -24,11 +24,29 @@ type finder struct {
 	validPrefixes    []string
 	searchPaths      []string
 	coreRootCommands []string
+	// Core commands (ie: "db export") and the root commands with subcommands (ie: "db")
+	coreCommands   []string
+	coreNamespaces []string
 }
 
 func (o *finder) find() map[string]string {
 	plugins := make(map[string]string)
 
+	for _, plugin := range o.findAll() {
+		plugins[plugin.Name] = plugin.Path
+	}
+
+	return plugins
+}
+
+/*
+Returns all plugins in search path order. Like executables on PATH, the first
+plugin found with a given name is used and any others are shadowed by it.
+*/
+func (o *finder) findAll() []Plugin {
+	plugins := []Plugin{}
+	index := make(map[string]int)
+
 	for _, dir := range unique(o.searchPaths) {
 		if len(strings.TrimSpace(dir)) == 0 {
 			continue
@@ -47,11 +65,6 @@ func (o *finder) find() map[string]string {
 				continue
 			}
 
-			// Prevent overriding core commands or adding any subcommands under core commands.
-			if isUnderCoreRootCommands(f.Name(), o.coreRootCommands) {
-				continue
-			}
-
 			path := filepath.Join(dir, f.Name())
 			if !isExecutable(path) {
 				continue
@@ -64,14 +77,62 @@ func (o *finder) find() map[string]string {
 			}
 			name := strings.Join(nameParts, " ")
 
-			// TODO: Handle overshadowed plugins, i.e: plugins with the same binary name.
-			plugins[name] = path
+			metadata, err := loadMetadata(path)
+
+			// Prevent overriding core commands or adding any subcommands under core commands
+			// unless the plugin declares it extends a core namespace.
+			if isUnderCoreRootCommands(f.Name(), o.coreRootCommands) && !o.extendsCoreNamespace(name, metadata) {
+				continue
+			}
+
+			if i, found := index[name]; found {
+				plugins[i].Shadowed = append(plugins[i].Shadowed, path)
+				continue
+			}
+
+			index[name] = len(plugins)
+			plugins = append(plugins, Plugin{Name: name, Path: path, Metadata: metadata, MetadataErr: err})
 		}
 	}
 
 	return plugins
 }
 
+/*
+Whether a plugin can add a subcommand under a core namespace. The plugin must
+declare the namespace it extends and can't override (or add subcommands under)
+any existing core command.
+*/
+func (o *finder) extendsCoreNamespace(name string, metadata Metadata) bool {
+	parts := strings.Split(name, " ")
+
+	if len(parts) < 2 || metadata.Extends != parts[0] || !contains(o.coreNamespaces, parts[0]) {
+		return false
+	}
+
+	for _, command := range o.coreCommands {
+		if command == parts[0] {
+			continue
+		}
+
+		if name == command || strings.HasPrefix(name, command+" ") {
+			return false
+		}
+	}
+
+	return true
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
 func isExecutable(fullPath string) bool {
 	info, err := os.Stat(fullPath)
 	if err != nil {
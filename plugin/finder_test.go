@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 )
 
@@ -326,3 +327,126 @@ func TestIsUnderCoreRootCommands(t *testing.T) {
 		}
 	}
 }
+
+func TestFindAll(t *testing.T) {
+	firstDir := t.TempDir()
+	secondDir := t.TempDir()
+
+	for _, dir := range []string{firstDir, secondDir} {
+		if err := os.WriteFile(filepath.Join(dir, "trellis-foo"), []byte{}, 0755); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	manifest := `{"synopsis": "Does foo things", "version": "1.2.0", "completions": {"args": ["bar"], "flags": ["--force"]}}`
+	if err := os.WriteFile(filepath.Join(firstDir, "trellis-foo.json"), []byte(manifest), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if err := os.WriteFile(filepath.Join(secondDir, "trellis-bar"), []byte{}, 0755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if err := os.WriteFile(filepath.Join(secondDir, "trellis-bar.json"), []byte("{"), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	pluginFinder := finder{
+		validPrefixes: []string{"trellis"},
+		searchPaths:   []string{firstDir, secondDir},
+	}
+
+	plugins := pluginFinder.findAll()
+
+	if len(plugins) != 2 {
+		t.Fatalf("expected 2 plugins, got %v", plugins)
+	}
+
+	foo := plugins[0]
+	expectedMetadata := Metadata{
+		Synopsis:    "Does foo things",
+		Version:     "1.2.0",
+		Completions: Completions{Args: []string{"bar"}, Flags: []string{"--force"}},
+	}
+
+	if foo.Name != "foo" || foo.Path != filepath.Join(firstDir, "trellis-foo") {
+		t.Errorf("expected first plugin in search paths to be used, got %v", foo)
+	}
+
+	if !reflect.DeepEqual(foo.Metadata, expectedMetadata) {
+		t.Errorf("expected metadata %v to be %v", foo.Metadata, expectedMetadata)
+	}
+
+	if !reflect.DeepEqual(foo.Shadowed, []string{filepath.Join(secondDir, "trellis-foo")}) {
+		t.Errorf("expected shadowed plugins %v to be the second trellis-foo", foo.Shadowed)
+	}
+
+	bar := plugins[1]
+
+	if bar.Name != "bar" || bar.MetadataErr == nil {
+		t.Errorf("expected bar to have an invalid manifest error, got %v", bar)
+	}
+}
+
+func TestFindAllCoreNamespaceExtensions(t *testing.T) {
+	dir := t.TempDir()
+
+	plugins := map[string]string{
+		// declared extension of a core namespace
+		"trellis-db-backup": `{"extends": "db"}`,
+		// undeclared
+		"trellis-db-restore": "",
+		// can't override or add subcommands under core commands
+		"trellis-db-export":     `{"extends": "db"}`,
+		"trellis-db-export-now": `{"extends": "db"}`,
+		// deploy isn't a namespace
+		"trellis-deploy-foo": `{"extends": "deploy"}`,
+		// declared namespace must match
+		"trellis-vault-rotate": `{"extends": "db"}`,
+	}
+
+	for name, manifest := range plugins {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte{}, 0755); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+
+		if manifest != "" {
+			if err := os.WriteFile(filepath.Join(dir, name+".json"), []byte(manifest), 0644); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+		}
+	}
+
+	pluginFinder := finder{
+		validPrefixes:    []string{"trellis"},
+		searchPaths:      []string{dir},
+		coreRootCommands: []string{"db", "deploy", "vault"},
+		coreCommands:     []string{"db", "db export", "deploy", "vault", "vault edit"},
+		coreNamespaces:   []string{"db", "vault"},
+	}
+
+	found := []string{}
+	for _, plugin := range pluginFinder.findAll() {
+		found = append(found, plugin.Name)
+	}
+
+	expected := []string{"db backup"}
+
+	if !reflect.DeepEqual(found, expected) {
+		t.Errorf("expected plugins %v to be %v", found, expected)
+	}
+}
+
+func TestLoadMetadataInvalidEvent(t *testing.T) {
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "trellis-foo")
+
+	if err := os.WriteFile(bin+".json", []byte(`{"events": {"post-deploy": ["notify"], "pre-destroy": ["notify"]}}`), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	_, err := loadMetadata(bin)
+
+	if err == nil || !strings.Contains(err.Error(), `unknown event "pre-destroy"`) {
+		t.Errorf("expected unknown event error, got %v", err)
+	}
+}
@@ -3,18 +3,38 @@ package plugin
 import (
 	"bytes"
 	"fmt"
+	"sort"
+
 	"github.com/mitchellh/cli"
 )
 
-func helpFunc(pluginRootCommands []string, f cli.HelpFunc) cli.HelpFunc {
+/*
+Appends plugin root commands to the help output along with their synopsis
+(from the plugin's manifest) when available.
+*/
+func helpFunc(pluginRootCommands []string, synopses map[string]string, f cli.HelpFunc) cli.HelpFunc {
 	return func(commands map[string]cli.CommandFactory) string {
 		var buf bytes.Buffer
 		if len(pluginRootCommands) > 0 {
 			buf.WriteString("\n\nAvailable plugin commands:\n")
 		}
 
-		for _, p := range pluginRootCommands {
-			buf.WriteString(fmt.Sprintf("    %s\n", p))
+		rootCommands := append([]string{}, pluginRootCommands...)
+		sort.Strings(rootCommands)
+
+		maxKeyLen := 0
+		for _, p := range rootCommands {
+			if len(p) > maxKeyLen {
+				maxKeyLen = len(p)
+			}
+		}
+
+		for _, p := range rootCommands {
+			if synopsis := synopses[p]; synopsis != "" {
+				buf.WriteString(fmt.Sprintf("    %-*s    %s\n", maxKeyLen, p, synopsis))
+			} else {
+				buf.WriteString(fmt.Sprintf("    %s\n", p))
+			}
 		}
 
 		return f(commands) + buf.String()
@@ -15,7 +15,7 @@ func TestHelpFunc(t *testing.T) {
 	}
 	pluginRootCommands := []string{"foo", "bar"}
 
-	output := helpFunc(pluginRootCommands, cli.BasicHelpFunc("app"))(coreCommands)
+	output := helpFunc(pluginRootCommands, nil, cli.BasicHelpFunc("app"))(coreCommands)
 
 	expected := "Available plugin commands"
 	if !strings.Contains(output, expected) {
@@ -37,10 +37,28 @@ func TestHelpFuncNoPlugin(t *testing.T) {
 	}
 	pluginRootCommands := []string{}
 
-	output := helpFunc(pluginRootCommands, cli.BasicHelpFunc("app"))(coreCommands)
+	output := helpFunc(pluginRootCommands, nil, cli.BasicHelpFunc("app"))(coreCommands)
 
 	expected := cli.BasicHelpFunc("app")(coreCommands)
 	if expected != output {
 		t.Errorf("expected output %q to be excatly the same as %q", output, expected)
 	}
 }
+
+func TestHelpFuncSynopsis(t *testing.T) {
+	coreCommands := map[string]cli.CommandFactory{
+		"dummy": func() (cli.Command, error) {
+			return &cli.MockCommand{}, nil
+		},
+	}
+	pluginRootCommands := []string{"foo", "barbaz"}
+	synopses := map[string]string{"foo": "Does foo things"}
+
+	output := helpFunc(pluginRootCommands, synopses, cli.BasicHelpFunc("app"))(coreCommands)
+
+	for _, expected := range []string{"    barbaz\n", "    foo       Does foo things\n"} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output %q to contain %q", output, expected)
+		}
+	}
+}
@@ -0,0 +1,39 @@
+package plugin
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/pkg/events"
+)
+
+/*
+Returns an event handler which runs a plugin with the args declared in its
+manifest. The event's JSON payload is written to the plugin's stdin and its
+name is set as TRELLIS_EVENT.
+*/
+func hookHandler(runner command.Runner, ui cli.Ui, bin string, args []string) events.Handler {
+	return func(event events.Event, payload []byte) error {
+		hook := command.New(
+			runner,
+			command.WithUiOutput(ui),
+		).Cmd(bin, args)
+
+		hook.Stdin = bytes.NewReader(payload)
+
+		if hook.Env == nil {
+			hook.Env = os.Environ()
+		}
+		hook.Env = append(hook.Env, "TRELLIS_EVENT="+event.Name)
+
+		if err := hook.Run(); err != nil {
+			return fmt.Errorf("Error: %s hook %s failed: %v", event.Name, filepath.Base(bin), err)
+		}
+
+		return nil
+	}
+}
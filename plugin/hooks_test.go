@@ -0,0 +1,49 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/pkg/events"
+)
+
+func TestHookHandler(t *testing.T) {
+	if runtime.GOOS == "windows" {
+		t.Skip("requires a POSIX shell")
+	}
+
+	dir := t.TempDir()
+	bin := filepath.Join(dir, "trellis-foo")
+	output := filepath.Join(dir, "output")
+
+	script := "#!/bin/sh\necho \"$TRELLIS_EVENT $*\" > " + output + "\ncat >> " + output + "\n[ \"$1\" != fail ]\n"
+	if err := os.WriteFile(bin, []byte(script), 0755); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	dispatcher := events.NewDispatcher()
+	dispatcher.Subscribe(events.PostDeploy, hookHandler(command.NewExecRunner(), cli.NewMockUi(), bin, []string{"notify", "--quiet"}))
+	dispatcher.Subscribe(events.PreDeploy, hookHandler(command.NewExecRunner(), cli.NewMockUi(), bin, []string{"fail"}))
+
+	if err := dispatcher.Emit(events.Event{Name: events.PostDeploy, ProjectPath: "/trellis", Environment: "production", Status: events.StatusSuccess}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	data, _ := os.ReadFile(output)
+	expected := "post-deploy notify --quiet\n" + `{"event":"post-deploy","project_path":"/trellis","environment":"production","status":"success"}`
+
+	if string(data) != expected {
+		t.Errorf("expected hook output %q to be %q", string(data), expected)
+	}
+
+	err := dispatcher.Emit(events.Event{Name: events.PreDeploy, ProjectPath: "/trellis", Environment: "production"})
+
+	if err == nil || !strings.Contains(err.Error(), "pre-deploy hook trellis-foo failed") {
+		t.Errorf("expected hook failure error, got %v", err)
+	}
+}
@@ -0,0 +1,112 @@
+package plugin
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+func NewInstallCommand(ui cli.Ui, installer *installer) *InstallCommand {
+	c := &InstallCommand{UI: ui, installer: installer}
+	c.init()
+	return c
+}
+
+type InstallCommand struct {
+	UI        cli.Ui
+	flags     *flag.FlagSet
+	installer *installer
+}
+
+func (c *InstallCommand) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Usage = func() { c.UI.Info(c.Help()) }
+}
+
+func (c *InstallCommand) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = c.flags.Args()
+
+	if len(args) != 1 {
+		c.UI.Error(fmt.Sprintf("Error: %s (expected exactly 1, got %d)", argumentsError(args), len(args)))
+		c.UI.Output(c.Help())
+		return 1
+	}
+
+	repo, version, err := parseRepoArg(args[0])
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	installed, err := c.installer.install(repo, version)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	// Files are unknown during a dry run since nothing is downloaded
+	if len(installed.Files) == 0 {
+		c.UI.Info(fmt.Sprintf("Installed %s %s", installed.Repo, installed.Version))
+		return 0
+	}
+
+	c.UI.Info(fmt.Sprintf("Installed %s %s (%s)", installed.Repo, installed.Version, strings.Join(installed.Files, ", ")))
+
+	return 0
+}
+
+func (c *InstallCommand) Synopsis() string {
+	return "Installs a plugin from a GitHub release"
+}
+
+func (c *InstallCommand) Help() string {
+	helpText := `
+Usage: trellis plugin install [options] OWNER/REPO[@version]
+
+Installs a plugin from a GitHub release.
+
+The release asset matching your OS and architecture (eg: trellis-foo_1.0.0_Darwin_arm64.tar.gz)
+is downloaded and verified against the release's checksums file (eg: checksums.txt as
+generated by GoReleaser). The trellis-* executables (and manifests) it contains are installed
+into trellis-cli's plugins directory which takes precedence over plugins on your PATH.
+
+Install the latest release:
+
+  $ trellis plugin install roots/trellis-foo
+
+Install a specific release:
+
+  $ trellis plugin install roots/trellis-foo@v1.2.0
+
+Arguments:
+  OWNER/REPO  GitHub repository of the plugin, optionally with a release tag
+
+Options:
+  -h, --help  show this help
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *InstallCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *InstallCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{}
+}
+
+func argumentsError(args []string) string {
+	if len(args) == 0 {
+		return "missing arguments"
+	}
+
+	return "too many arguments"
+}
@@ -0,0 +1,543 @@
+package plugin
+
+import (
+	"bufio"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+	"sort"
+	"strings"
+
+	"github.com/mholt/archiver"
+	"github.com/posener/complete"
+	"github.com/roots/trellis-cli/app_paths"
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/github"
+)
+
+const installedFileName = "installed.json"
+
+var repoPattern = regexp.MustCompile(`^[\w.-]+/[\w.-]+$`)
+
+var osAliases = map[string][]string{
+	"darwin":  {"darwin", "macos", "apple"},
+	"linux":   {"linux"},
+	"windows": {"windows", "win"},
+}
+
+var archAliases = map[string][]string{
+	"amd64": {"amd64", "x86_64", "x64"},
+	"arm64": {"arm64", "aarch64"},
+	"386":   {"386", "i386", "x86"},
+}
+
+var archiveExtensions = []string{".tar.gz", ".tgz", ".tar.xz", ".txz", ".tar.bz2", ".tbz2", ".tar", ".zip"}
+
+// Managed plugins dir. Plugins installed by 'trellis plugin install' take precedence over plugins on PATH.
+func InstallDir() string {
+	return filepath.Join(app_paths.DataDir(), "plugins")
+}
+
+// A plugin installed from a GitHub release.
+type InstalledPlugin struct {
+	Repo    string   `json:"repo"`
+	Version string   `json:"version"`
+	Files   []string `json:"files"`
+}
+
+type installer struct {
+	dir    string
+	client *http.Client
+	goos   string
+	goarch string
+	// Only prints downloads and file changes during a dry run
+	runner command.Runner
+}
+
+func newInstaller(runner command.Runner) *installer {
+	return &installer{
+		dir:    InstallDir(),
+		client: github.Client,
+		goos:   runtime.GOOS,
+		goarch: runtime.GOARCH,
+		runner: runner,
+	}
+}
+
+/*
+Parses an OWNER/REPO[@version] argument. The version defaults to "latest".
+*/
+func parseRepoArg(arg string) (repo string, version string, err error) {
+	repo, version, found := strings.Cut(arg, "@")
+
+	if !repoPattern.MatchString(repo) || (found && version == "") {
+		return "", "", fmt.Errorf("Error: invalid plugin %q. Must be in the format OWNER/REPO[@version]", arg)
+	}
+
+	if version == "" {
+		version = "latest"
+	}
+
+	return repo, version, nil
+}
+
+func (i *installer) installed() (map[string]InstalledPlugin, error) {
+	plugins := make(map[string]InstalledPlugin)
+
+	data, err := os.ReadFile(filepath.Join(i.dir, installedFileName))
+	if errors.Is(err, os.ErrNotExist) {
+		return plugins, nil
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := json.Unmarshal(data, &plugins); err != nil {
+		return nil, fmt.Errorf("Error parsing %s: %v", filepath.Join(i.dir, installedFileName), err)
+	}
+
+	return plugins, nil
+}
+
+func (i *installer) save(plugins map[string]InstalledPlugin) error {
+	data, err := json.MarshalIndent(plugins, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	return command.WriteFile(i.runner, filepath.Join(i.dir, installedFileName), append(data, '\n'), 0644)
+}
+
+/*
+Downloads the release asset matching the current OS and arch, verifies it
+against the release's checksums file, and installs the trellis-* executables
+(and their manifests) it contains into the managed plugins dir.
+*/
+func (i *installer) install(repo string, version string) (*InstalledPlugin, error) {
+	release, err := i.fetchRelease(repo, version)
+	if err != nil {
+		return nil, err
+	}
+
+	asset, err := findReleaseAsset(release.Assets, i.goos, i.goarch)
+	if err != nil {
+		return nil, fmt.Errorf("Error: %s %s: %v", repo, release.Version, err)
+	}
+
+	checksumsAsset, ok := findChecksumsAsset(release.Assets, asset)
+	if !ok {
+		return nil, fmt.Errorf("Error: %s %s has no checksums file. Refusing to install an unverified plugin.", repo, release.Version)
+	}
+
+	// Nothing is downloaded during a dry run so the plugin's files are unknown
+	if dryRunner, ok := i.runner.(*command.DryRunner); ok {
+		fmt.Fprintf(dryRunner.Out, "[dry-run] download %s\n", asset.URL)
+		fmt.Fprintf(dryRunner.Out, "[dry-run] install %s into %s\n", asset.Name, i.dir)
+		return &InstalledPlugin{Repo: repo, Version: release.Version}, nil
+	}
+
+	tmpDir, err := os.MkdirTemp("", "trellis-plugin")
+	if err != nil {
+		return nil, err
+	}
+	defer os.RemoveAll(tmpDir)
+
+	assetPath := filepath.Join(tmpDir, asset.Name)
+	if err := github.DownloadFile(assetPath, asset.URL, i.client); err != nil {
+		return nil, err
+	}
+
+	checksumsPath := filepath.Join(tmpDir, checksumsAsset.Name)
+	if err := github.DownloadFile(checksumsPath, checksumsAsset.URL, i.client); err != nil {
+		return nil, err
+	}
+
+	if err := verifyChecksum(assetPath, checksumsPath); err != nil {
+		return nil, err
+	}
+
+	files, err := pluginFiles(repo, assetPath, filepath.Join(tmpDir, "extracted"))
+	if err != nil {
+		return nil, err
+	}
+
+	plugins, err := i.installed()
+	if err != nil {
+		return nil, err
+	}
+
+	key := strings.ToLower(repo)
+
+	for name := range files {
+		for otherKey, other := range plugins {
+			if otherKey == key {
+				continue
+			}
+
+			for _, file := range other.Files {
+				if file == name {
+					return nil, fmt.Errorf("Error: %s is already installed by %s", name, other.Repo)
+				}
+			}
+		}
+	}
+
+	if err := os.MkdirAll(i.dir, 0755); err != nil {
+		return nil, fmt.Errorf("Error creating plugins directory: %v", err)
+	}
+
+	if previous, ok := plugins[key]; ok {
+		i.removeFiles(previous.Files)
+	}
+
+	installed := InstalledPlugin{Repo: repo, Version: release.Version}
+
+	for name, src := range files {
+		if err := copyFile(src, filepath.Join(i.dir, name)); err != nil {
+			return nil, fmt.Errorf("Error installing %s: %v", name, err)
+		}
+
+		installed.Files = append(installed.Files, name)
+	}
+
+	sort.Strings(installed.Files)
+	plugins[key] = installed
+
+	if err := i.save(plugins); err != nil {
+		return nil, fmt.Errorf("Error saving installed plugins: %v", err)
+	}
+
+	return &installed, nil
+}
+
+func (i *installer) remove(repo string) (*InstalledPlugin, error) {
+	plugins, err := i.installed()
+	if err != nil {
+		return nil, err
+	}
+
+	key := strings.ToLower(repo)
+	installed, ok := plugins[key]
+	if !ok {
+		return nil, fmt.Errorf("Error: plugin %s is not installed", repo)
+	}
+
+	i.removeFiles(installed.Files)
+	delete(plugins, key)
+
+	if err := i.save(plugins); err != nil {
+		return nil, fmt.Errorf("Error saving installed plugins: %v", err)
+	}
+
+	return &installed, nil
+}
+
+func (i *installer) removeFiles(files []string) {
+	for _, file := range files {
+		if dryRunner, ok := i.runner.(*command.DryRunner); ok {
+			fmt.Fprintf(dryRunner.Out, "[dry-run] remove %s\n", filepath.Join(i.dir, file))
+			continue
+		}
+
+		os.Remove(filepath.Join(i.dir, file))
+	}
+}
+
+func (i *installer) fetchRelease(repo string, version string) (*github.Release, error) {
+	var release *github.Release
+	var err error
+
+	if version == "latest" {
+		release, err = github.FetchLatestRelease(repo, i.client)
+	} else {
+		release, err = github.FetchRelease(repo, version, i.client)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("Error fetching release information for %s from the GitHub API: %v", repo, err)
+	}
+
+	return release, nil
+}
+
+/*
+Finds the release asset for an OS and arch based on common naming conventions
+(eg: trellis-foo_1.0.0_Darwin_x86_64.tar.gz or trellis-foo-linux-arm64).
+macOS universal binaries are used when there's no arch specific asset.
+*/
+func findReleaseAsset(assets []github.Asset, goos string, goarch string) (github.Asset, error) {
+	var universal *github.Asset
+	names := []string{}
+
+	for i, asset := range assets {
+		if isChecksumsAsset(asset.Name) || isSupplementaryAsset(asset.Name) {
+			continue
+		}
+
+		names = append(names, asset.Name)
+		tokens := assetNameTokens(asset.Name)
+
+		if !hasAnyToken(tokens, osAliases[goos]) {
+			continue
+		}
+
+		if hasAnyToken(tokens, archAliases[goarch]) {
+			return asset, nil
+		}
+
+		if goos == "darwin" && hasAnyToken(tokens, []string{"all", "universal"}) {
+			universal = &assets[i]
+		}
+	}
+
+	if universal != nil {
+		return *universal, nil
+	}
+
+	return github.Asset{}, fmt.Errorf("no release asset found for %s/%s (found: %s)", goos, goarch, strings.Join(names, ", "))
+}
+
+func assetNameTokens(name string) map[string]bool {
+	name = strings.ToLower(name)
+	name = strings.ReplaceAll(name, "x86_64", "amd64")
+
+	tokens := make(map[string]bool)
+	for _, token := range strings.FieldsFunc(name, func(r rune) bool {
+		return r == '_' || r == '-' || r == '.'
+	}) {
+		tokens[token] = true
+	}
+
+	return tokens
+}
+
+func hasAnyToken(tokens map[string]bool, values []string) bool {
+	for _, value := range values {
+		if tokens[value] {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isChecksumsAsset(name string) bool {
+	name = strings.ToLower(name)
+
+	return strings.HasSuffix(name, "checksums.txt") || strings.HasSuffix(name, ".sha256")
+}
+
+func isSupplementaryAsset(name string) bool {
+	for _, ext := range []string{".sig", ".pem", ".sbom", ".json", ".txt", ".asc"} {
+		if strings.HasSuffix(strings.ToLower(name), ext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Prefers a per-asset checksum file (eg: foo.tar.gz.sha256) over a release-wide one (eg: checksums.txt).
+func findChecksumsAsset(assets []github.Asset, asset github.Asset) (github.Asset, bool) {
+	for _, a := range assets {
+		if a.Name == asset.Name+".sha256" {
+			return a, true
+		}
+	}
+
+	for _, a := range assets {
+		if isChecksumsAsset(a.Name) && !strings.HasSuffix(strings.ToLower(a.Name), ".sha256") {
+			return a, true
+		}
+	}
+
+	return github.Asset{}, false
+}
+
+/*
+Verifies a file against a sha256sum formatted checksums file. A file only
+containing a checksum (without a filename) is also supported.
+*/
+func verifyChecksum(path string, checksumsPath string) error {
+	name := filepath.Base(path)
+
+	checksums, err := os.Open(checksumsPath)
+	if err != nil {
+		return err
+	}
+	defer checksums.Close()
+
+	expected := ""
+	scanner := bufio.NewScanner(checksums)
+
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+
+		if len(fields) == 1 && expected == "" {
+			expected = fields[0]
+		} else if len(fields) == 2 && strings.TrimPrefix(fields[1], "*") == name {
+			expected = fields[0]
+			break
+		}
+	}
+
+	if expected == "" {
+		return fmt.Errorf("Error: no checksum found for %s", name)
+	}
+
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	hash := sha256.New()
+	if _, err := io.Copy(hash, file); err != nil {
+		return err
+	}
+
+	if actual := hex.EncodeToString(hash.Sum(nil)); !strings.EqualFold(actual, expected) {
+		return fmt.Errorf("Error: checksum mismatch for %s (expected %s, got %s)", name, expected, actual)
+	}
+
+	return nil
+}
+
+/*
+Returns the plugin files to install (name => source path). Archives are
+extracted and searched for trellis-* executables and their manifests. Other
+assets are treated as the executable itself, named after the repo.
+*/
+func pluginFiles(repo string, assetPath string, extractDir string) (map[string]string, error) {
+	files := make(map[string]string)
+
+	if !isArchive(assetPath) {
+		name := strings.Split(repo, "/")[1]
+		if !hasValidPrefix(name, []string{"trellis"}) {
+			return nil, fmt.Errorf("Error: release asset %s is not an archive and %s is not a valid plugin name", filepath.Base(assetPath), name)
+		}
+
+		if runtime.GOOS == "windows" {
+			name += ".exe"
+		}
+
+		if err := os.Chmod(assetPath, 0755); err != nil {
+			return nil, err
+		}
+
+		files[name] = assetPath
+		return files, nil
+	}
+
+	if err := archiver.Unarchive(assetPath, extractDir); err != nil {
+		return nil, fmt.Errorf("Error extracting the release archive: %v", err)
+	}
+
+	manifests := make(map[string]string)
+
+	err := filepath.WalkDir(extractDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() || !hasValidPrefix(d.Name(), []string{"trellis"}) {
+			return err
+		}
+
+		if filepath.Ext(d.Name()) == ".json" {
+			manifests[d.Name()] = path
+		} else if isExecutable(path) {
+			files[d.Name()] = path
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	if len(files) == 0 {
+		return nil, fmt.Errorf("Error: no trellis-* executables found in %s", filepath.Base(assetPath))
+	}
+
+	executables := make([]string, 0, len(files))
+	for name := range files {
+		executables = append(executables, name)
+	}
+
+	for _, name := range executables {
+		manifest := filepath.Base(manifestPath(name))
+
+		if manifestSrc, ok := manifests[manifest]; ok {
+			files[manifest] = manifestSrc
+		}
+	}
+
+	return files, nil
+}
+
+func isArchive(path string) bool {
+	for _, ext := range archiveExtensions {
+		if strings.HasSuffix(strings.ToLower(path), ext) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func copyFile(src string, dest string) error {
+	info, err := os.Stat(src)
+	if err != nil {
+		return err
+	}
+
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	// Write to a temp file first so a running plugin isn't clobbered mid-update
+	tmp := dest + ".tmp"
+	out, err := os.OpenFile(tmp, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		os.Remove(tmp)
+		return err
+	}
+
+	if err := out.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+
+	return os.Rename(tmp, dest)
+}
+
+func (i *installer) predictInstalled() complete.Predictor {
+	return complete.PredictFunc(func(args complete.Args) []string {
+		installed, err := i.installed()
+		if err != nil {
+			return nil
+		}
+
+		repos := []string{}
+		for _, plugin := range installed {
+			repos = append(repos, plugin.Repo)
+		}
+
+		return repos
+	})
+}
@@ -0,0 +1,292 @@
+package plugin
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/mholt/archiver"
+	"github.com/mitchellh/cli"
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/github"
+)
+
+func TestParseRepoArg(t *testing.T) {
+	cases := []struct {
+		arg     string
+		repo    string
+		version string
+		err     bool
+	}{
+		{"roots/trellis-foo", "roots/trellis-foo", "latest", false},
+		{"roots/trellis-foo@v1.2.0", "roots/trellis-foo", "v1.2.0", false},
+		{"roots/trellis-foo@", "", "", true},
+		{"trellis-foo", "", "", true},
+		{"roots/trellis-foo/bar", "", "", true},
+	}
+
+	for _, tc := range cases {
+		repo, version, err := parseRepoArg(tc.arg)
+
+		if (err != nil) != tc.err {
+			t.Errorf("%s: expected error %v, got %v", tc.arg, tc.err, err)
+		}
+
+		if repo != tc.repo || version != tc.version {
+			t.Errorf("%s: expected %s@%s, got %s@%s", tc.arg, tc.repo, tc.version, repo, version)
+		}
+	}
+}
+
+func TestFindReleaseAsset(t *testing.T) {
+	assets := []github.Asset{
+		{Name: "checksums.txt"},
+		{Name: "trellis-foo_1.0.0_Darwin_all.tar.gz"},
+		{Name: "trellis-foo_1.0.0_Linux_x86_64.tar.gz"},
+		{Name: "trellis-foo_1.0.0_Linux_arm64.tar.gz"},
+		{Name: "trellis-foo_1.0.0_Windows_x86_64.zip"},
+	}
+
+	cases := []struct {
+		goos     string
+		goarch   string
+		expected string
+	}{
+		{"linux", "amd64", "trellis-foo_1.0.0_Linux_x86_64.tar.gz"},
+		{"linux", "arm64", "trellis-foo_1.0.0_Linux_arm64.tar.gz"},
+		{"darwin", "arm64", "trellis-foo_1.0.0_Darwin_all.tar.gz"},
+		{"windows", "amd64", "trellis-foo_1.0.0_Windows_x86_64.zip"},
+		{"linux", "386", ""},
+	}
+
+	for _, tc := range cases {
+		asset, err := findReleaseAsset(assets, tc.goos, tc.goarch)
+
+		if tc.expected == "" {
+			if err == nil {
+				t.Errorf("%s/%s: expected error, got %s", tc.goos, tc.goarch, asset.Name)
+			}
+			continue
+		}
+
+		if err != nil {
+			t.Errorf("%s/%s: unexpected error: %v", tc.goos, tc.goarch, err)
+		}
+
+		if asset.Name != tc.expected {
+			t.Errorf("%s/%s: expected %s, got %s", tc.goos, tc.goarch, tc.expected, asset.Name)
+		}
+	}
+}
+
+func TestVerifyChecksum(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "trellis-foo.tar.gz")
+	checksumsPath := filepath.Join(dir, "checksums.txt")
+
+	os.WriteFile(path, []byte("contents"), 0644)
+	sum := sha256.Sum256([]byte("contents"))
+
+	os.WriteFile(checksumsPath, []byte(fmt.Sprintf("%s  other.tar.gz\n%s  trellis-foo.tar.gz\n", strings.Repeat("0", 64), hex.EncodeToString(sum[:]))), 0644)
+
+	if err := verifyChecksum(path, checksumsPath); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	os.WriteFile(path, []byte("tampered"), 0644)
+
+	if err := verifyChecksum(path, checksumsPath); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("expected checksum mismatch error, got %v", err)
+	}
+}
+
+// Serves releases of roots/trellis-foo with a linux/amd64 archive containing
+// the trellis-foo executable (and its manifest).
+func newReleaseServer(t *testing.T, version string, checksum string) *httptest.Server {
+	t.Helper()
+
+	archiveDir := t.TempDir()
+	binDir := filepath.Join(archiveDir, "trellis-foo_linux_amd64")
+	os.Mkdir(binDir, 0755)
+	os.WriteFile(filepath.Join(binDir, "trellis-foo"), []byte("#!/bin/sh\necho "+version), 0755)
+	os.WriteFile(filepath.Join(binDir, "trellis-foo.json"), []byte(`{"version": "`+version+`"}`), 0644)
+	os.WriteFile(filepath.Join(binDir, "README.md"), []byte("readme"), 0644)
+
+	archivePath := filepath.Join(archiveDir, "trellis-foo_linux_amd64.tar.gz")
+	if err := archiver.Archive([]string{binDir}, archivePath); err != nil {
+		t.Fatalf("could not create archive: %v", err)
+	}
+
+	archive, _ := os.ReadFile(archivePath)
+
+	if checksum == "" {
+		sum := sha256.Sum256(archive)
+		checksum = hex.EncodeToString(sum[:])
+	}
+
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(rw http.ResponseWriter, req *http.Request) {
+		switch req.URL.Path {
+		case "/repos/roots/trellis-foo/releases/latest", "/repos/roots/trellis-foo/releases/tags/" + version:
+			fmt.Fprintf(rw, `{"tag_name": %q, "assets": [
+				{"name": "checksums.txt", "browser_download_url": "%[2]s/download/checksums.txt"},
+				{"name": "trellis-foo_linux_amd64.tar.gz", "browser_download_url": "%[2]s/download/trellis-foo_linux_amd64.tar.gz"}
+			]}`, version, server.URL)
+		case "/download/checksums.txt":
+			fmt.Fprintf(rw, "%s  trellis-foo_linux_amd64.tar.gz\n", checksum)
+		case "/download/trellis-foo_linux_amd64.tar.gz":
+			rw.Write(archive)
+		default:
+			http.Error(rw, "not found", 404)
+		}
+	}))
+
+	github.BaseURL = server.URL
+
+	return server
+}
+
+func TestInstallerInstallAndRemove(t *testing.T) {
+	defer func(baseURL string) { github.BaseURL = baseURL }(github.BaseURL)
+
+	dir := t.TempDir()
+	server := newReleaseServer(t, "v1.0.0", "")
+	defer server.Close()
+
+	installer := &installer{dir: dir, client: server.Client(), goos: "linux", goarch: "amd64"}
+
+	installed, err := installer.install("roots/trellis-foo", "latest")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := &InstalledPlugin{Repo: "roots/trellis-foo", Version: "v1.0.0", Files: []string{"trellis-foo", "trellis-foo.json"}}
+	if !reflect.DeepEqual(installed, expected) {
+		t.Errorf("expected %v, got %v", expected, installed)
+	}
+
+	if !isExecutable(filepath.Join(dir, "trellis-foo")) {
+		t.Error("expected trellis-foo to be installed as an executable")
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "README.md")); err == nil {
+		t.Error("expected non-plugin files not to be installed")
+	}
+
+	plugins := (&finder{validPrefixes: []string{"trellis"}, searchPaths: []string{dir}}).findAll()
+	if len(plugins) != 1 || plugins[0].Metadata.Version != "v1.0.0" {
+		t.Errorf("expected installed plugin to be found with its manifest, got %v", plugins)
+	}
+
+	removed, err := installer.remove("roots/trellis-foo")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if removed.Version != "v1.0.0" {
+		t.Errorf("expected removed version v1.0.0, got %s", removed.Version)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "trellis-foo")); !os.IsNotExist(err) {
+		t.Error("expected trellis-foo to be removed")
+	}
+
+	if all, _ := installer.installed(); len(all) != 0 {
+		t.Errorf("expected no installed plugins, got %v", all)
+	}
+
+	if _, err := installer.remove("roots/trellis-foo"); err == nil {
+		t.Error("expected error removing a plugin which isn't installed")
+	}
+}
+
+func TestInstallerInstallChecksumMismatch(t *testing.T) {
+	defer func(baseURL string) { github.BaseURL = baseURL }(github.BaseURL)
+
+	dir := t.TempDir()
+	server := newReleaseServer(t, "v1.0.0", strings.Repeat("0", 64))
+	defer server.Close()
+
+	installer := &installer{dir: dir, client: server.Client(), goos: "linux", goarch: "amd64"}
+
+	_, err := installer.install("roots/trellis-foo", "v1.0.0")
+	if err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+		t.Errorf("expected checksum mismatch error, got %v", err)
+	}
+
+	if _, err := os.Stat(filepath.Join(dir, "trellis-foo")); !os.IsNotExist(err) {
+		t.Error("expected trellis-foo not to be installed")
+	}
+}
+
+func TestInstallerInstallDryRun(t *testing.T) {
+	defer func(baseURL string) { github.BaseURL = baseURL }(github.BaseURL)
+
+	dir := t.TempDir()
+	server := newReleaseServer(t, "v1.0.0", "")
+	defer server.Close()
+
+	var out bytes.Buffer
+	installer := &installer{dir: dir, client: server.Client(), goos: "linux", goarch: "amd64", runner: command.NewDryRunner(&out)}
+
+	installed, err := installer.install("roots/trellis-foo", "latest")
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if installed.Version != "v1.0.0" {
+		t.Errorf("expected version v1.0.0, got %s", installed.Version)
+	}
+
+	expected := fmt.Sprintf("[dry-run] download %s/download/trellis-foo_linux_amd64.tar.gz\n[dry-run] install trellis-foo_linux_amd64.tar.gz into %s\n", server.URL, dir)
+	if out.String() != expected {
+		t.Errorf("expected output %q, got %q", expected, out.String())
+	}
+
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Errorf("expected nothing to be installed, got %v", entries)
+	}
+}
+
+func TestUpdateCommand(t *testing.T) {
+	defer func(baseURL string) { github.BaseURL = baseURL }(github.BaseURL)
+
+	dir := t.TempDir()
+	server := newReleaseServer(t, "v1.1.0", "")
+	defer server.Close()
+
+	installer := &installer{dir: dir, client: server.Client(), goos: "linux", goarch: "amd64"}
+	installer.save(map[string]InstalledPlugin{
+		"roots/trellis-foo": {Repo: "roots/trellis-foo", Version: "v1.0.0", Files: []string{"trellis-foo"}},
+	})
+
+	ui := cli.NewMockUi()
+	updateCommand := NewUpdateCommand(ui, installer)
+
+	if code := updateCommand.Run(nil); code != 0 {
+		t.Fatalf("expected code 0, got %d: %s", code, ui.ErrorWriter.String())
+	}
+
+	if !strings.Contains(ui.OutputWriter.String(), "Updated roots/trellis-foo v1.0.0 → v1.1.0") {
+		t.Errorf("expected output %q to contain update", ui.OutputWriter.String())
+	}
+
+	ui = cli.NewMockUi()
+	updateCommand = NewUpdateCommand(ui, installer)
+
+	if code := updateCommand.Run([]string{"roots/trellis-foo"}); code != 0 {
+		t.Fatalf("expected code 0, got %d: %s", code, ui.ErrorWriter.String())
+	}
+
+	if !strings.Contains(ui.OutputWriter.String(), "roots/trellis-foo is already up to date (v1.1.0)") {
+		t.Errorf("expected output %q to contain up to date message", ui.OutputWriter.String())
+	}
+}
@@ -0,0 +1,168 @@
+package plugin
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+	"github.com/roots/trellis-cli/cmd"
+	"github.com/roots/trellis-cli/command"
+)
+
+/*
+Registers the core 'plugin' commands. Must be called before Register so plugins
+aren't mistaken for core commands.
+*/
+func RegisterCommands(c *cli.CLI, ui cli.Ui, runner command.Runner, searchPaths []string, validPluginFilenamePrefixes []string) {
+	var pluginFinder *finder
+
+	c.Commands["plugin"] = func() (cli.Command, error) {
+		return &cmd.NamespaceCommand{
+			HelpText:     "Usage: trellis plugin <subcommand> [<args>]",
+			SynopsisText: "Commands for managing plugins",
+		}, nil
+	}
+	c.Commands["plugin install"] = func() (cli.Command, error) {
+		return NewInstallCommand(ui, newInstaller(runner)), nil
+	}
+	c.Commands["plugin list"] = func() (cli.Command, error) {
+		return NewListCommand(ui, pluginFinder), nil
+	}
+	c.Commands["plugin remove"] = func() (cli.Command, error) {
+		return NewRemoveCommand(ui, newInstaller(runner)), nil
+	}
+	c.Commands["plugin update"] = func() (cli.Command, error) {
+		return NewUpdateCommand(ui, newInstaller(runner)), nil
+	}
+
+	pluginFinder = newFinder(c.Commands, searchPaths, validPluginFilenamePrefixes)
+}
+
+func NewListCommand(ui cli.Ui, pluginFinder *finder) *ListCommand {
+	c := &ListCommand{UI: ui, finder: pluginFinder}
+	c.init()
+	return c
+}
+
+type ListCommand struct {
+	UI     cli.Ui
+	flags  *flag.FlagSet
+	finder *finder
+}
+
+func (c *ListCommand) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Usage = func() { c.UI.Info(c.Help()) }
+}
+
+func (c *ListCommand) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	if len(c.flags.Args()) > 0 {
+		c.UI.Error("Error: too many arguments")
+		c.UI.Output(c.Help())
+		return 1
+	}
+
+	plugins := c.finder.findAll()
+
+	if len(plugins) == 0 {
+		c.UI.Info("No plugins found.")
+		return 0
+	}
+
+	var output strings.Builder
+	w := tabwriter.NewWriter(&output, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "NAME\tVERSION\tPATH")
+
+	for _, plugin := range plugins {
+		version := plugin.Metadata.Version
+		if version == "" {
+			version = "-"
+		}
+
+		fmt.Fprintf(w, "%s\t%s\t%s\n", plugin.Name, version, plugin.Path)
+
+		for _, shadowed := range plugin.Shadowed {
+			fmt.Fprintf(w, "\t\t  shadows %s\n", shadowed)
+		}
+
+		if plugin.MetadataErr != nil {
+			fmt.Fprintf(w, "\t\t  warning: %v\n", plugin.MetadataErr)
+		}
+	}
+
+	w.Flush()
+	c.UI.Output(strings.TrimSuffix(output.String(), "\n"))
+
+	return 0
+}
+
+func (c *ListCommand) Synopsis() string {
+	return "Lists installed plugins"
+}
+
+func (c *ListCommand) Help() string {
+	helpText := `
+Usage: trellis plugin list [options]
+
+Lists installed plugins.
+
+Plugins are executables named 'trellis-<command>' (eg: trellis-foo, or trellis-foo-bar
+for a 'foo bar' subcommand) found in trellis-cli's plugins directory (see 'trellis plugin install')
+or your PATH. When multiple plugins have the same name, the first one found is used and it
+shadows the others (just like PATH).
+
+Plugins can provide a JSON manifest next to their executable (eg: trellis-foo.json)
+with metadata shown in trellis' help output and used for completions:
+
+  {
+    "synopsis": "Does foo things",
+    "help": "Usage: trellis foo [options]",
+    "version": "1.2.0",
+    "completions": {"args": ["bar", "baz"], "flags": ["--force"]},
+    "events": {"post-deploy": ["notify"]}
+  }
+
+Plugins can't override core commands. To add a subcommand under a core namespace
+(eg: trellis-db-backup for 'trellis db backup'), a plugin must declare it with
+"extends": "db" in its manifest. Existing core subcommands can't be replaced.
+
+Plugins can subscribe to core events (pre-deploy, post-deploy, pre-provision,
+post-provision, pre-vm-start, post-vm-start) with "events". The plugin is run with
+the declared args, the event name as TRELLIS_EVENT, and a JSON payload on stdin:
+
+  {"event": "post-deploy", "project_path": "/path/to/trellis", "environment": "production",
+   "site": "example.com", "status": "success"}
+
+A failing "pre-" hook aborts the command.
+
+When run inside a Trellis project, plugins have the project's virtualenv activated
+(if virtualenv_integration is enabled) and receive the following env vars:
+
+  TRELLIS_PROJECT_PATH       Path to the Trellis project
+  TRELLIS_CONFIG_DIR         Path to the project's .trellis directory
+  TRELLIS_ENVIRONMENTS       Comma separated environment names
+  TRELLIS_VENV_PATH          Path to the project's virtualenv (when initialized)
+  TRELLIS_CLI_CONFIG         Resolved trellis-cli config as JSON
+  TRELLIS_VM_INVENTORY_PATH  Path to the development VM's inventory (when it exists)
+
+Options:
+  -h, --help  show this help
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *ListCommand) AutocompleteArgs() complete.Predictor {
+	return complete.PredictNothing
+}
+
+func (c *ListCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{}
+}
@@ -0,0 +1,57 @@
+package plugin
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/mitchellh/cli"
+)
+
+func TestListCommand(t *testing.T) {
+	firstDir := t.TempDir()
+	secondDir := t.TempDir()
+
+	for _, dir := range []string{firstDir, secondDir} {
+		if err := os.WriteFile(filepath.Join(dir, "trellis-foo"), []byte{}, 0755); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if err := os.WriteFile(filepath.Join(firstDir, "trellis-foo.json"), []byte(`{"version": "1.2.0"}`), 0644); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ui := cli.NewMockUi()
+	listCommand := NewListCommand(ui, &finder{validPrefixes: []string{"trellis"}, searchPaths: []string{firstDir, secondDir}})
+
+	if code := listCommand.Run(nil); code != 0 {
+		t.Errorf("expected code %d to be 0", code)
+	}
+
+	output := ui.OutputWriter.String()
+
+	for _, expected := range []string{
+		"NAME",
+		"foo   1.2.0    " + filepath.Join(firstDir, "trellis-foo"),
+		"shadows " + filepath.Join(secondDir, "trellis-foo"),
+	} {
+		if !strings.Contains(output, expected) {
+			t.Errorf("expected output %q to contain %q", output, expected)
+		}
+	}
+}
+
+func TestListCommandNoPlugins(t *testing.T) {
+	ui := cli.NewMockUi()
+	listCommand := NewListCommand(ui, &finder{validPrefixes: []string{"trellis"}, searchPaths: []string{t.TempDir()}})
+
+	if code := listCommand.Run(nil); code != 0 {
+		t.Errorf("expected code %d to be 0", code)
+	}
+
+	if !strings.Contains(ui.OutputWriter.String(), "No plugins found.") {
+		t.Errorf("expected output %q to contain %q", ui.OutputWriter.String(), "No plugins found.")
+	}
+}
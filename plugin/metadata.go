@@ -0,0 +1,96 @@
+package plugin
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"github.com/roots/trellis-cli/pkg/events"
+)
+
+/*
+Optional plugin metadata read from a JSON manifest next to the plugin binary
+named after it (ie: trellis-foo.json for trellis-foo or trellis-foo.exe):
+
+	{
+	  "synopsis": "Does foo things",
+	  "help": "Usage: trellis foo [options]\n...",
+	  "version": "1.2.0",
+	  "completions": {"args": ["bar", "baz"], "flags": ["--force"]},
+	  "extends": "db",
+	  "events": {"post-deploy": ["notify", "--quiet"]}
+	}
+
+Plugins can only add subcommands under a core namespace (eg: trellis-db-backup
+for 'trellis db backup') by declaring it with "extends". Events map core events
+to the plugin arguments run when they're emitted.
+*/
+type Metadata struct {
+	Synopsis    string      `json:"synopsis"`
+	Help        string      `json:"help"`
+	Version     string      `json:"version"`
+	Completions Completions `json:"completions"`
+	// Core namespace the plugin adds a subcommand to (ie: "db")
+	Extends string `json:"extends"`
+	// Core event name => plugin args
+	Events map[string][]string `json:"events"`
+}
+
+type Completions struct {
+	Args  []string `json:"args"`
+	Flags []string `json:"flags"`
+}
+
+// A plugin found in the search paths.
+type Plugin struct {
+	// Command name (ie: "foo bar" for trellis-foo-bar)
+	Name     string
+	Path     string
+	Metadata Metadata
+	// Error reading or parsing the manifest (nil when there's no manifest)
+	MetadataErr error
+	// Paths of plugins with the same name found later in the search paths
+	Shadowed []string
+}
+
+func manifestPath(binPath string) string {
+	if runtime.GOOS == "windows" {
+		binPath = strings.TrimSuffix(binPath, filepath.Ext(binPath))
+	}
+
+	return binPath + ".json"
+}
+
+func loadMetadata(binPath string) (Metadata, error) {
+	metadata := Metadata{}
+	path := manifestPath(binPath)
+
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return metadata, nil
+	}
+
+	if err != nil {
+		return metadata, fmt.Errorf("could not read plugin manifest %s: %v", path, err)
+	}
+
+	if err := json.Unmarshal(data, &metadata); err != nil {
+		return Metadata{}, fmt.Errorf("invalid plugin manifest %s: %v", path, err)
+	}
+
+	if strings.ContainsAny(metadata.Extends, " -") {
+		return Metadata{}, fmt.Errorf("invalid plugin manifest %s: extends must be a single core command (ie: db)", path)
+	}
+
+	for event := range metadata.Events {
+		if !events.Valid(event) {
+			return Metadata{}, fmt.Errorf("invalid plugin manifest %s: unknown event %q (expected one of: %s)", path, event, strings.Join(events.Names, ", "))
+		}
+	}
+
+	return metadata, nil
+}
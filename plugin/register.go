@@ -3,37 +3,101 @@ package plugin
 import (
 	"github.com/mitchellh/cli"
 	"github.com/roots/trellis-cli/cmd"
+	"github.com/roots/trellis-cli/trellis"
 	"reflect"
 	"strings"
 )
 
-func Register(c *cli.CLI, searchPaths []string, validPluginFilenamePrefixes []string) {
-	coreRootCommands := rootCommandsFor(reflect.ValueOf(c.Commands))
-
-	pluginFinder := finder{
-		validPrefixes:    validPluginFilenamePrefixes,
-		searchPaths:      searchPaths,
-		coreRootCommands: coreRootCommands,
-	}
-	plugins := pluginFinder.find()
-	pluginRootCommands := rootCommandsFor(reflect.ValueOf(plugins))
+func Register(c *cli.CLI, ui cli.Ui, trellis *trellis.Trellis, searchPaths []string, validPluginFilenamePrefixes []string) {
+	pluginFinder := newFinder(c.Commands, searchPaths, validPluginFilenamePrefixes)
+	plugins := pluginFinder.findAll()
+	pluginCommands := make(map[string]string)
+	synopses := make(map[string]string)
 
 	// Register plugin commands.
-	for name, bin := range plugins {
+	for _, plugin := range plugins {
 		pluginCommand := &cmd.PassthroughCommand{
-			Name: name,
-			Bin:  bin,
-			Args: c.Args,
+			Name:            plugin.Name,
+			Bin:             plugin.Path,
+			Args:            c.Args,
+			SynopsisText:    plugin.Metadata.Synopsis,
+			HelpText:        plugin.Metadata.Help,
+			CompletionArgs:  plugin.Metadata.Completions.Args,
+			CompletionFlags: plugin.Metadata.Completions.Flags,
+			UI:              ui,
+			Trellis:         trellis,
 		}
-		c.Commands[name] = func() (cli.Command, error) {
+		c.Commands[plugin.Name] = func() (cli.Command, error) {
 			return pluginCommand, nil
 		}
+
+		for event, args := range plugin.Metadata.Events {
+			trellis.Events.Subscribe(event, hookHandler(trellis.Runner, ui, plugin.Path, args))
+		}
+
+		// Subcommands of core namespaces are listed by the namespace's help instead
+		if plugin.Metadata.Extends == "" {
+			pluginCommands[plugin.Name] = plugin.Path
+			synopses[plugin.Name] = plugin.Metadata.Synopsis
+		}
 	}
 
+	pluginRootCommands := rootCommandsFor(reflect.ValueOf(pluginCommands))
+
 	// Separate plugin commands from core command lists.
 	c.HiddenCommands = append(c.HiddenCommands, pluginRootCommands...)
 	// Append plugin command list to help text.
-	c.HelpFunc = helpFunc(pluginRootCommands, c.HelpFunc)
+	c.HelpFunc = helpFunc(pluginRootCommands, synopses, c.HelpFunc)
+}
+
+func newFinder(commands map[string]cli.CommandFactory, searchPaths []string, validPluginFilenamePrefixes []string) *finder {
+	coreCommands := []string{}
+	for name := range commands {
+		coreCommands = append(coreCommands, name)
+	}
+
+	return &finder{
+		validPrefixes:    validPluginFilenamePrefixes,
+		searchPaths:      withInstallDir(searchPaths),
+		coreRootCommands: rootCommandsFor(reflect.ValueOf(commands)),
+		coreCommands:     coreCommands,
+		coreNamespaces:   namespacesFor(commands),
+	}
+}
+
+// Root commands with subcommands which are namespaces (ie: "db" but not "venv").
+func namespacesFor(commands map[string]cli.CommandFactory) []string {
+	namespaces := []string{}
+	roots := make(map[string]bool)
+
+	for name := range commands {
+		if root, _, found := strings.Cut(name, " "); found {
+			roots[root] = true
+		}
+	}
+
+	for root := range roots {
+		factory, ok := commands[root]
+		if !ok {
+			continue
+		}
+
+		command, err := factory()
+		if err != nil {
+			continue
+		}
+
+		if _, ok := command.(*cmd.NamespaceCommand); ok {
+			namespaces = append(namespaces, root)
+		}
+	}
+
+	return namespaces
+}
+
+// Installed plugins take precedence over ones on PATH.
+func withInstallDir(searchPaths []string) []string {
+	return append([]string{InstallDir()}, searchPaths...)
 }
 
 func rootCommandsFor(v reflect.Value) (rootCommands []string) {
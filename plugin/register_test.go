@@ -106,7 +106,7 @@ func TestIntegrationPluginCommand(t *testing.T) {
 
 	for _, tc := range cases {
 		mockUi := cli.NewMockUi()
-		spyCommand := command.WithOptions(command.WithUiOutput(mockUi)).Cmd(bin, tc.args)
+		spyCommand := command.New(command.NewExecRunner(), command.WithUiOutput(mockUi)).Cmd(bin, tc.args)
 		spyCommand.Env = []string{"PATH=" + tempDir + ":" + os.ExpandEnv("$PATH")}
 
 		spyCommand.Run()
@@ -164,7 +164,7 @@ func TestIntegrationPluginListInHelpFunc(t *testing.T) {
 
 	mockUi := cli.NewMockUi()
 
-	trellisCommand := command.WithOptions(command.WithUiOutput(mockUi)).Cmd(bin, []string{"--help"})
+	trellisCommand := command.New(command.NewExecRunner(), command.WithUiOutput(mockUi)).Cmd(bin, []string{"--help"})
 	trellisCommand.Env = []string{"PATH=" + tempDir + ":$PATH"}
 
 	trellisCommand.Run()
@@ -0,0 +1,87 @@
+package plugin
+
+import (
+	"flag"
+	"fmt"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+func NewRemoveCommand(ui cli.Ui, installer *installer) *RemoveCommand {
+	c := &RemoveCommand{UI: ui, installer: installer}
+	c.init()
+	return c
+}
+
+type RemoveCommand struct {
+	UI        cli.Ui
+	flags     *flag.FlagSet
+	installer *installer
+}
+
+func (c *RemoveCommand) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Usage = func() { c.UI.Info(c.Help()) }
+}
+
+func (c *RemoveCommand) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	args = c.flags.Args()
+
+	if len(args) != 1 {
+		c.UI.Error(fmt.Sprintf("Error: %s (expected exactly 1, got %d)", argumentsError(args), len(args)))
+		c.UI.Output(c.Help())
+		return 1
+	}
+
+	repo, version, err := parseRepoArg(args[0])
+	if err != nil || version != "latest" {
+		c.UI.Error(fmt.Sprintf("Error: invalid plugin %q. Must be in the format OWNER/REPO", args[0]))
+		return 1
+	}
+
+	removed, err := c.installer.remove(repo)
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	c.UI.Info(fmt.Sprintf("Removed %s %s (%s)", removed.Repo, removed.Version, strings.Join(removed.Files, ", ")))
+
+	return 0
+}
+
+func (c *RemoveCommand) Synopsis() string {
+	return "Removes an installed plugin"
+}
+
+func (c *RemoveCommand) Help() string {
+	helpText := `
+Usage: trellis plugin remove [options] OWNER/REPO
+
+Removes a plugin installed with 'trellis plugin install'.
+
+  $ trellis plugin remove roots/trellis-foo
+
+Arguments:
+  OWNER/REPO  GitHub repository of an installed plugin
+
+Options:
+  -h, --help  show this help
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *RemoveCommand) AutocompleteArgs() complete.Predictor {
+	return c.installer.predictInstalled()
+}
+
+func (c *RemoveCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{}
+}
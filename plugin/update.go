@@ -0,0 +1,139 @@
+package plugin
+
+import (
+	"flag"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/mitchellh/cli"
+	"github.com/posener/complete"
+)
+
+func NewUpdateCommand(ui cli.Ui, installer *installer) *UpdateCommand {
+	c := &UpdateCommand{UI: ui, installer: installer}
+	c.init()
+	return c
+}
+
+type UpdateCommand struct {
+	UI        cli.Ui
+	flags     *flag.FlagSet
+	installer *installer
+}
+
+func (c *UpdateCommand) init() {
+	c.flags = flag.NewFlagSet("", flag.ContinueOnError)
+	c.flags.Usage = func() { c.UI.Info(c.Help()) }
+}
+
+func (c *UpdateCommand) Run(args []string) int {
+	if err := c.flags.Parse(args); err != nil {
+		return 1
+	}
+
+	installed, err := c.installer.installed()
+	if err != nil {
+		c.UI.Error(err.Error())
+		return 1
+	}
+
+	repos := []string{}
+
+	for _, arg := range c.flags.Args() {
+		repo, version, err := parseRepoArg(arg)
+		if err != nil || version != "latest" {
+			c.UI.Error(fmt.Sprintf("Error: invalid plugin %q. Must be in the format OWNER/REPO", arg))
+			return 1
+		}
+
+		plugin, ok := installed[strings.ToLower(repo)]
+		if !ok {
+			c.UI.Error(fmt.Sprintf("Error: plugin %s is not installed", repo))
+			return 1
+		}
+
+		repos = append(repos, plugin.Repo)
+	}
+
+	if len(repos) == 0 {
+		for _, plugin := range installed {
+			repos = append(repos, plugin.Repo)
+		}
+		sort.Strings(repos)
+	}
+
+	if len(repos) == 0 {
+		c.UI.Info("No plugins installed.")
+		return 0
+	}
+
+	failed := false
+
+	for _, repo := range repos {
+		previous := installed[strings.ToLower(repo)]
+
+		release, err := c.installer.fetchRelease(repo, "latest")
+		if err != nil {
+			c.UI.Error(err.Error())
+			failed = true
+			continue
+		}
+
+		if release.Version == previous.Version {
+			c.UI.Info(fmt.Sprintf("%s is already up to date (%s)", repo, previous.Version))
+			continue
+		}
+
+		updated, err := c.installer.install(repo, release.Version)
+		if err != nil {
+			c.UI.Error(err.Error())
+			failed = true
+			continue
+		}
+
+		c.UI.Info(fmt.Sprintf("Updated %s %s → %s", repo, previous.Version, updated.Version))
+	}
+
+	if failed {
+		return 1
+	}
+
+	return 0
+}
+
+func (c *UpdateCommand) Synopsis() string {
+	return "Updates installed plugins to their latest release"
+}
+
+func (c *UpdateCommand) Help() string {
+	helpText := `
+Usage: trellis plugin update [options] [OWNER/REPO...]
+
+Updates plugins installed with 'trellis plugin install' to their latest release.
+
+Update all installed plugins:
+
+  $ trellis plugin update
+
+Update a specific plugin:
+
+  $ trellis plugin update roots/trellis-foo
+
+Arguments:
+  OWNER/REPO  GitHub repository of an installed plugin (default: all)
+
+Options:
+  -h, --help  show this help
+`
+
+	return strings.TrimSpace(helpText)
+}
+
+func (c *UpdateCommand) AutocompleteArgs() complete.Predictor {
+	return c.installer.predictInstalled()
+}
+
+func (c *UpdateCommand) AutocompleteFlags() complete.Flags {
+	return complete.Flags{}
+}
@@ -1,11 +1,17 @@
 package trellis
 
 import (
+	"bytes"
 	"html/template"
-	"os"
 	"path/filepath"
+
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/pkg/inventory"
 )
 
+// Host name used in the example inventory files Trellis ships with.
+const PlaceholderHost = "your_server_hostname"
+
 const Template = `
 [{{ .Environment }}]
 {{ .Host }}
@@ -24,15 +30,54 @@ func (t *Trellis) UpdateHosts(env string, ip string) (path string, err error) {
 	hosts := Hosts{env, ip}
 	tpl := template.Must(template.New("hosts").Parse(Template))
 
-	file, err := os.Create(path)
-	if err != nil {
+	var content bytes.Buffer
+	if err := tpl.Execute(&content, hosts); err != nil {
 		return "", err
 	}
 
-	err = tpl.Execute(file, hosts)
-	if err != nil {
+	// Only printed during a dry run
+	if err := command.WriteFile(t.Runner, path, content.Bytes(), 0644); err != nil {
 		return "", err
 	}
 
 	return path, nil
 }
+
+/*
+Loads the environment's Ansible inventory file (eg: 'hosts/production')
+along with the project's 'group_vars' and 'host_vars'.
+*/
+func (t *Trellis) LoadInventory(env string) (*inventory.Inventory, error) {
+	inv, err := inventory.Load(filepath.Join(t.Path, "hosts", env))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := inv.LoadVarsDir(t.Path); err != nil {
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+/*
+Returns the connection addresses (ansible_host or the host name) of the hosts
+in the environment's group in its inventory file (eg: 'hosts/production').
+Placeholder hosts (eg: 'your_server_hostname') are ignored.
+*/
+func (t *Trellis) EnvironmentHosts(env string) (hosts []string, err error) {
+	inv, err := t.LoadInventory(env)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, name := range inv.GroupHosts(env) {
+		if name == PlaceholderHost {
+			continue
+		}
+
+		hosts = append(hosts, inv.Connection(name).Host)
+	}
+
+	return hosts, nil
+}
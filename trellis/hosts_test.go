@@ -37,3 +37,59 @@ func TestUpdateHosts(t *testing.T) {
 		t.Errorf("expected hosts contents to be %s, but got %s", hostsContent, string(content))
 	}
 }
+
+func TestEnvironmentHosts(t *testing.T) {
+	defer TestChdir(t, "testdata/trellis")()
+
+	trellis := NewTrellis()
+
+	err := trellis.LoadProject()
+	if err != nil {
+		t.Fatalf("Could not load Trellis project: %s", err)
+	}
+
+	hosts, err := trellis.EnvironmentHosts("development")
+	if err != nil {
+		t.Fatalf(err.Error())
+	}
+
+	if len(hosts) != 1 || hosts[0] != "192.168.50.5" {
+		t.Errorf("expected hosts to be [192.168.50.5], but got %v", hosts)
+	}
+
+	if _, err := trellis.EnvironmentHosts("nope"); err == nil {
+		t.Errorf("expected error for missing hosts file")
+	}
+}
+
+func TestSshTargets(t *testing.T) {
+	defer TestChdir(t, "testdata/trellis")()
+
+	trellis := NewTrellis()
+
+	err := trellis.LoadProject()
+	if err != nil {
+		t.Fatalf("Could not load Trellis project: %s", err)
+	}
+
+	cases := []struct {
+		name        string
+		environment string
+		user        string
+		expected    string
+	}{
+		{"inventory_host", "production", "", "admin@1.2.3.4"},
+		{"inventory_host_with_user", "production", "web", "web@1.2.3.4"},
+		{"development", "development", "web", "vagrant@example.test"},
+	}
+
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			targets := trellis.SshTargets(tc.environment, "example.com", tc.user)
+
+			if len(targets) != 1 || targets[0].String() != tc.expected {
+				t.Errorf("expected targets to be [%s], but got %v", tc.expected, targets)
+			}
+		})
+	}
+}
@@ -7,6 +7,7 @@ import (
 
 	"github.com/fatih/color"
 	"github.com/mcuadros/go-version"
+	"github.com/roots/trellis-cli/command"
 )
 
 type Requirement struct {
@@ -34,7 +35,7 @@ func (r *Requirement) IsInstalled() (path string, ok bool) {
 	return path, true
 }
 
-func (r *Requirement) Check() (result RequirementResult, err error) {
+func (r *Requirement) Check(runner command.Runner) (result RequirementResult, err error) {
 	constraint := version.NewConstrainGroupFromString(r.VersionConstraint)
 	path, installed := r.IsInstalled()
 	message := fmt.Sprintf("%s [%s]:", r.Name, r.VersionConstraint)
@@ -47,7 +48,7 @@ func (r *Requirement) Check() (result RequirementResult, err error) {
 		}, nil
 	}
 
-	out, err := exec.Command(path, "--version").CombinedOutput()
+	out, err := command.New(runner).Cmd(path, []string{"--version"}).CombinedOutput()
 	version := strings.TrimSpace(string(out))
 
 	if err != nil {
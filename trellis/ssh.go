@@ -1,19 +1,58 @@
 package trellis
 
 import (
-	"fmt"
+	"github.com/roots/trellis-cli/pkg/inventory"
 )
 
 func (t *Trellis) SshHost(environment string, siteName string, user string) string {
+	return t.SshTargets(environment, siteName, user)[0].String()
+}
+
+/*
+Returns the SSH connections for an environment's web servers.
+
+Hosts are resolved from the environment's inventory (hosts in both the 'web'
+and environment groups) including ansible_host, ansible_port and ansible_user.
+If the inventory has no usable hosts, the site's main canonical host is used.
+
+The user defaults to ansible_user or 'admin' if not specified.
+Development always connects to the site's main host with the 'vagrant' user.
+*/
+func (t *Trellis) SshTargets(environment string, siteName string, user string) []inventory.Connection {
 	host := t.SiteFromEnvironmentAndName(environment, siteName).MainHost()
 
 	if environment == "development" {
-		user = "vagrant"
-	} else {
-		if user == "" {
-			user = "admin"
+		return []inventory.Connection{{Name: host, Host: host, User: "vagrant"}}
+	}
+
+	targets := []inventory.Connection{}
+
+	if inv, err := t.LoadInventory(environment); err == nil {
+		hosts := inv.Intersect("web", environment)
+		if len(hosts) == 0 {
+			hosts = inv.GroupHosts(environment)
+		}
+
+		for _, name := range hosts {
+			if name == PlaceholderHost {
+				continue
+			}
+
+			targets = append(targets, inv.Connection(name))
+		}
+	}
+
+	if len(targets) == 0 {
+		targets = append(targets, inventory.Connection{Name: host, Host: host})
+	}
+
+	for i := range targets {
+		if user != "" {
+			targets[i].User = user
+		} else if targets[i].User == "" {
+			targets[i].User = "admin"
 		}
 	}
 
-	return fmt.Sprintf("%s@%s", user, host)
+	return targets
 }
@@ -12,6 +12,8 @@ import (
 	"github.com/mitchellh/cli"
 	"github.com/roots/trellis-cli/app_paths"
 	"github.com/roots/trellis-cli/cli_config"
+	"github.com/roots/trellis-cli/command"
+	"github.com/roots/trellis-cli/pkg/events"
 	"gopkg.in/ini.v1"
 	"gopkg.in/yaml.v2"
 )
@@ -30,6 +32,7 @@ type TrellisOption func(*Trellis)
 
 var DefaultCliConfig = cli_config.Config{
 	AllowDevelopmentDeploys: false,
+	Aliases:                 make(map[string]string),
 	AskVaultPass:            false,
 	CheckForUpdates:         true,
 	LoadPlugins:             true,
@@ -47,9 +50,13 @@ type Trellis struct {
 	ConfigDir       string
 	Detector        Detector
 	Environments    map[string]*Config
+	Events          *events.Dispatcher
 	Path            string
+	Runner          command.Runner
 	Virtualenv      *Virtualenv
 	VenvInitialized bool
+	askVaultPassSet bool
+	baseCliConfig   *cli_config.Config
 	venvWarned      bool
 }
 
@@ -64,6 +71,8 @@ func NewTrellis(opts ...TrellisOption) *Trellis {
 		CliConfig:       cli_config.NewConfig(DefaultCliConfig),
 		ConfigDir:       defaultConfigDir,
 		Detector:        &ProjectDetector{},
+		Events:          events.NewDispatcher(),
+		Runner:          command.NewExecRunner(),
 		VenvInitialized: defaultVenvInitialized,
 		venvWarned:      defaultVenvWarned,
 	}
@@ -148,7 +157,7 @@ func (t *Trellis) ActivateProject() bool {
 	}
 
 	t.Path = path
-	t.Virtualenv = NewVirtualenv(t.ConfigPath())
+	t.Virtualenv = NewVirtualenv(t.ConfigPath(), t.Runner)
 
 	if !t.Virtualenv.Initialized() {
 		return false
@@ -182,7 +191,7 @@ func (t *Trellis) LoadProject() error {
 	}
 
 	t.Path = path
-	t.Virtualenv = NewVirtualenv(t.ConfigPath())
+	t.Virtualenv = NewVirtualenv(t.ConfigPath(), t.Runner)
 
 	os.Chdir(t.Path)
 
@@ -234,6 +243,33 @@ func (t *Trellis) ValidateEnvironment(name string) (err error) {
 	return fmt.Errorf("Error: %s is not a valid environment, valid options are %s", name, t.EnvironmentNames())
 }
 
+/*
+Overlays an environment's CLI config settings (from the `environments` block)
+once a command knows which environment it runs for. Only the last environment
+applied is in effect; the settings of previously applied ones are reset.
+*/
+func (t *Trellis) ApplyEnvironmentConfig(name string) error {
+	if t.baseCliConfig == nil {
+		base := t.CliConfig.Clone()
+		t.baseCliConfig = &base
+	}
+
+	t.CliConfig = t.baseCliConfig.Clone()
+
+	if err := t.CliConfig.ApplyEnvironment(name); err != nil {
+		return fmt.Errorf("Error loading CLI config for the %s environment\n%v", name, err)
+	}
+
+	// Env vars still take precedence over environment settings
+	if err := t.CliConfig.LoadEnv("TRELLIS_"); err != nil {
+		return fmt.Errorf("Error loading CLI config\n%v", err)
+	}
+
+	t.setAnsibleEnv()
+
+	return nil
+}
+
 func (t *Trellis) SiteNamesFromEnvironment(environment string) []string {
 	var names []string
 
@@ -304,26 +340,96 @@ func (t *Trellis) LoadGlobalCliConfig() error {
 }
 
 func (t *Trellis) LoadProjectCliConfig() error {
-	configPaths := []string{
-		filepath.Join(t.ConfigPath(), "cli.yml"),
-		filepath.Join(t.Path, "trellis.cli.yml"),
-		filepath.Join(t.Path, "trellis.cli.local.yml"),
-	}
-
-	for _, path := range configPaths {
+	for _, path := range t.projectCliConfigPaths(t.Path) {
 		if err := t.CliConfig.LoadFile(path); err != nil {
 			return fmt.Errorf("Error loading CLI config %s\n%v", path, err)
 		}
 	}
 
-	t.CliConfig.LoadEnv("TRELLIS_")
+	if err := t.CliConfig.LoadEnv("TRELLIS_"); err != nil {
+		return fmt.Errorf("Error loading CLI config\n%v", err)
+	}
+
+	t.setAnsibleEnv()
 
+	return nil
+}
+
+func (t *Trellis) setAnsibleEnv() {
 	if t.CliConfig.AskVaultPass {
 		// https://docs.ansible.com/ansible/latest/reference_appendices/config.html#default-ask-vault-pass
 		os.Setenv("ANSIBLE_ASK_VAULT_PASS", "true")
+		t.askVaultPassSet = true
+	} else if t.askVaultPassSet {
+		// Only unset it when it was set by a previously applied config
+		os.Unsetenv("ANSIBLE_ASK_VAULT_PASS")
+		t.askVaultPassSet = false
 	}
+}
 
-	return nil
+// CLI config files in the order they're loaded (later files take precedence).
+func (t *Trellis) CliConfigPaths() []string {
+	paths := []string{app_paths.ConfigPath("cli.yml")}
+
+	if t.Path != "" {
+		paths = append(paths, t.projectCliConfigPaths(t.Path)...)
+	}
+
+	return paths
+}
+
+func (t *Trellis) projectCliConfigPaths(projectPath string) []string {
+	return []string{
+		filepath.Join(projectPath, t.ConfigDir, "cli.yml"),
+		filepath.Join(projectPath, "trellis.cli.yml"),
+		filepath.Join(projectPath, "trellis.cli.local.yml"),
+	}
+}
+
+/*
+Returns the command aliases from the global CLI config and, when run inside a
+project, its CLI config files. Aliases are needed before any command runs, so
+the project's config files are only read for their aliases (without loading
+the project or validating the rest of the config).
+*/
+func (t *Trellis) Aliases() map[string]string {
+	aliases := make(map[string]string)
+
+	for name, value := range t.CliConfig.Aliases {
+		aliases[name] = value
+	}
+
+	wd, err := os.Getwd()
+	if err != nil {
+		return aliases
+	}
+
+	projectPath, ok := t.Detect(wd)
+	if !ok {
+		return aliases
+	}
+
+	for _, path := range t.projectCliConfigPaths(projectPath) {
+		config := struct {
+			Aliases map[string]string `yaml:"aliases"`
+		}{}
+
+		data, err := os.ReadFile(path)
+		if err != nil {
+			continue
+		}
+
+		// Invalid config files are reported once the project is loaded
+		if err := yaml.Unmarshal(data, &config); err != nil {
+			continue
+		}
+
+		for name, value := range config.Aliases {
+			aliases[name] = value
+		}
+	}
+
+	return aliases
 }
 
 func (t *Trellis) SiteFromEnvironmentAndName(environment string, name string) *Site {
@@ -349,15 +455,11 @@ func (t *Trellis) UpdateAnsibleConfig(section string, key string, value string)
 func (t *Trellis) WriteYamlFile(s interface{}, path string, header string) error {
 	data, err := yaml.Marshal(s)
 	if err != nil {
-		log.Fatal(err)
+		return err
 	}
 
 	path = filepath.Join(t.Path, path)
 	data = append([]byte(header), data...)
 
-	if err := os.WriteFile(path, data, 0666); err != nil {
-		log.Fatal(err)
-	}
-
-	return nil
+	return command.WriteFile(t.Runner, path, data, 0666)
 }
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/roots/trellis-cli/app_paths"
@@ -444,3 +445,121 @@ ask_vault_pass: true
 		t.Errorf("expected load project to load project CLI config file")
 	}
 }
+
+func TestApplyEnvironmentConfig(t *testing.T) {
+	defer LoadFixtureProject(t)()
+
+	t.Setenv("TRELLIS_CONFIG_DIR", t.TempDir())
+	t.Setenv("TRELLIS_DATABASE_APP", "mysql")
+
+	projectConfigContents := `
+database_app: tableplus
+environments:
+  production:
+    allow_development_deploys: true
+    database_app: sequel-ace
+`
+
+	if err := os.WriteFile("trellis.cli.yml", []byte(projectConfigContents), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	tp := NewTrellis()
+
+	if err := tp.LoadProject(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if tp.CliConfig.AllowDevelopmentDeploys {
+		t.Errorf("expected environment settings not to be applied before applying an environment")
+	}
+
+	if err := tp.ValidateEnvironment("production"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if tp.CliConfig.AllowDevelopmentDeploys {
+		t.Errorf("expected validating an environment not to apply its settings")
+	}
+
+	if err := tp.ApplyEnvironmentConfig("production"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if !tp.CliConfig.AllowDevelopmentDeploys {
+		t.Errorf("expected production settings to set AllowDevelopmentDeploys to true")
+	}
+
+	if tp.CliConfig.DatabaseApp != "mysql" {
+		t.Errorf("expected env vars to take precedence over environment settings, got %s", tp.CliConfig.DatabaseApp)
+	}
+}
+
+func TestApplyEnvironmentConfigResetsPreviousEnvironment(t *testing.T) {
+	defer LoadFixtureProject(t)()
+
+	t.Setenv("TRELLIS_CONFIG_DIR", t.TempDir())
+	os.Unsetenv("ANSIBLE_ASK_VAULT_PASS")
+	t.Cleanup(func() { os.Unsetenv("ANSIBLE_ASK_VAULT_PASS") })
+
+	projectConfigContents := `
+environments:
+  production:
+    allow_development_deploys: true
+    ask_vault_pass: true
+`
+
+	if err := os.WriteFile("trellis.cli.yml", []byte(projectConfigContents), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	tp := NewTrellis()
+
+	if err := tp.LoadProject(); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, environment := range []string{"production", "development"} {
+		if err := tp.ValidateEnvironment(environment); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+	}
+
+	if tp.CliConfig.AllowDevelopmentDeploys {
+		t.Errorf("expected validating environments not to apply their settings")
+	}
+
+	if err := tp.ApplyEnvironmentConfig("production"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if os.Getenv("ANSIBLE_ASK_VAULT_PASS") != "true" {
+		t.Errorf("expected production settings to set ANSIBLE_ASK_VAULT_PASS")
+	}
+
+	if err := tp.ApplyEnvironmentConfig("development"); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if tp.CliConfig.AllowDevelopmentDeploys || tp.CliConfig.AskVaultPass {
+		t.Errorf("expected production settings to be reset when applying development")
+	}
+
+	if _, ok := os.LookupEnv("ANSIBLE_ASK_VAULT_PASS"); ok {
+		t.Errorf("expected ANSIBLE_ASK_VAULT_PASS to be unset when applying development")
+	}
+}
+
+func TestLoadProjectInvalidEnvConfig(t *testing.T) {
+	defer LoadFixtureProject(t)()
+
+	t.Setenv("TRELLIS_CONFIG_DIR", t.TempDir())
+	t.Setenv("TRELLIS_VM_MANAGER", "bogus")
+
+	tp := NewTrellis()
+
+	err := tp.LoadProject()
+	if err == nil || !strings.Contains(err.Error(), "TRELLIS_VM_MANAGER=bogus must be one of") {
+		t.Errorf("expected invalid TRELLIS_VM_MANAGER error, got %v", err)
+	}
+}
@@ -9,6 +9,8 @@ import (
 	"os"
 	"path/filepath"
 	"strings"
+
+	"github.com/roots/trellis-cli/command"
 )
 
 const fileHeader = "$ANSIBLE_VAULT;1.1;AES256"
@@ -121,7 +123,7 @@ func (t *Trellis) GenerateVaultPassFile(path string) error {
 	randomString := RandomStringGenerator{Length: 64}
 
 	vaultPass := randomString.Generate()
-	return os.WriteFile(path, []byte(vaultPass), 0600)
+	return command.WriteFile(t.Runner, path, []byte(vaultPass), 0600)
 }
 
 func assertAvailablePRNG() {
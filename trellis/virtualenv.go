@@ -22,13 +22,15 @@ type Virtualenv struct {
 	Path    string
 	BinPath string
 	OldPath string
+	runner  command.Runner
 }
 
-func NewVirtualenv(path string) *Virtualenv {
+func NewVirtualenv(path string, runner command.Runner) *Virtualenv {
 	return &Virtualenv{
 		Path:    filepath.Join(path, VirtualenvDir),
 		BinPath: filepath.Join(path, VirtualenvDir, "bin"),
 		OldPath: os.Getenv(PathEnvName),
+		runner:  runner,
 	}
 }
 
@@ -87,19 +89,19 @@ func (v *Virtualenv) Initialized() bool {
 	return true
 }
 
-func (v *Virtualenv) Installed() (ok bool, cmd *exec.Cmd) {
+func (v *Virtualenv) Installed() (ok bool, cmd *command.Cmd) {
 	path, err := exec.LookPath("python3")
 	if err == nil {
-		err = command.Cmd(path, []string{"-m", "ensurepip", "--version"}).Run()
+		err = command.New(v.runner).Cmd(path, []string{"-m", "ensurepip", "--version"}).Run()
 
 		if err == nil {
-			return true, command.Cmd(path, []string{"-m", "venv"})
+			return true, command.New(v.runner).Cmd(path, []string{"-m", "venv"})
 		}
 	}
 
 	path, err = exec.LookPath("virtualenv")
 	if err == nil {
-		return true, command.Cmd(path, []string{})
+		return true, command.New(v.runner).Cmd(path, []string{})
 	}
 
 	return false, nil
@@ -158,7 +160,7 @@ func (v *Virtualenv) updateFile(path string) error {
 	}
 
 	// overwrite the original bin file with the fixed version
-	if err = os.WriteFile(f.Name(), contents.Bytes(), permissions); err != nil {
+	if err = command.WriteFile(v.runner, f.Name(), contents.Bytes(), permissions); err != nil {
 		return fmt.Errorf("Error writing file %s: %w", path, err)
 	}
 
@@ -1,10 +1,8 @@
 package trellis
 
 import (
-	"bytes"
 	"fmt"
 	"os"
-	"os/exec"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -13,7 +11,7 @@ import (
 )
 
 func TestNewVirtualenv(t *testing.T) {
-	venv := NewVirtualenv("trellis")
+	venv := NewVirtualenv("trellis", command.NewFakeRunner())
 	path := "trellis/virtualenv"
 	binPath := "trellis/virtualenv/bin"
 	oldPath := os.Getenv("PATH")
@@ -32,7 +30,7 @@ func TestNewVirtualenv(t *testing.T) {
 }
 
 func TestActivateSetsEnv(t *testing.T) {
-	venv := NewVirtualenv("trellis")
+	venv := NewVirtualenv("trellis", command.NewFakeRunner())
 	originalPath := os.Getenv("PATH")
 
 	venv.Activate()
@@ -53,7 +51,7 @@ func TestActivateSetsEnv(t *testing.T) {
 }
 
 func TestActivateIsIdempotent(t *testing.T) {
-	venv := NewVirtualenv("trellis")
+	venv := NewVirtualenv("trellis", command.NewFakeRunner())
 	originalPath := os.Getenv("PATH")
 
 	venv.Activate()
@@ -75,7 +73,7 @@ func TestActivateIsIdempotent(t *testing.T) {
 }
 
 func TestActive(t *testing.T) {
-	venv := NewVirtualenv("trellis")
+	venv := NewVirtualenv("trellis", command.NewFakeRunner())
 
 	if venv.Active() {
 		t.Error("expected virtualenv to be inactive")
@@ -89,7 +87,7 @@ func TestActive(t *testing.T) {
 }
 
 func TestDeactive(t *testing.T) {
-	venv := NewVirtualenv("trellis")
+	venv := NewVirtualenv("trellis", command.NewFakeRunner())
 	venv.Activate()
 	venv.Deactivate()
 
@@ -109,7 +107,7 @@ func TestDeactive(t *testing.T) {
 func TestInitialized(t *testing.T) {
 	tempDir := t.TempDir()
 
-	venv := NewVirtualenv(tempDir)
+	venv := NewVirtualenv(tempDir, command.NewFakeRunner())
 
 	if venv.Initialized() {
 		t.Error("Expected to be uniniatlized")
@@ -128,7 +126,7 @@ func TestInstalled(t *testing.T) {
 	t.Setenv("PATH", "")
 	t.Setenv("XDG_CONFIG_HOME", "none")
 
-	venv := NewVirtualenv("foo")
+	venv := NewVirtualenv("foo", command.NewFakeRunner())
 
 	ok, _ := venv.Installed()
 
@@ -144,22 +142,7 @@ func TestInstalledPython3WithEnsurepip(t *testing.T) {
 	pythonPath := filepath.Join(tempDir, "python3")
 	os.OpenFile(pythonPath, os.O_CREATE, 0555)
 
-	venv := NewVirtualenv(tempDir)
-
-	var output bytes.Buffer
-
-	mockExecCommand := func(command string, args []string) *exec.Cmd {
-		cs := []string{"-test.run=TestEnsurePipSuccessHelperProcess", "--", command}
-		cs = append(cs, args...)
-		cmd := exec.Command(os.Args[0], cs...)
-		cmd.Stderr = &output
-		cmd.Stdout = &output
-		cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
-		return cmd
-	}
-
-	command.Mock(mockExecCommand)
-	defer command.Restore()
+	venv := NewVirtualenv(tempDir, command.NewFakeRunner())
 
 	ok, cmd := venv.Installed()
 
@@ -182,22 +165,13 @@ func TestInstalledPython3WithoutEnsurepip(t *testing.T) {
 	pythonPath := filepath.Join(tempDir, "python3")
 	os.OpenFile(pythonPath, os.O_CREATE, 0555)
 
-	var output bytes.Buffer
+	runner := command.NewFakeRunner(command.MockCommand{
+		Command:  pythonPath,
+		Args:     []string{"-m", "ensurepip", "--version"},
+		ExitCode: 1,
+	})
 
-	mockExecCommand := func(command string, args []string) *exec.Cmd {
-		cs := []string{"-test.run=TestEnsurePipFailureHelperProcess", "--", command}
-		cs = append(cs, args...)
-		cmd := exec.Command(os.Args[0], cs...)
-		cmd.Stderr = &output
-		cmd.Stdout = &output
-		cmd.Env = []string{"GO_WANT_HELPER_PROCESS=1"}
-		return cmd
-	}
-
-	command.Mock(mockExecCommand)
-	defer command.Restore()
-
-	venv := NewVirtualenv(tempDir)
+	venv := NewVirtualenv(tempDir, runner)
 
 	ok, _ := venv.Installed()
 
@@ -213,7 +187,7 @@ func TestInstalledVirtualenv(t *testing.T) {
 	venvPath := filepath.Join(tempDir, "virtualenv")
 	os.OpenFile(venvPath, os.O_CREATE, 0555)
 
-	venv := NewVirtualenv(tempDir)
+	venv := NewVirtualenv(tempDir, command.NewFakeRunner())
 
 	ok, cmd := venv.Installed()
 
@@ -221,13 +195,13 @@ func TestInstalledVirtualenv(t *testing.T) {
 		t.Error("Expected to be installed")
 	}
 
-	if strings.Join(cmd.Args, " ") != venvPath {
+	if cmd.String() != venvPath {
 		t.Error("Expected args incorrect")
 	}
 }
 
 func TestReplaceShebang(t *testing.T) {
-	venv := NewVirtualenv("/trellis")
+	venv := NewVirtualenv("/trellis", command.NewFakeRunner())
 
 	cases := []struct {
 		name   string
@@ -284,7 +258,7 @@ func TestUpdateBinShebangsNoSpaces(t *testing.T) {
 
 	os.MkdirAll(filepath.Join(dir, "virtualenv", "bin"), 0755)
 
-	venv := NewVirtualenv(dir)
+	venv := NewVirtualenv(dir, command.NewFakeRunner())
 	content := `#!/trellis/virtualenv/bin/python\n`
 	path := filepath.Join(venv.BinPath, "foo")
 
@@ -310,7 +284,7 @@ func TestUpdateBinShebangsWithSpaces(t *testing.T) {
 
 	os.MkdirAll(filepath.Join(dir, "virtualenv", "bin"), 0755)
 
-	venv := NewVirtualenv(dir)
+	venv := NewVirtualenv(dir, command.NewFakeRunner())
 
 	cases := []struct {
 		name         string
@@ -370,21 +344,3 @@ func testCreateFile(t *testing.T, path string) func() {
 
 	return func() { file.Close() }
 }
-
-func TestEnsurePipSuccessHelperProcess(t *testing.T) {
-	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
-		return
-	}
-
-	fmt.Fprintf(os.Stdout, strings.Join(os.Args[3:], " "))
-	os.Exit(0)
-}
-
-func TestEnsurePipFailureHelperProcess(t *testing.T) {
-	if os.Getenv("GO_WANT_HELPER_PROCESS") != "1" {
-		return
-	}
-
-	fmt.Fprintf(os.Stderr, strings.Join(os.Args[3:], " "))
-	os.Exit(1)
-}